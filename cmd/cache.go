@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd groups commands that manage the cache of remote templates
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of remote templates",
+	Long: `Manage the cache of remote templates, kept in the ason cache directory
+($XDG_CACHE_HOME/ason, usually ~/.cache/ason).
+
+Templates fetched from Git URLs by new and register are cached by URL and
+ref, and used again until they are older than the cache_ttl setting (24h by
+default). Pass --refresh to those commands to fetch a template again.`,
+}
+
+var cachePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the cache directory",
+	Args:  cobra.NoArgs,
+	RunE:  runCachePath,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached remote templates with their sizes and ages",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheList,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached remote templates",
+	Long: `Remove every cached remote template, or with --older-than only those
+fetched longer ago than the given duration, such as 72h.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+// cacheOlderThan limits cache clear to templates fetched longer ago
+var cacheOlderThan time.Duration
+
+func init() {
+	cacheCmd.AddCommand(cachePathCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cacheClearCmd.Flags().DurationVar(&cacheOlderThan, "older-than", 0, "Only remove templates fetched longer ago than this, e.g. 72h")
+}
+
+func runCachePath(cmd *cobra.Command, args []string) error {
+	templates, err := cache.Open()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), templates.Dir())
+	return nil
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	templates, err := cache.Open()
+	if err != nil {
+		return err
+	}
+	entries, err := templates.List()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No cached remote templates")
+		return nil
+	}
+
+	var total int64
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tSIZE\tFETCHED")
+	fmt.Fprintln(w, "------\t----\t-------")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Source, formatSize(entry.Size), formatTime(entry.Fetched))
+		total += entry.Size
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d cached templates, %s\n", len(entries), formatSize(total))
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	if cacheOlderThan < 0 {
+		return usageErrorf("invalid --older-than %s (must not be negative)", cacheOlderThan)
+	}
+
+	templates, err := cache.Open()
+	if err != nil {
+		return err
+	}
+
+	if cacheOlderThan == 0 {
+		if err := templates.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "🧹 Cleared the remote template cache")
+		return nil
+	}
+
+	removed, err := templates.Prune(cacheOlderThan)
+	for _, entry := range removed {
+		fmt.Fprintf(cmd.OutOrStdout(), "🧹 Removed %s\n", entry.Source)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached templates fetched more than %s ago\n", len(removed), cacheOlderThan)
+	return nil
+}
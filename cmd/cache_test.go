@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+func TestCacheCmds(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	defer func() { cacheOlderThan = 0 }()
+
+	run := func(cmd *cobra.Command, wantErr bool) string {
+		t.Helper()
+		var out strings.Builder
+		cmd.SetOut(&out)
+		defer cmd.SetOut(nil)
+		if err := cmd.RunE(cmd, nil); (err != nil) != wantErr {
+			t.Fatalf("%s error = %v, wantErr %v", cmd.CommandPath(), err, wantErr)
+		}
+		return out.String()
+	}
+
+	if got := strings.TrimSpace(run(cachePathCmd, false)); got != filepath.Join(cacheHome, "ason", "templates") {
+		t.Errorf("cache path = %q", got)
+	}
+	if got := run(cacheListCmd, false); !strings.Contains(got, "No cached remote templates") {
+		t.Errorf("cache list of an empty cache = %q", got)
+	}
+
+	templates, err := cache.Open()
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	sources := []string{"https://github.com/example/a.git#v1", "https://github.com/example/b.git"}
+	for _, source := range sources {
+		_, err := templates.Store(source, func(dir string) error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dir, "README.md"), []byte("# cached"), 0644)
+		})
+		if err != nil {
+			t.Fatalf("Failed to cache %s: %v", source, err)
+		}
+	}
+
+	out := run(cacheListCmd, false)
+	for _, want := range append(sources, "8 B", "just now", "2 cached templates, 16 B") {
+		if !strings.Contains(out, want) {
+			t.Errorf("cache list is missing %q:\n%s", want, out)
+		}
+	}
+
+	// Nothing is older than an hour
+	cacheOlderThan = time.Hour
+	run(cacheClearCmd, false)
+	if entries, _ := templates.List(); len(entries) != 2 {
+		t.Errorf("cache clear --older-than 1h removed fresh templates, %d left", len(entries))
+	}
+
+	cacheOlderThan = -time.Hour
+	run(cacheClearCmd, true)
+
+	// Everything is older than a nanosecond
+	cacheOlderThan = time.Nanosecond
+	out = run(cacheClearCmd, false)
+	if !strings.Contains(out, "Removed 2 cached templates") {
+		t.Errorf("cache clear --older-than 1ns = %q", out)
+	}
+	if entries, _ := templates.List(); len(entries) != 0 {
+		t.Errorf("cache clear --older-than 1ns left %d templates", len(entries))
+	}
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -12,22 +15,34 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/madstone-tech/ason/internal/template"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// List command flags
-	listFormat  string
-	listFilter  string
-	listSort    string
-	listReverse bool
+	listFormat   string
+	listFilter   string
+	listSort     string
+	listReverse  bool
+	listOutdated bool
+	listLong     bool
+	listTime     string
+	listLimit    int
+	listOffset   int
 
 	// Register command flags
 	registerDescription string
+	registerReadme      bool
 	registerType        string
 	registerForce       bool
 	registerValidate    bool
+	registerNoValidate  bool
+	registerStrict      bool
 	registerDryRun      bool
+	registerSource      string
+	registerHidden      bool
+	registerRefresh     bool
 
 	// Remove command flags
 	removeForce     bool
@@ -56,12 +71,24 @@ func init() {
 	listCmd.Flags().StringVar(&listFilter, "filter", "", "Filter templates by name or description")
 	listCmd.Flags().StringVar(&listSort, "sort", "name", "Sort by field (name, date, size, type)")
 	listCmd.Flags().BoolVar(&listReverse, "reverse", false, "Reverse sort order")
+	listCmd.Flags().BoolVar(&listOutdated, "outdated", false, "Mark templates whose source has changed since registration")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Show at most N templates (0 for all)")
+	listCmd.Flags().IntVar(&listOffset, "offset", 0, "Skip the first M templates, after filtering and sorting")
+	listCmd.Flags().StringVar(&listTime, "time-format", "relative", "How the table shows when templates were added (relative, absolute, rfc3339)")
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "Show version, tags, variable count and source, without truncating descriptions")
 
 	registerCmd.Flags().StringVar(&registerDescription, "description", "", "Template description")
+	registerCmd.Flags().BoolVar(&registerReadme, "description-from-readme", false, "Use the README's first paragraph as the description when --description is not given")
 	registerCmd.Flags().StringVar(&registerType, "type", "", "Template type")
 	registerCmd.Flags().BoolVar(&registerForce, "force", false, "Overwrite existing template")
-	registerCmd.Flags().BoolVar(&registerValidate, "validate", false, "Validate template before registering")
+	registerCmd.Flags().BoolVar(&registerValidate, "validate", false, "Print the full validation report before registering")
+	registerCmd.Flags().BoolVar(&registerNoValidate, "no-validate", false, "Register without validating the template")
+	registerCmd.Flags().BoolVar(&registerStrict, "strict", false, "Refuse templates with validation warnings too")
+	registerCmd.MarkFlagsMutuallyExclusive("validate", "no-validate")
 	registerCmd.Flags().BoolVar(&registerDryRun, "dry-run", false, "Show what would be registered")
+	registerCmd.Flags().StringVar(&registerSource, "source", "", "Where the template came from, e.g. a repository URL (default: the path registered from)")
+	registerCmd.Flags().BoolVar(&registerHidden, "include-hidden", false, "Copy all hidden files and directories, and generate them too")
+	registerCmd.Flags().BoolVar(&registerRefresh, "refresh", false, "Fetch a remote template again instead of using the cached copy")
 
 	removeCmd.Flags().BoolVar(&removeForce, "force", false, "Remove without confirmation")
 	removeCmd.Flags().BoolVar(&removeDryRun, "dry-run", false, "Show what would be removed")
@@ -76,7 +103,14 @@ func init() {
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	reg, err := registry.NewRegistry()
+	if !slices.Contains(listTimeFormats, listTime) {
+		return usageErrorf("invalid --time-format %q (supported: %s)", listTime, strings.Join(listTimeFormats, ", "))
+	}
+	if listLimit < 0 || listOffset < 0 {
+		return usageErrorf("--limit and --offset must not be negative")
+	}
+
+	reg, err := openRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to initialize registry: %w", err)
 	}
@@ -91,10 +125,26 @@ func runList(cmd *cobra.Command, args []string) error {
 		templates = filterTemplates(templates, listFilter)
 	}
 
+	// Compare templates against their sources
+	if listOutdated {
+		for i := range templates {
+			outdated, err := reg.IsOutdated(templates[i])
+			if err != nil {
+				slog.Warn("could not check template source", "template", templates[i].Name, "error", err)
+				continue
+			}
+			templates[i].Outdated = outdated
+		}
+	}
+
 	// Sort templates
 	sortTemplates(templates, listSort, listReverse)
 
-	if len(templates) == 0 {
+	// Page through the sorted templates
+	total := len(templates)
+	templates = pageTemplates(templates, listOffset, listLimit)
+
+	if total == 0 {
 		if listFormat == "json" {
 			fmt.Println(`{"templates":[], "total":0}`)
 			return nil
@@ -114,14 +164,27 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	switch listFormat {
 	case "json":
-		return printTemplatesJSON(templates)
+		return printTemplatesJSON(templates, total)
 	case "yaml":
-		return printTemplatesYAML(templates)
+		return printTemplatesYAML(templates, total)
 	default:
-		return printTemplatesTable(templates)
+		return printTemplatesTable(templates, total)
 	}
 }
 
+// pageTemplates returns the window of templates selected by --offset and
+// --limit; a limit of 0 means no limit
+func pageTemplates(templates []registry.TemplateEntry, offset, limit int) []registry.TemplateEntry {
+	if offset >= len(templates) {
+		return []registry.TemplateEntry{}
+	}
+	templates = templates[offset:]
+	if limit > 0 && limit < len(templates) {
+		templates = templates[:limit]
+	}
+	return templates
+}
+
 // registerCmd registers a template in the registry.
 // The "add" alias is maintained for backward compatibility with existing scripts and workflows.
 var registerCmd = &cobra.Command{
@@ -135,51 +198,73 @@ var registerCmd = &cobra.Command{
 func runRegister(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	sourcePath := args[1]
+	source := registerSource
+	remote, isRemote := remoteSource(sourcePath)
+
+	if !isRemote {
+		// Expand path
+		if strings.HasPrefix(sourcePath, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to get home directory: %w", err)
+			}
+			sourcePath = filepath.Join(home, sourcePath[2:])
+		}
 
-	// Expand path
-	if strings.HasPrefix(sourcePath, "~/") {
-		home, err := os.UserHomeDir()
+		// Make path absolute
+		var err error
+		sourcePath, err = filepath.Abs(sourcePath)
 		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+			return fmt.Errorf("failed to resolve path: %w", err)
 		}
-		sourcePath = filepath.Join(home, sourcePath[2:])
-	}
-
-	// Make path absolute
-	sourcePath, err := filepath.Abs(sourcePath)
-	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	fmt.Println("※ The ason prepares to embrace new wisdom...")
 
-	if registerDryRun {
-		fmt.Println("[DRY RUN] Would analyze:", sourcePath)
-		fmt.Println("[DRY RUN] Would validate template structure")
-		fmt.Printf("[DRY RUN] Would copy to: ~/.ason/templates/%s\n", name)
-		fmt.Printf("[DRY RUN] Would register as: %s\n", name)
-		fmt.Println("🔮 [DRY RUN] Template ready for registration. Use without --dry-run to register.")
-		return nil
+	// Remote templates are registered from a temporary clone and keep
+	// their URL as the source
+	if isRemote {
+		userConfig, _, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		dir, cleanup, err := fetchRemote(cmd, os.Stdout, remote, userConfig.RemoteCacheTTL(), registerRefresh)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		sourcePath = dir
+		if source == "" {
+			source = remote.String()
+		}
 	}
 
-	fmt.Println("✨ Analyzing template:", sourcePath)
+	description := registerDescription
+	if description == "" && registerReadme {
+		description = readmeDescription(sourcePath)
+	}
 
-	// Validate template if requested
-	if registerValidate {
-		fmt.Println("📿 Validating template structure...")
-		if err := validateTemplate(sourcePath); err != nil {
-			return fmt.Errorf("template validation failed: %w", err)
+	// Validate first so that broken templates never enter the registry
+	if !registerNoValidate {
+		if err := validateForRegister(cmd, sourcePath); err != nil {
+			return err
 		}
-		fmt.Println("💫 Template structure confirmed")
 	}
 
-	reg, err := registry.NewRegistry()
+	if registerDryRun {
+		return previewRegister(cmd, name, sourcePath, description, source)
+	}
+
+	fmt.Println("✨ Analyzing template:", sourcePath)
+
+	reg, err := openRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to initialize registry: %w", err)
 	}
 
-	// Check if template exists and handle force flag
-	if _, err := reg.Get(name); err == nil {
+	// Check if template exists and handle force flag. System templates are
+	// read-only and are simply shadowed by the new registration.
+	if entry, err := reg.Entry(name); err == nil && entry.Name == name && entry.Origin != registry.OriginSystem {
 		if !registerForce {
 			return fmt.Errorf("template '%s' already exists. Use --force to overwrite", name)
 		}
@@ -193,10 +278,16 @@ func runRegister(cmd *cobra.Command, args []string) error {
 	fmt.Println("🎭 Copying template to registry...")
 
 	// Register template in registry
-	if err := reg.Add(name, sourcePath, registerDescription, registerType); err != nil {
+	opts := registry.AddOptions{Source: source, IncludeHidden: registerHidden}
+	if err := reg.AddWithOptions(name, sourcePath, description, registerType, opts); err != nil {
 		return fmt.Errorf("failed to add template: %w", err)
 	}
 
+	if entry, err := reg.Entry(name); err == nil && len(entry.SkippedHidden) > 0 {
+		fmt.Printf("⚠️  Skipped hidden paths (use --include-hidden, or set include_hidden or hidden_allow in the template config to keep them): %s\n",
+			strings.Join(entry.SkippedHidden, ", "))
+	}
+
 	fmt.Printf("🔮 Template '%s' added to registry successfully!\n", name)
 	fmt.Println()
 	fmt.Printf("💡 Use it with: ason new %s my-project\n", name)
@@ -204,6 +295,122 @@ func runRegister(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// readmeFiles are the README names checked by --description-from-readme
+var readmeFiles = []string{"README.md", "README"}
+
+// readmeDescription returns the first paragraph of a template's README, with
+// its lines joined. Headings are skipped in favour of the first paragraph of
+// text, unless there is none; badge, HTML and template-tag lines, such as a
+// "# {{ project_name }}" title, are ignored. It returns ""
+// if the template has no README.
+func readmeDescription(dir string) string {
+	var data []byte
+	for _, name := range readmeFiles {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(dir, name)); err == nil {
+			break
+		}
+	}
+
+	var heading string
+	var paragraph []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			if len(paragraph) > 0 {
+				return strings.Join(paragraph, " ")
+			}
+		case strings.HasPrefix(line, "![") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "<"),
+			strings.Contains(line, "{{") || strings.Contains(line, "{%"):
+		case strings.HasPrefix(line, "#"):
+			if len(paragraph) > 0 {
+				return strings.Join(paragraph, " ")
+			}
+			if heading == "" {
+				heading = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+
+	if len(paragraph) > 0 {
+		return strings.Join(paragraph, " ")
+	}
+	return heading
+}
+
+// validateForRegister validates a template about to be registered. The report
+// is printed in full with --validate, and otherwise only when it fails.
+func validateForRegister(cmd *cobra.Command, sourcePath string) error {
+	report, err := checkTemplate(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	err = validationError(report, registerStrict)
+	if registerValidate || err != nil {
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, "📿 Validating template structure...")
+		printValidationReport(out, report, true)
+	}
+	if err == nil && registerValidate {
+		fmt.Fprintln(cmd.OutOrStdout(), "💫 Template structure confirmed")
+	}
+
+	return err
+}
+
+// previewRegister prints what registering the template would record, without
+// copying it or changing the registry
+func previewRegister(cmd *cobra.Command, name, sourcePath, description, source string) error {
+	reg, err := openRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	opts := registry.AddOptions{IncludeHidden: registerHidden}
+	tmpl, err := reg.Preview(name, sourcePath, description, registerType, opts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, "[DRY RUN] Analyzed:", tmpl.Source)
+	if source != "" {
+		fmt.Fprintf(out, "[DRY RUN] Source: %s\n", source)
+	}
+	fmt.Fprintf(out, "[DRY RUN] Would register as: %s\n", tmpl.Name)
+	fmt.Fprintf(out, "[DRY RUN] Would copy to: %s\n", tmpl.Path)
+	if tmpl.Description != "" {
+		fmt.Fprintf(out, "[DRY RUN] Description: %s\n", tmpl.Description)
+	}
+	if tmpl.Type != "" {
+		fmt.Fprintf(out, "[DRY RUN] Type: %s\n", tmpl.Type)
+	}
+	fmt.Fprintf(out, "[DRY RUN] Files: %d (%s)\n", tmpl.Files, formatSize(tmpl.Size))
+	if tmpl.IncludeHidden {
+		fmt.Fprintln(out, "[DRY RUN] Hidden files: included")
+	}
+	if len(tmpl.Variables) > 0 {
+		fmt.Fprintf(out, "[DRY RUN] Variables: %s\n", strings.Join(tmpl.Variables, ", "))
+	} else {
+		fmt.Fprintln(out, "[DRY RUN] Variables: none detected")
+	}
+
+	if entry, err := reg.Entry(name); err == nil && entry.Name == name && entry.Origin != registry.OriginSystem {
+		if !registerForce {
+			fmt.Fprintf(out, "⚠️  [DRY RUN] Template '%s' already exists; registering would need --force\n", name)
+			return nil
+		}
+		fmt.Fprintf(out, "[DRY RUN] Would replace existing template '%s'\n", name)
+	}
+
+	fmt.Fprintln(out, "🔮 [DRY RUN] Template ready for registration. Use without --dry-run to register.")
+	return nil
+}
+
 // removeCmd removes a template from the registry
 var removeCmd = &cobra.Command{
 	Use:     "remove [name]",
@@ -218,7 +425,7 @@ func runRemove(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("※ The ason prepares to release template from registry...")
 
-	reg, err := registry.NewRegistry()
+	reg, err := openRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to initialize registry: %w", err)
 	}
@@ -238,7 +445,7 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	if tmpl == nil {
-		return fmt.Errorf("template '%s' not found in registry", name)
+		return templateNotFoundError(reg, name)
 	}
 
 	if removeDryRun {
@@ -270,19 +477,28 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Without --backup-dir, backups go where the user config says
+	backupDir := removeBackupDir
 	if removeBackup {
 		fmt.Println("✨ Creating backup before removal...")
+		if backupDir == "" {
+			userConfig, _, err := loadUserConfig()
+			if err != nil {
+				return err
+			}
+			backupDir = userConfig.BackupDir
+		}
 	}
 
 	fmt.Printf("✨ Removing template '%s'...\n", name)
 
 	// Remove template from registry
-	if err := reg.Remove(name, removeBackup, removeBackupDir); err != nil {
+	if err := reg.Remove(name, removeBackup, backupDir); err != nil {
 		return fmt.Errorf("failed to remove template: %w", err)
 	}
 
 	if removeBackup {
-		fmt.Printf("💫 Backup created in: %s\n", getBackupDir(removeBackupDir))
+		fmt.Printf("💫 Backup created in: %s\n", getBackupDir(backupDir))
 	}
 
 	fmt.Printf("🔮 Template '%s' removed successfully!\n", name)
@@ -315,9 +531,9 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		path = filepath.Join(home, path[2:])
 	}
 
-	fmt.Printf("※ Validating template: %s\n\n", path)
+	fmt.Fprintf(cmd.OutOrStdout(), "※ Validating template: %s\n\n", path)
 
-	return validateTemplate(path)
+	return validateTemplate(cmd.OutOrStdout(), path, validateStrict)
 }
 
 // Helper functions
@@ -359,37 +575,63 @@ func sortTemplates(templates []registry.TemplateEntry, sortBy string, reverse bo
 	})
 }
 
-func printTemplatesTable(templates []registry.TemplateEntry) error {
+func printTemplatesTable(templates []registry.TemplateEntry, total int) error {
 	fmt.Println("※ Templates ready for invocation:")
 	fmt.Println()
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tDESCRIPTION\tTYPE\tSIZE\tADDED")
-	fmt.Fprintln(w, "----\t-----------\t----\t----\t-----")
+	if listLong {
+		fmt.Fprintln(w, "NAME\tDESCRIPTION\tTYPE\tVERSION\tTAGS\tVARS\tSIZE\tADDED\tSOURCE")
+		fmt.Fprintln(w, "----\t-----------\t----\t-------\t----\t----\t----\t-----\t------")
+	} else {
+		fmt.Fprintln(w, "NAME\tDESCRIPTION\tTYPE\tSIZE\tADDED")
+		fmt.Fprintln(w, "----\t-----------\t----\t----\t-----")
+	}
 
 	for _, tmpl := range templates {
 		desc := tmpl.Description
-		if len(desc) > 40 {
+		if len(desc) > 40 && !listLong {
 			desc = desc[:37] + "..."
 		}
-		if desc == "" {
-			desc = "-"
+
+		name := tmpl.Name
+		if tmpl.Origin == registry.OriginSystem {
+			name += " (system)"
+		}
+		if tmpl.Outdated {
+			name += " (outdated)"
 		}
 
-		tmplType := tmpl.Type
-		if tmplType == "" {
-			tmplType = "-"
+		if listLong {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				name,
+				orDash(desc),
+				orDash(tmpl.Type),
+				orDash(tmpl.Version),
+				orDash(strings.Join(tmpl.Tags, ",")),
+				len(tmpl.Variables),
+				formatSize(tmpl.Size),
+				formatListTime(tmpl.Added),
+				orDash(tmpl.Source))
+			continue
 		}
 
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			tmpl.Name,
-			desc,
-			tmplType,
+			name,
+			orDash(desc),
+			orDash(tmpl.Type),
 			formatSize(tmpl.Size),
-			formatTime(tmpl.Added))
+			formatListTime(tmpl.Added))
 	}
 
 	w.Flush()
+	if len(templates) < total {
+		if len(templates) == 0 {
+			fmt.Printf("\nNo templates past offset %d (%d total)\n", listOffset, total)
+		} else {
+			fmt.Printf("\nShowing %d-%d of %d templates\n", listOffset+1, listOffset+len(templates), total)
+		}
+	}
 	fmt.Println()
 	fmt.Println("💡 Use 'ason new TEMPLATE OUTPUT_DIR' to create a project")
 	fmt.Println("💡 Use 'ason register' to prepare more templates for invocation")
@@ -397,10 +639,21 @@ func printTemplatesTable(templates []registry.TemplateEntry) error {
 	return nil
 }
 
-func printTemplatesJSON(templates []registry.TemplateEntry) error {
+// orDash returns s, or "-" for an empty table cell
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// printTemplatesJSON prints a page of templates; total counts the templates
+// before --offset and --limit were applied
+func printTemplatesJSON(templates []registry.TemplateEntry, total int) error {
 	output := map[string]interface{}{
 		"templates": templates,
-		"total":     len(templates),
+		"total":     total,
+		"count":     len(templates),
 	}
 
 	data, err := json.MarshalIndent(output, "", "  ")
@@ -412,10 +665,11 @@ func printTemplatesJSON(templates []registry.TemplateEntry) error {
 	return nil
 }
 
-func printTemplatesYAML(templates []registry.TemplateEntry) error {
+func printTemplatesYAML(templates []registry.TemplateEntry, total int) error {
 	output := map[string]interface{}{
 		"templates": templates,
-		"total":     len(templates),
+		"total":     total,
+		"count":     len(templates),
 	}
 
 	// Use TOML format instead of YAML
@@ -429,85 +683,120 @@ func printTemplatesYAML(templates []registry.TemplateEntry) error {
 	return nil
 }
 
-func validateTemplate(templatePath string) error {
-	// Check if path exists
-	info, err := os.Stat(templatePath)
+// validateTemplate validates a template directory and prints the report to
+// w, grouped by category. It fails when the template has errors, or any
+// issues at all in strict mode.
+func validateTemplate(w io.Writer, templatePath string, strict bool) error {
+	report, err := checkTemplate(templatePath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("template not found at %s", templatePath)
-		}
-		return fmt.Errorf("failed to access template: %w", err)
+		return err
 	}
 
-	if !info.IsDir() {
-		return fmt.Errorf("template path must be a directory: %s", templatePath)
-	}
-
-	fmt.Println("✅ Structure Validation")
-	fmt.Println("   ✓ Template directory exists")
-
-	// Count files
-	fileCount := 0
-	err = filepath.Walk(templatePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			fileCount++
-		}
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("failed to analyze template: %w", err)
-	}
+	printValidationReport(w, report, !validateIgnoreWarnings)
+	return validationError(report, strict)
+}
 
-	if fileCount == 0 {
-		fmt.Println("❌ Template directory is empty")
-		return fmt.Errorf("template contains no files")
+// checkTemplate validates a template directory, reporting a missing one as
+// not found
+func checkTemplate(templatePath string) (*template.Report, error) {
+	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w at %s", registry.ErrTemplateNotFound, templatePath)
 	}
+	return template.Validate(templatePath)
+}
 
-	fmt.Printf("   ✓ Contains %d processable files\n", fileCount)
-	fmt.Println("   ✓ Directory structure is valid")
-
-	// Check for configuration file (ason.toml)
-	tomlPath := filepath.Join(templatePath, "ason.toml")
-
-	var config registry.TemplateConfig
+// validationCategories are the report sections, in the order printed
+var validationCategories = []struct {
+	category string
+	title    string
+}{
+	{template.CategoryStructure, "Structure Validation"},
+	{template.CategoryConfig, "Configuration Validation"},
+	{template.CategoryVariables, "Variable Validation"},
+}
 
-	if _, err := os.Stat(tomlPath); err == nil {
-		fmt.Println("\n✅ Configuration Validation")
-		fmt.Println("   ✓ ason.toml found")
+func printValidationReport(w io.Writer, report *template.Report, showWarnings bool) {
+	for i, section := range validationCategories {
+		var errs, warnings []template.Issue
+		for _, issue := range report.Issues {
+			if issue.Category != section.category {
+				continue
+			}
+			if issue.Severity == template.SeverityError {
+				errs = append(errs, issue)
+			} else if showWarnings {
+				warnings = append(warnings, issue)
+			}
+		}
 
-		data, err := os.ReadFile(tomlPath)
-		if err != nil {
-			fmt.Println("❌ Failed to read ason.toml")
-			return fmt.Errorf("failed to read config: %w", err)
+		icon := "✅"
+		if len(errs) > 0 {
+			icon = "❌"
+		} else if len(warnings) > 0 {
+			icon = "⚠️ "
 		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s %s\n", icon, section.title)
 
-		if err := toml.Unmarshal(data, &config); err != nil {
-			fmt.Println("❌ ason.toml syntax error")
-			return fmt.Errorf("invalid config syntax: %w", err)
+		for _, issue := range errs {
+			fmt.Fprintf(w, "   ✗ %s\n", issue.Message)
+		}
+		for _, issue := range warnings {
+			fmt.Fprintf(w, "   ⚠ %s\n", issue.Message)
 		}
 
-		fmt.Println("   ✓ ason.toml syntax is correct")
-		fmt.Println("   ✓ Configuration is valid")
-		if len(config.Variables) > 0 {
-			fmt.Printf("   ✓ Defines %d variables\n", len(config.Variables))
+		switch section.category {
+		case template.CategoryStructure:
+			if len(errs) == 0 {
+				fmt.Fprintf(w, "   ✓ Contains %d files\n", report.Files)
+			}
+		case template.CategoryConfig:
+			if report.Config != "" && len(errs) == 0 {
+				fmt.Fprintf(w, "   ✓ %s is valid\n", report.Config)
+			}
+		case template.CategoryVariables:
+			if len(errs) == 0 {
+				fmt.Fprintf(w, "   ✓ Defines %d variables\n", report.Variables)
+			}
 		}
-	} else {
-		fmt.Println("\n⚠️  Configuration Validation")
-		fmt.Println("   ⚠ No ason.toml found (optional)")
 	}
 
-	fmt.Println("\n🔮 Validation Summary:")
-	fmt.Println("   ✅ Template structure is valid")
-	fmt.Println("   ✅ Ready for use with Ason")
+	errCount, warnCount := len(report.Errors()), len(report.Warnings())
+	fmt.Fprintln(w, "\n🔮 Validation Summary:")
+	switch {
+	case errCount > 0:
+		fmt.Fprintf(w, "   ❌ %d errors, %d warnings\n", errCount, warnCount)
+	case warnCount > 0:
+		fmt.Fprintf(w, "   ⚠️  %d warnings\n", warnCount)
+	default:
+		fmt.Fprintln(w, "   ✅ Template structure is valid")
+		fmt.Fprintln(w, "   ✅ Ready for use with Ason")
+	}
+}
+
+// validationError summarises a failed report as an error, or returns nil if
+// the template passed
+func validationError(report *template.Report, strict bool) error {
+	if !report.Failed(strict) {
+		return nil
+	}
 
-	return nil
+	issues := report.Errors()
+	if strict {
+		issues = append(issues, report.Warnings()...)
+	}
+
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.String()
+	}
+	return fmt.Errorf("template validation failed: %s", strings.Join(messages, "; "))
 }
 
 func validateAllTemplates() error {
-	reg, err := registry.NewRegistry()
+	reg, err := openRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to initialize registry: %w", err)
 	}
@@ -527,7 +816,7 @@ func validateAllTemplates() error {
 	var failed []string
 	for i, tmpl := range templates {
 		fmt.Printf("[%d/%d] Validating: %s\n", i+1, len(templates), tmpl.Name)
-		if err := validateTemplate(tmpl.Path); err != nil {
+		if err := validateTemplate(os.Stdout, tmpl.Path, validateStrict); err != nil {
 			failed = append(failed, tmpl.Name)
 			fmt.Printf("❌ Validation failed: %v\n\n", err)
 		} else {
@@ -576,6 +865,22 @@ func formatTime(t time.Time) string {
 	}
 }
 
+// listTimeFormats are the values accepted by list --time-format
+var listTimeFormats = []string{"relative", "absolute", "rfc3339"}
+
+// formatListTime formats a time for the list table according to
+// --time-format. JSON and YAML output always carry the full timestamp.
+func formatListTime(t time.Time) string {
+	switch listTime {
+	case "absolute":
+		return t.Local().Format("2006-01-02 15:04")
+	case "rfc3339":
+		return t.Format(time.RFC3339)
+	default:
+		return formatTime(t)
+	}
+}
+
 func getBackupDir(customDir string) string {
 	if customDir != "" {
 		return customDir
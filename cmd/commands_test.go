@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/registry"
 )
 
 func TestListCmd(t *testing.T) {
@@ -175,6 +181,200 @@ func TestRegisterCmdAliasWorks(t *testing.T) {
 	registerCmd.SetOut(nil)
 }
 
+func TestRegisterCmdValidation(t *testing.T) {
+	withReadme := map[string]string{"README.md": "# {{ project_name }}"}
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		validate   bool
+		noValidate bool
+		strict     bool
+		wantErr    string
+	}{
+		{name: "empty with --validate", validate: true, wantErr: "structure: template contains no files"},
+		{name: "empty by default", wantErr: "structure: template contains no files"},
+		{name: "empty with --no-validate", noValidate: true},
+		{name: "warnings only", files: withReadme},
+		{name: "warnings with --strict", files: withReadme, strict: true, wantErr: "config: no config file found"},
+		{
+			name:    "broken config",
+			files:   map[string]string{"ason.toml": "engine = \"jinja\"\n"},
+			wantErr: `config: unknown engine "jinja"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryDir = t.TempDir()
+			registerValidate = tt.validate
+			registerNoValidate = tt.noValidate
+			registerStrict = tt.strict
+			defer func() {
+				registryDir = ""
+				registerValidate = false
+				registerNoValidate = false
+				registerStrict = false
+			}()
+
+			sourceDir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			var buf bytes.Buffer
+			registerCmd.SetOut(&buf)
+			defer registerCmd.SetOut(nil)
+
+			err := registerCmd.RunE(registerCmd, []string{"checked", sourceDir})
+
+			reg, openErr := openRegistry()
+			if openErr != nil {
+				t.Fatalf("Failed to open registry: %v", openErr)
+			}
+			_, entryErr := reg.Entry("checked")
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("register failed: %v", err)
+				}
+				if entryErr != nil {
+					t.Errorf("template was not registered: %v", entryErr)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("register error = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(entryErr, registry.ErrTemplateNotFound) {
+				t.Errorf("refused template was registered anyway")
+			}
+			if !strings.Contains(buf.String(), "🔮 Validation Summary:") {
+				t.Errorf("refusal did not print the validation report:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestReadmeDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		readme string
+		want   string
+	}{
+		{"paragraph after heading", "README.md", "# Go Service\n\nA gRPC service\nwith metrics.\n\nMore text.", "A gRPC service with metrics."},
+		{"heading only", "README.md", "## Go Service\n", "Go Service"},
+		{"plain README", "README", "\n\nMinimal template\n", "Minimal template"},
+		{"badges and template title skipped", "README.md", "# {{ project_name }}\n[![CI](x)](y)\n\nCLI starter\n", "CLI starter"},
+		{"no README", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.readme), 0644); err != nil {
+					t.Fatalf("Failed to write README: %v", err)
+				}
+			}
+
+			if got := readmeDescription(dir); got != tt.want {
+				t.Errorf("readmeDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterCmdDescriptionFromReadme(t *testing.T) {
+	registryDir = t.TempDir()
+	registerReadme = true
+	defer func() {
+		registryDir = ""
+		registerReadme = false
+	}()
+
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# Lambda\n\nPython AWS Lambda function.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create README: %v", err)
+	}
+
+	if err := registerCmd.RunE(registerCmd, []string{"lambda", sourceDir}); err != nil {
+		t.Fatalf("registerCmd failed: %v", err)
+	}
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	entry, err := reg.Entry("lambda")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if entry.Description != "Python AWS Lambda function." {
+		t.Errorf("Description = %q, want the README's first paragraph", entry.Description)
+	}
+}
+
+func TestRegisterCmdDryRun(t *testing.T) {
+	registryDir = t.TempDir()
+	registerDryRun = true
+	defer func() {
+		registryDir = ""
+		registerDryRun = false
+	}()
+
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"README.md": "# {{ project_name }}",
+		"main.go":   "package main",
+		"ason.toml": `description = "Go service"
+
+[[variables]]
+name = "project_name"
+
+[[variables]]
+name = "module_path"
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	registerCmd.SetOut(&buf)
+	defer registerCmd.SetOut(nil)
+
+	if err := registerCmd.RunE(registerCmd, []string{"go-svc", sourceDir}); err != nil {
+		t.Fatalf("registerCmd --dry-run failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"Would register as: go-svc",
+		"Description: Go service",
+		"Files: 3 (",
+		"Variables: project_name, module_path",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry-run output missing %q:\n%s", want, output)
+		}
+	}
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	if _, err := reg.Entry("go-svc"); !errors.Is(err, registry.ErrTemplateNotFound) {
+		t.Errorf("dry run registered the template: Entry() error = %v", err)
+	}
+}
+
 func TestRemoveCmd(t *testing.T) {
 	// Test remove command properties
 	if removeCmd == nil {
@@ -327,3 +527,404 @@ func TestCommandsAreRegistered(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistryDirFlag(t *testing.T) {
+	if rootCmd.PersistentFlags().Lookup("registry-dir") == nil {
+		t.Fatal("--registry-dir flag should be defined")
+	}
+
+	registryDir = filepath.Join(t.TempDir(), "personal")
+	defer func() { registryDir = "" }()
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	if err := registerCmd.RunE(registerCmd, []string{"custom-home", templateDir}); err != nil {
+		t.Fatalf("registerCmd execution failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(registryDir, "templates", "custom-home", "README.md")); err != nil {
+		t.Errorf("Template should be registered under --registry-dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(registryDir, "registry.toml")); err != nil {
+		t.Errorf("Registry metadata should be written under --registry-dir: %v", err)
+	}
+}
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestListCmdOutdated(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# source"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := reg.Add("drifting", sourceDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	listOutdated = true
+	defer func() {
+		listOutdated = false
+		listFormat = "table"
+	}()
+
+	// Unchanged right after registering
+	out := captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if strings.Contains(out, "(outdated)") {
+		t.Errorf("Freshly registered template shown as outdated:\n%s", out)
+	}
+
+	// The source gains a file
+	if err := os.WriteFile(filepath.Join(sourceDir, "NEW.md"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to modify template source: %v", err)
+	}
+
+	out = captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "drifting (outdated)") {
+		t.Errorf("Modified template should be marked outdated:\n%s", out)
+	}
+
+	listFormat = "json"
+	out = captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, `"outdated": true`) {
+		t.Errorf("JSON output should include outdated: true:\n%s", out)
+	}
+}
+
+func TestListCmdLong(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	description := "A service template with a description well over forty characters"
+	config := `description = "` + description + `"
+version = "1.2.0"
+tags = ["go", "grpc"]
+
+[[variables]]
+name = "project_name"
+
+[[variables]]
+name = "module_path"
+
+[[variables]]
+name = "port"
+`
+	if err := os.WriteFile(filepath.Join(sourceDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	if err := reg.Add("svc", sourceDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if strings.Contains(out, sourceDir) || strings.Contains(out, description) {
+		t.Errorf("Compact list should truncate descriptions and omit sources:\n%s", out)
+	}
+
+	listLong = true
+	defer func() { listLong = false }()
+
+	out = captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd --long execution failed: %v", err)
+		}
+	})
+	for _, want := range []string{"VARS", "SOURCE", sourceDir, description, "1.2.0", "go,grpc"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("--long output missing %q:\n%s", want, out)
+		}
+	}
+
+	var row string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "svc ") {
+			row = line
+		}
+	}
+	if fields := strings.Fields(row); !containsField(fields, "3") {
+		t.Errorf("--long row should show 3 variables: %q", row)
+	}
+}
+
+func containsField(fields []string, want string) bool {
+	for _, f := range fields {
+		if f == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestListTimeFormat(t *testing.T) {
+	added := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	templates := []registry.TemplateEntry{{Name: "svc", Added: added}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"relative", "3 days ago"},
+		{"absolute", added.Local().Format("2006-01-02 15:04")},
+		{"rfc3339", added.Format(time.RFC3339)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			listTime = tt.format
+			defer func() { listTime = "relative" }()
+
+			out := captureStdout(t, func() {
+				if err := printTemplatesTable(templates, len(templates)); err != nil {
+					t.Fatalf("printTemplatesTable failed: %v", err)
+				}
+			})
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("table missing %q:\n%s", tt.want, out)
+			}
+			if tt.format != "relative" && strings.Contains(out, "days ago") {
+				t.Errorf("--time-format %s still printed a relative time:\n%s", tt.format, out)
+			}
+		})
+	}
+
+	listTime = "iso"
+	defer func() { listTime = "relative" }()
+	if err := listCmd.RunE(listCmd, []string{}); ExitCode(err) != ExitUsage {
+		t.Errorf("invalid --time-format error = %v, want a usage error", err)
+	}
+}
+
+func TestListCmdPagination(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# source"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	for _, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		if err := reg.Add(name, sourceDir, "", ""); err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+	}
+
+	listLimit, listOffset, listFormat = 2, 1, "json"
+	defer func() { listLimit, listOffset, listFormat = 0, 0, "table" }()
+
+	out := captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+
+	var result struct {
+		Templates []registry.TemplateEntry `json:"templates"`
+		Total     int                      `json:"total"`
+		Count     int                      `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\n%s", err, out)
+	}
+
+	var names []string
+	for _, tmpl := range result.Templates {
+		names = append(names, tmpl.Name)
+	}
+	if strings.Join(names, ",") != "bravo,charlie" {
+		t.Errorf("--limit 2 --offset 1 returned %v, want [bravo charlie]", names)
+	}
+	if result.Total != 5 || result.Count != 2 {
+		t.Errorf("total, count = %d, %d; want 5, 2", result.Total, result.Count)
+	}
+
+	// The table notes which window it shows
+	listFormat = "table"
+	out = captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Showing 2-3 of 5 templates") || strings.Contains(out, "alpha") {
+		t.Errorf("table output does not reflect the page:\n%s", out)
+	}
+
+	// An offset past the end returns no templates but keeps the total
+	listOffset, listFormat = 10, "json"
+	out = captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd execution failed: %v", err)
+		}
+	})
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\n%s", err, out)
+	}
+	if len(result.Templates) != 0 || result.Total != 5 {
+		t.Errorf("offset past the end: %d templates of %d total, want 0 of 5", len(result.Templates), result.Total)
+	}
+}
+
+func TestRegisterCmdSource(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	cloneDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cloneDir, "README.md"), []byte("# Demo"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	const source = "https://github.com/example/go-service.git"
+	registerSource = source
+	defer func() { registerSource = "" }()
+
+	captureStdout(t, func() {
+		if err := registerCmd.RunE(registerCmd, []string{"go-service", cloneDir}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	})
+
+	listLong = true
+	defer func() { listLong = false }()
+	out := captureStdout(t, func() {
+		if err := listCmd.RunE(listCmd, []string{}); err != nil {
+			t.Fatalf("listCmd --long execution failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, source) || strings.Contains(out, cloneDir) {
+		t.Errorf("list --long should show the recorded source instead of the clone:\n%s", out)
+	}
+}
+
+func TestRegisterCmdIncludeHidden(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml": "name: ci",
+		".private/notes.txt":       "notes",
+		"README.md":                "# Demo",
+	}
+	for name, content := range files {
+		path := filepath.Join(templateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	registerHidden = true
+	defer func() { registerHidden = false }()
+
+	captureStdout(t, func() {
+		if err := registerCmd.RunE(registerCmd, []string{"dotfiles", templateDir}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	})
+
+	outputDir := t.TempDir()
+	captureStdout(t, func() {
+		if err := newCmd.RunE(newCmd, []string{"dotfiles", outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+	})
+
+	for _, name := range []string{".github/workflows/ci.yml", ".private/notes.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("%s should be generated from a template registered with --include-hidden: %v", name, err)
+		}
+	}
+}
+
+func TestRegisterCmdGitURL(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("TMPDIR", t.TempDir())
+	bare := newTestGitRemote(t)
+
+	url := "file://" + bare + "#v1"
+	captureStdout(t, func() {
+		if err := registerCmd.RunE(registerCmd, []string{"remote", url}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	})
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	entry, err := reg.Entry("remote")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if entry.Source != url {
+		t.Errorf("Source = %q, want %q", entry.Source, url)
+	}
+	got, err := os.ReadFile(filepath.Join(entry.Path, "README.md"))
+	if err != nil || string(got) != "# {{ name }} v1" {
+		t.Errorf("Registered README.md = %q, %v, want the v1 version", got, err)
+	}
+}
@@ -1,17 +1,20 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/xdg"
 	"github.com/spf13/cobra"
 )
 
 // completeTemplateNames provides completion for template names from the registry
 func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	reg, err := registry.NewRegistry()
+	reg, err := openRegistry()
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -36,7 +39,7 @@ func completeTemplateNamesOrPaths(cmd *cobra.Command, args []string, toComplete
 	var completions []string
 
 	// First, try to complete template names from registry
-	reg, err := registry.NewRegistry()
+	reg, err := openRegistry()
 	if err == nil {
 		templates, err := reg.List()
 		if err == nil {
@@ -144,6 +147,11 @@ func completeVariableKeys(cmd *cobra.Command, args []string, toComplete string)
 	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeEngineNames provides completion for template engine names
+func completeEngineNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return engine.Names, cobra.ShellCompDirectiveNoFileComp
+}
+
 // completeRegisterCommand provides completion for the register command
 func completeRegisterCommand(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// First argument is template name (no completion needed, it's user-defined)
@@ -159,8 +167,143 @@ func completeRegisterCommand(cmd *cobra.Command, args []string, toComplete strin
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completionPrint makes `completion install` print the script instead of
+// installing it
+var completionPrint bool
+
+var completionInstallCmd = &cobra.Command{
+	Use:   "install [shell]",
+	Short: "Install the completion script for your shell",
+	Long: `Install the completion script where your shell loads completions from.
+
+The shell is taken from $SHELL unless given. Scripts are written to:
+  bash   $XDG_DATA_HOME/bash-completion/completions/ason
+  zsh    ~/.zfunc/_ason
+  fish   $XDG_CONFIG_HOME/fish/completions/ason.fish
+
+PowerShell has no standard location; use --print and add the script to your profile.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: completionShells,
+	RunE:      runCompletionInstall,
+}
+
+func init() {
+	completionInstallCmd.Flags().BoolVar(&completionPrint, "print", false, "Print the script instead of installing it")
+}
+
+// completionShells lists the shells cobra generates completions for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// detectShell maps a shell path such as $SHELL to a completion shell name
+func detectShell(shellPath string) (string, error) {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(shellPath)), ".exe")
+	switch name {
+	case "bash", "zsh", "fish", "powershell":
+		return name, nil
+	case "pwsh":
+		return "powershell", nil
+	case "", ".":
+		return "", usageErrorf("cannot detect your shell from $SHELL; name it, e.g. ason completion install bash")
+	}
+	return "", usageErrorf("unsupported shell %q (supported: %s)", name, strings.Join(completionShells, ", "))
+}
+
+// genCompletion writes the completion script for shell
+func genCompletion(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	}
+	return fmt.Errorf("unsupported shell %q", shell)
+}
+
+// completionInstallPath returns where shell loads user completions from
+func completionInstallPath(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		dataHome, err := xdg.DataHome()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(dataHome), "bash-completion", "completions", "ason"), nil
+	case "zsh":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".zfunc", "_ason"), nil
+	case "fish":
+		configHome, err := xdg.ConfigHome()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(configHome), "fish", "completions", "ason.fish"), nil
+	}
+	return "", fmt.Errorf("%s has no standard completion directory; use --print and load the script from your profile", shell)
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) error {
+	shellPath := os.Getenv("SHELL")
+	if len(args) > 0 {
+		shellPath = args[0]
+	}
+	shell, err := detectShell(shellPath)
+	if err != nil {
+		return err
+	}
+
+	if completionPrint {
+		return genCompletion(cmd.Root(), shell, cmd.OutOrStdout())
+	}
+
+	path, err := completionInstallPath(shell)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create completion directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+	defer f.Close()
+
+	if err := genCompletion(cmd.Root(), shell, f); err != nil {
+		return fmt.Errorf("failed to generate completion script: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "✓ Installed %s completions to %s\n", shell, path)
+	switch shell {
+	case "bash":
+		fmt.Fprintln(out, "💡 Requires the bash-completion package; start a new shell to use them")
+	case "zsh":
+		fmt.Fprintln(out, "💡 Add this to ~/.zshrc before compinit, then start a new shell:")
+		fmt.Fprintln(out, "   fpath=(~/.zfunc $fpath)")
+	case "fish":
+		fmt.Fprintln(out, "💡 Start a new shell to use them")
+	}
+	return nil
+}
+
 // setupCompletions configures completion for all commands
 func setupCompletions() {
+	// Add `completion install` to cobra's completion command
+	rootCmd.InitDefaultCompletionCmd()
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "completion" && !completionInstallCmd.HasParent() {
+			c.AddCommand(completionInstallCmd)
+		}
+	}
+
 	// Set up completion for the new command
 	newCmd.ValidArgsFunction = completeTemplateNamesOrPaths
 
@@ -179,4 +322,8 @@ func setupCompletions() {
 	})
 
 	newCmd.RegisterFlagCompletionFunc("var", completeVariableKeys)
+
+	newCmd.RegisterFlagCompletionFunc("engine", completeEngineNames)
+	renderCmd.RegisterFlagCompletionFunc("engine", completeEngineNames)
+	renderCmd.RegisterFlagCompletionFunc("var", completeVariableKeys)
 }
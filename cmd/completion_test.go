@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -268,3 +270,74 @@ func TestSetupCompletions(t *testing.T) {
 		t.Error("validateCmd should have ValidArgsFunction set")
 	}
 }
+
+func TestDetectShell(t *testing.T) {
+	tests := map[string]string{
+		"/bin/bash":          "bash",
+		"/usr/local/bin/zsh": "zsh",
+		"/opt/homebrew/fish": "fish",
+		"/usr/bin/pwsh":      "powershell",
+		"powershell.exe":     "powershell",
+		"zsh":                "zsh",
+	}
+
+	for shellPath, want := range tests {
+		got, err := detectShell(shellPath)
+		if err != nil {
+			t.Errorf("detectShell(%q) failed: %v", shellPath, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("detectShell(%q) = %q, want %q", shellPath, got, want)
+		}
+	}
+
+	for _, shellPath := range []string{"", "/bin/tcsh"} {
+		if _, err := detectShell(shellPath); err == nil {
+			t.Errorf("detectShell(%q) should fail", shellPath)
+		}
+	}
+}
+
+func TestCompletionInstallPrint(t *testing.T) {
+	completionPrint = true
+	defer func() { completionPrint = false }()
+
+	var buf bytes.Buffer
+	completionInstallCmd.SetOut(&buf)
+	defer completionInstallCmd.SetOut(nil)
+
+	if err := completionInstallCmd.RunE(completionInstallCmd, []string{"bash"}); err != nil {
+		t.Fatalf("completion install --print bash failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "_ason") {
+		t.Errorf("Printed script should define the ason completion function, got:\n%.200s", buf.String())
+	}
+}
+
+func TestCompletionInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("SHELL", "/usr/bin/fish")
+
+	var buf bytes.Buffer
+	completionInstallCmd.SetOut(&buf)
+	defer completionInstallCmd.SetOut(nil)
+
+	if err := completionInstallCmd.RunE(completionInstallCmd, nil); err != nil {
+		t.Fatalf("completion install failed: %v", err)
+	}
+
+	path := filepath.Join(home, "config", "fish", "completions", "ason.fish")
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Completion script not written to %s: %v", path, err)
+	}
+	if !strings.Contains(string(script), "complete -c ason") {
+		t.Errorf("Installed script doesn't look like fish completions:\n%.200s", script)
+	}
+	if !strings.Contains(buf.String(), path) {
+		t.Errorf("Output should name the installed path, got %q", buf.String())
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/madstone-tech/ason/internal/userconfig"
+	"github.com/spf13/cobra"
+)
+
+// configCmd groups commands that view and change the user config
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change your ason settings",
+	Long: `View and change your ason settings, stored in config.toml in the ason
+config directory ($XDG_CONFIG_HOME/ason, usually ~/.config/ason).
+
+Known settings:
+  author          Default for template variables named author
+  default_engine  Engine for templates that don't name one (pongo2, go)
+  color           Color output (auto, always, never)
+  quiet           Suppress progress output and the summary of ason new
+  backup_dir      Directory for ason remove --backup
+  cache_ttl       How long remote templates are cached (0 disables the cache)`,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file location",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPath,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings and their values",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Change a setting; an empty value clears it",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+
+	configGetCmd.ValidArgsFunction = completeConfigKeys
+	configSetCmd.ValidArgsFunction = completeConfigKeys
+}
+
+// loadUserConfig loads the user config and returns it with its path
+func loadUserConfig() (*userconfig.Config, string, error) {
+	path, err := userconfig.Path()
+	if err != nil {
+		return nil, "", err
+	}
+	config, err := userconfig.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return config, path, nil
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	path, err := userconfig.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), path)
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	config, _, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, key := range userconfig.Keys {
+		value, err := config.Get(key.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\n", key.Name, orDash(value))
+	}
+	return w.Flush()
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if _, err := userconfig.LookupKey(args[0]); err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	config, _, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	value, err := config.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	config, path, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := config.Set(args[0], args[1]); err != nil {
+		return usageErrorf("%v", err)
+	}
+	return config.Save(path)
+}
+
+// completeConfigKeys completes the first argument with the known settings
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var keys []string
+	for _, key := range userconfig.Keys {
+		keys = append(keys, key.Name+"\t"+key.Description)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
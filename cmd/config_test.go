@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestConfigCmd(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configPath := filepath.Join(configHome, "ason", "config.toml")
+
+	var buf bytes.Buffer
+	for _, c := range []*cobra.Command{configPathCmd, configListCmd, configGetCmd, configSetCmd} {
+		c.SetOut(&buf)
+		defer c.SetOut(nil)
+	}
+
+	if err := configPathCmd.RunE(configPathCmd, nil); err != nil {
+		t.Fatalf("config path failed: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != configPath {
+		t.Errorf("config path = %q, want %q", got, configPath)
+	}
+
+	if err := configSetCmd.RunE(configSetCmd, []string{"author", "Ada Lovelace"}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("config set should write %s: %v", configPath, err)
+	}
+	if !strings.Contains(string(data), `author = "Ada Lovelace"`) {
+		t.Errorf("config file = %q, want the author", data)
+	}
+
+	buf.Reset()
+	if err := configGetCmd.RunE(configGetCmd, []string{"author"}); err != nil {
+		t.Fatalf("config get failed: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "Ada Lovelace" {
+		t.Errorf("config get author = %q, want %q", got, "Ada Lovelace")
+	}
+
+	buf.Reset()
+	if err := configListCmd.RunE(configListCmd, nil); err != nil {
+		t.Fatalf("config list failed: %v", err)
+	}
+	for _, want := range []string{"author          Ada Lovelace", "default_engine  -", "quiet           false"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("config list should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	for _, args := range [][]string{{"editor", "vim"}, {"default_engine", "jinja"}} {
+		if err := configSetCmd.RunE(configSetCmd, args); ExitCode(err) != ExitUsage {
+			t.Errorf("config set %v error = %v, want a usage error", args, err)
+		}
+	}
+	if err := configGetCmd.RunE(configGetCmd, []string{"editor"}); ExitCode(err) != ExitUsage {
+		t.Errorf("config get editor error = %v, want a usage error", err)
+	}
+}
+
+func TestNewCmdUserConfigAuthor(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := configSetCmd.RunE(configSetCmd, []string{"author", "Ada Lovelace"}); err != nil {
+		t.Fatalf("config set failed: %v", err)
+	}
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte("[[variables]]\nname = \"author\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "AUTHORS"), []byte("{{ author }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	noInput = true
+	defer func() { noInput = false }()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(outputDir, "AUTHORS")); string(content) != "Ada Lovelace" {
+		t.Errorf("AUTHORS = %q, want the configured author", content)
+	}
+}
+
+func TestNewCmdSaveDefaults(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := "[[variables]]\nname = \"org\"\ndefault = \"none\"\n\n[[variables]]\nname = \"token\"\nsecret = true\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "ORG"), []byte("{{ org }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	noInput = true
+	defer func() {
+		noInput = false
+		saveDefaults = false
+		extraVars = nil
+	}()
+
+	generate := func(vars map[string]string) string {
+		t.Helper()
+		extraVars = vars
+		outputDir := filepath.Join(t.TempDir(), "out")
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		content, _ := os.ReadFile(filepath.Join(outputDir, "ORG"))
+		return string(content)
+	}
+
+	saveDefaults = true
+	if got := generate(map[string]string{"org": "acme", "token": "s3cret"}); got != "acme" {
+		t.Fatalf("first run ORG = %q, want acme", got)
+	}
+	saveDefaults = false
+
+	userConfig, _, err := loadUserConfig()
+	if err != nil {
+		t.Fatalf("loadUserConfig failed: %v", err)
+	}
+	key, _ := filepath.Abs(templateDir)
+	if _, ok := userConfig.TemplateDefaults(key)["token"]; ok {
+		t.Error("secret variable was saved as a default")
+	}
+
+	if got := generate(nil); got != "acme" {
+		t.Errorf("second run ORG = %q, want the saved default acme", got)
+	}
+	if got := generate(map[string]string{"org": "other"}); got != "other" {
+		t.Errorf("third run ORG = %q, want --var to override the saved default", got)
+	}
+}
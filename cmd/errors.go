@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/madstone-tech/ason/internal/prompt"
+	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for ason, by kind of failure
+const (
+	ExitOK        = 0
+	ExitError     = 1   // any other failure
+	ExitUsage     = 2   // bad flags, arguments or an unknown command
+	ExitNotFound  = 3   // the requested template doesn't exist
+	ExitCancelled = 130 // the user cancelled a prompt or pressed Ctrl-C, as shells report SIGINT
+)
+
+// usageError marks a mistake in how a command was invoked, rather than a
+// failure while running it
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// usageErrorf formats a usage error
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// usageArgs wraps a cobra argument validator so its errors are usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return &usageError{err: err}
+		}
+		return nil
+	}
+}
+
+// markUsageErrors makes the flag and argument errors of cmd and its
+// subcommands usage errors
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+	if cmd.Args != nil {
+		cmd.Args = usageArgs(cmd.Args)
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, registry.ErrTemplateNotFound):
+		return ExitNotFound
+	case errors.Is(err, prompt.ErrCancelled), errors.Is(err, context.Canceled):
+		return ExitCancelled
+	case errors.As(err, &usage):
+		return ExitUsage
+	default:
+		return ExitError
+	}
+}
+
+// templateNotFoundError reports a template missing from the registry, with
+// the closest registered names as suggestions
+func templateNotFoundError(reg *registry.Registry, name string) error {
+	err := fmt.Errorf("%w: %s", registry.ErrTemplateNotFound, name)
+
+	suggestions := reg.Suggest(name, 3)
+	switch len(suggestions) {
+	case 0:
+		return err
+	case 1:
+		return fmt.Errorf("%w (did you mean %s?)", err, suggestions[0])
+	default:
+		last := len(suggestions) - 1
+		return fmt.Errorf("%w (did you mean %s or %s?)", err, strings.Join(suggestions[:last], ", "), suggestions[last])
+	}
+}
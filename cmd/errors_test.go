@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/ason/internal/prompt"
+	"github.com/madstone-tech/ason/internal/registry"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"generic", errors.New("boom"), ExitError},
+		{"usage", usageErrorf("bad flag"), ExitUsage},
+		{"wrapped usage", fmt.Errorf("context: %w", usageErrorf("bad flag")), ExitUsage},
+		{"not found", fmt.Errorf("%w: web", registry.ErrTemplateNotFound), ExitNotFound},
+		{"cancelled prompt", fmt.Errorf("%w while prompting for name", prompt.ErrCancelled), ExitCancelled},
+		{"interrupted generation", fmt.Errorf("generation was interrupted: %w", context.Canceled), ExitCancelled},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+// executeArgs runs the root command with args and returns what was written
+// to stderr and the error
+func executeArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	resetRootFlags(t)
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	err := Execute()
+	return stderr.String(), err
+}
+
+func TestExecuteTemplateNotFound(t *testing.T) {
+	originalRegistryDir := registryDir
+	defer func() { registryDir = originalRegistryDir }()
+
+	stderr, err := executeArgs(t, "new", "no-such-template", t.TempDir(), "--registry-dir", t.TempDir())
+
+	if code := ExitCode(err); code != ExitNotFound {
+		t.Errorf("ExitCode = %d, want %d (err: %v)", code, ExitNotFound, err)
+	}
+	if !strings.HasPrefix(stderr, "Error: template not found: no-such-template") {
+		t.Errorf("stderr = %q, want a plain error line", stderr)
+	}
+	if regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`).MatchString(stderr) {
+		t.Errorf("stderr has a log timestamp: %q", stderr)
+	}
+	if strings.Contains(stderr, "Usage:") {
+		t.Errorf("stderr should not include the usage text: %q", stderr)
+	}
+}
+
+func TestExecuteUsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"missing argument": {"new"},
+		"unknown flag":     {"list", "--no-such-flag"},
+		"unknown command":  {"frobnicate"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			stderr, err := executeArgs(t, args...)
+
+			if code := ExitCode(err); code != ExitUsage {
+				t.Errorf("ExitCode = %d, want %d (err: %v)", code, ExitUsage, err)
+			}
+			if !strings.Contains(stderr, "--help' for usage") {
+				t.Errorf("stderr should point at --help, got %q", stderr)
+			}
+		})
+	}
+}
+
+func TestTemplateNotFoundSuggestions(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# service"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := reg.Add("golang-service", sourceDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	t.Run("new", func(t *testing.T) {
+		err := newCmd.RunE(newCmd, []string{"golng-service", filepath.Join(t.TempDir(), "out")})
+		if err == nil || !strings.Contains(err.Error(), "did you mean golang-service?") {
+			t.Errorf("new error = %v, want a suggestion", err)
+		}
+		if ExitCode(err) != ExitNotFound {
+			t.Errorf("ExitCode() = %d, want %d", ExitCode(err), ExitNotFound)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err := removeCmd.RunE(removeCmd, []string{"golang-servic"})
+		if err == nil || !strings.Contains(err.Error(), "did you mean golang-service?") {
+			t.Errorf("remove error = %v, want a suggestion", err)
+		}
+	})
+
+	t.Run("no close match", func(t *testing.T) {
+		err := removeCmd.RunE(removeCmd, []string{"terraform"})
+		if err == nil || strings.Contains(err.Error(), "did you mean") {
+			t.Errorf("remove error = %v, want no suggestion", err)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logLevel is the minimum level of the diagnostics written to stderr. They
+// explain decisions, such as why a file was skipped, rather than report
+// progress, so only warnings and errors show by default.
+var logLevel = new(slog.LevelVar)
+
+// levelValue is a pflag.Value setting a slog level by name
+type levelValue struct {
+	level *slog.LevelVar
+}
+
+func (v levelValue) String() string {
+	return strings.ToLower(v.level.Level().String())
+}
+
+func (v levelValue) Set(s string) error {
+	return v.level.UnmarshalText([]byte(s))
+}
+
+func (v levelValue) Type() string {
+	return "level"
+}
+
+// setupLogging sends slog output to w as logfmt lines, filtered by logLevel
+func setupLogging(w io.Writer) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: logLevel})))
+}
+
+func init() {
+	logLevel.Set(slog.LevelWarn)
+	setupLogging(os.Stderr)
+
+	rootCmd.PersistentFlags().Var(levelValue{logLevel}, "log-level", "Diagnostic log level on stderr (debug, info, warn, error)")
+}
@@ -1,24 +1,73 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/pprof"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/fsutil"
 	"github.com/madstone-tech/ason/internal/generator"
+	"github.com/madstone-tech/ason/internal/prompt"
 	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/madstone-tech/ason/internal/template"
 	"github.com/madstone-tech/ason/internal/varfile"
+	asonversion "github.com/madstone-tech/ason/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var (
-	outputDir  string
-	noInput    bool
-	extraVars  map[string]string
-	varFile    string
-	configFile string
-	skipHooks  bool
-	dryRun     bool
+	outputDir   string
+	noInput     bool
+	extraVars   map[string]string
+	varLists    map[string][]string
+	varFile     string
+	configFile  string
+	skipHooks   bool
+	dryRun      bool
+	engineName  string
+	includes    []string
+	excludes    []string
+	quiet       bool
+	jsonOutput  bool
+	seed        int64
+	strictVars  bool
+	overwrite   string
+	noAutoVars  bool
+	dumpContext bool
+	timeout     time.Duration
+	noCleanup   bool
+	pruneEmpty  bool
+	jobs        int
+	pprofFile   string
+	promptOrder string
+	into        bool
+	force       bool
+	refresh     bool
+
+	saveDefaults   bool
+	acceptDefaults bool
+
+	validateVarsSchema bool
+)
+
+// Supported --prompt-order values
+const (
+	promptOrderDeclared = "declared"
+	promptOrderAlpha    = "alpha"
 )
 
 var newCmd = &cobra.Command{
@@ -37,80 +86,1120 @@ Examples:
   ason new lambda-waf-ipset ./output --var-file prod.toml
 
   # Mix file variables with CLI overrides
-  ason new lambda-waf-ipset ./output --var-file base.toml --var environment=prod`,
+  ason new lambda-waf-ipset ./output --var-file base.toml --var environment=prod
+
+  # Use another config profile from the template directory
+  ason new golang-service ./output --config ason.minimal.toml
+
+  # Generate only the CI config
+  ason new golang-service ./output --include '.github/**'
+
+  # Write the generated files as a tar stream to stdout
+  ason new golang-service --output - | tar -x -C ./my-service`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runNew,
 }
 
 func init() {
-	newCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory")
+	newCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory (- writes a tar stream to stdout)")
+	newCmd.Flags().BoolVar(&into, "into", false, "Generate into the current directory, asking first when it isn't empty and keeping existing files")
+	newCmd.Flags().BoolVar(&force, "force", false, "With --into, don't ask and overwrite existing files")
 	newCmd.Flags().BoolVar(&noInput, "no-input", false, "Don't prompt for variables")
-	newCmd.Flags().StringToStringVar(&extraVars, "var", nil, "Set variables (key=value)")
-	newCmd.Flags().StringVarP(&varFile, "var-file", "f", "", "Load variables from file (TOML, YAML, or JSON)")
+	newCmd.Flags().BoolVar(&acceptDefaults, "accept-defaults", false, "Don't prompt; use each variable's default and fail only for required variables without one")
+	newCmd.Flags().BoolVar(&acceptDefaults, "yes", false, "Same as --accept-defaults")
+	newCmd.Flags().StringVar(&promptOrder, "prompt-order", promptOrderDeclared, "Order to prompt for variables in (declared, alpha)")
+	newCmd.Flags().Var(&varsValue{values: &extraVars, lists: &varLists}, "var", "Set variables (key=value); repeat a key to build a list")
+	newCmd.Flags().StringVarP(&varFile, "var-file", "f", "", "Load variables from file (TOML, YAML, JSON, .tfvars, or .env)")
+	newCmd.Flags().BoolVar(&saveDefaults, "save-defaults", false, "Remember the variable values used as this template's defaults in the user config")
+	newCmd.Flags().BoolVar(&noAutoVars, "no-auto-vars", false, "Don't load ason.vars.{toml,yaml,json} from the current or output directory")
+	newCmd.Flags().StringVarP(&configFile, "config", "c", "", "Template config file to use instead of the template's ason.toml")
 	newCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be generated")
+	newCmd.Flags().BoolVar(&skipHooks, "skip-hooks", false, "Don't run the template's hooks")
+	newCmd.Flags().StringVar(&engineName, "engine", "", "Override the template engine (pongo2, go)")
+	newCmd.Flags().StringArrayVar(&includes, "include", nil, "Only generate template paths matching this glob (repeatable)")
+	newCmd.Flags().StringArrayVar(&excludes, "exclude", nil, "Skip template paths matching this glob (repeatable)")
+	newCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress output and the summary")
+	newCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Also log created directories and skipped paths")
+	newCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the generation summary as JSON")
+	newCmd.Flags().BoolVar(&dumpContext, "dump-context", false, "Print the merged template variables as JSON to stderr before generating")
+	newCmd.Flags().StringVar(&overwrite, "overwrite-policy", string(generator.OverwriteReplace), "What to do with existing output files (ask, overwrite, skip, backup)")
+	newCmd.Flags().BoolVar(&strictVars, "strict-vars", false, "Fail when a template uses an undefined variable")
+	newCmd.Flags().BoolVar(&validateVarsSchema, "validate-vars-against-schema", false, "Check all variable values against their declared types, choices, patterns and required flags before generating")
+	newCmd.Flags().DurationVar(&timeout, "timeout", 0, "Give up generating after this long, e.g. 30s or 2m (0 means no limit)")
+	newCmd.Flags().BoolVar(&pruneEmpty, "prune-empty-dirs", false, "Don't create directories left empty because everything in them was ignored or excluded")
+	newCmd.Flags().IntVar(&jobs, "jobs", 0, "Number of files to render at once (0 uses the number of CPUs)")
+	newCmd.Flags().StringVar(&pprofFile, "pprof", "", "Write a CPU profile of the generation to this file")
+	_ = newCmd.Flags().MarkHidden("pprof")
+	newCmd.Flags().BoolVar(&noCleanup, "no-cleanup", false, "Keep the partial output when generation fails, for debugging")
+	newCmd.Flags().BoolVar(&refresh, "refresh", false, "Fetch a remote template again instead of using the cached copy")
+	newCmd.Flags().Int64Var(&seed, "seed", 0, "Seed the uuid, random_int and random_string helpers for reproducible output")
 }
 
 func runNew(cmd *cobra.Command, args []string) error {
 	templateName := args[0]
 
+	if _, err := generator.ParseOverwritePolicy(overwrite); err != nil {
+		return usageErrorf("invalid --overwrite-policy: %v", err)
+	}
+	if jobs < 0 {
+		return usageErrorf("invalid --jobs %d (must be 0 or more)", jobs)
+	}
+	if promptOrder != promptOrderDeclared && promptOrder != promptOrderAlpha {
+		return usageErrorf("invalid --prompt-order %q (supported: %s, %s)", promptOrder, promptOrderDeclared, promptOrderAlpha)
+	}
+
+	// Without an output the template may name the directory itself
+	outputGiven := len(args) > 1 || cmd.Flags().Changed("output")
+	if into && outputGiven {
+		return usageErrorf("--into generates into the current directory and cannot be combined with an output directory")
+	}
+	if force && !into {
+		return usageErrorf("--force only applies with --into")
+	}
 	if len(args) > 1 {
 		outputDir = args[1]
 	}
+	if into {
+		outputDir, outputGiven = ".", true
+	}
+
+	// When stdout carries a tar stream or JSON, keep status messages off it
+	status := cmd.OutOrStdout()
+	var stream io.Writer
+	if outputDir == "-" {
+		if jsonOutput {
+			return usageErrorf("--json cannot be used with --output -")
+		}
+		status = cmd.ErrOrStderr()
+		stream = cmd.OutOrStdout()
+	}
+	if jsonOutput {
+		status = cmd.ErrOrStderr()
+	}
+	userConfig, userConfigPath, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	if quiet || (userConfig.Quiet && !cmd.Flags().Changed("quiet")) {
+		status = io.Discard
+	}
 
-	fmt.Println("※ The ason shakes, preparing transformation...")
+	fmt.Fprintln(status, "※ The ason shakes, preparing transformation...")
+
+	// Check before anything else is asked, so declining costs nothing
+	noPrompt := promptingDisabled(cmd)
+	if into && !force && !dryRun {
+		if err := confirmInto(cmd, outputDir, noPrompt); err != nil {
+			return err
+		}
+	}
 
 	// Get template path
-	reg, err := registry.NewRegistry()
+	reg, err := openRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to initialize registry: %w", err)
 	}
 
-	templatePath, err := reg.Get(templateName)
-	if err != nil {
-		// Try as direct path
-		if info, err := os.Stat(templateName); err == nil && info.IsDir() {
-			templatePath = templateName
-		} else {
-			return fmt.Errorf("template not found: %s", templateName)
+	// Registered templates win over a directory or archive of the same name,
+	// but those win over a registered name that only matches ignoring case.
+	// Saved defaults are kept by registered name, or by path otherwise.
+	var templatePath, templateEngine, defaultsKey string
+	var includeHidden bool
+	entry, err := reg.Entry(templateName)
+	info, statErr := os.Stat(templateName)
+	isDir := statErr == nil && info.IsDir()
+	isArchive := statErr == nil && info.Mode().IsRegular() && fsutil.IsArchive(templateName)
+	remote, isRemote := remoteSource(templateName)
+	switch {
+	case err == nil && (entry.Name == templateName || !isDir && !isArchive && !isRemote):
+		if entry.Name != templateName {
+			fmt.Fprintf(status, "⚠️  No template named '%s'; using '%s'\n", templateName, entry.Name)
+		}
+		templatePath = entry.Path
+		templateEngine = entry.Engine
+		defaultsKey = entry.Name
+		includeHidden = entry.IncludeHidden
+	case isDir:
+		templatePath = templateName
+		defaultsKey, err = filepath.Abs(templateName)
+		if err != nil {
+			return fmt.Errorf("failed to resolve template path: %w", err)
+		}
+	case isArchive:
+		defaultsKey, err = filepath.Abs(templateName)
+		if err != nil {
+			return fmt.Errorf("failed to resolve template path: %w", err)
+		}
+		extractDir, err := os.MkdirTemp("", "ason-template-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(extractDir)
+		fmt.Fprintf(status, "📦 Extracting template from %s\n", templateName)
+		if err := fsutil.Extract(templateName, extractDir); err != nil {
+			return err
 		}
+		templatePath = archiveTemplateDir(extractDir)
+	case isRemote:
+		defaultsKey = remote.URL
+		var cleanup func()
+		templatePath, cleanup, err = fetchRemote(cmd, status, remote, userConfig.RemoteCacheTTL(), refresh)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	case errors.Is(err, registry.ErrAmbiguousTemplate):
+		return err
+	default:
+		return templateNotFoundError(reg, templateName)
+	}
+
+	// Load the template config: the one named by --config, or the
+	// template's own if it has one
+	var config *template.Config
+	if configFile != "" {
+		config, err = template.LoadConfig(resolveConfigFile(configFile, templatePath))
+		if err != nil {
+			return fmt.Errorf("failed to load config %s: %w", configFile, err)
+		}
+		// The explicit config's engine beats the registered one
+		if config.Engine != "" {
+			templateEngine = config.Engine
+		}
+	} else {
+		config, err = template.Load(templatePath)
+		if err != nil && !errors.Is(err, template.ErrNoConfig) {
+			return fmt.Errorf("failed to load template config: %w", err)
+		}
+	}
+
+	if config != nil {
+		if err := checkAsonVersion(status, config.MinAsonVersion); err != nil {
+			return err
+		}
+	}
+
+	// Templates registered with --include-hidden generate all their hidden
+	// files, whatever their config keeps
+	if includeHidden {
+		config = config.WithIncludeHidden()
 	}
 
-	// Create a simple template object
 	tmpl := &generator.Template{
-		Path: templatePath,
+		Path:   templatePath,
+		Config: config,
+	}
+
+	// Resolve the templates it extends and includes
+	if err := resolveComposition(reg, tmpl, []string{templateName}, 0); err != nil {
+		return err
+	}
+
+	// Registered templates carry their engine in the registry metadata;
+	// fall back to the template's own config for direct paths.
+	if templateEngine == "" && config != nil {
+		templateEngine = config.Engine
+	}
+	if templateEngine == "" {
+		templateEngine = userConfig.DefaultEngine
+	}
+
+	engineOpts := engine.Options{StrictUndefined: strictVars, Root: templatePath}
+	if config != nil {
+		engineOpts.TrimBlocks = config.Rendering.TrimBlocks
+		engineOpts.LStripBlocks = config.Rendering.LStripBlocks
+		engineOpts.Autoescape = config.Rendering.Autoescape
+	}
+	for _, dir := range config.PartialsDirs() {
+		engineOpts.IncludeDirs = append(engineOpts.IncludeDirs, filepath.Join(templatePath, dir))
+	}
+
+	eng, err := engine.New(resolveEngine(engineName, templateEngine), engineOpts)
+	if err != nil {
+		return err
+	}
+
+	// Fake-data helpers are time-seeded unless a seed is given
+	if cmd.Flags().Changed("seed") {
+		engine.Seed(seed)
 	}
 
 	// Create generator
-	gen := generator.New(tmpl, engine.NewPongo2Engine())
+	gen := generator.New(tmpl, eng)
 
-	// Load variables from file if specified
+	// Load variables from a discovered ason.vars file, then from the
+	// file given with --var-file, which wins
 	var fileVars map[string]string
+	if !noAutoVars {
+		fileVars, err = loadAutoVars(status, outputDir)
+		if err != nil {
+			return err
+		}
+	}
 	if varFile != "" {
-		var err error
-		fileVars, err = varfile.Load(varFile)
+		explicitVars, err := varfile.Load(varFile)
 		if err != nil {
 			return fmt.Errorf("failed to load variables from file: %w", err)
 		}
+		fileVars = varfile.Merge(fileVars, explicitVars)
+	}
+
+	var variables []template.Variable
+	if config != nil {
+		variables = withAuthorDefault(config.Variables, userConfig.Author)
+		variables = withSavedDefaults(variables, userConfig.TemplateDefaults(defaultsKey))
+	}
+
+	// Ask for the variables not given on the command line or in a file,
+	// unless prompting is off or there is no terminal to prompt on. A dry
+	// run shows where each value would come from instead of asking.
+	cliVars := extraVars
+	if dryRun {
+		printVariablePlan(status, variableSources(variables, fileVars, extraVars, noPrompt == ""))
+	} else if noPrompt == "" {
+		answers, err := promptVariables(cmd, variables, varfile.Merge(fileVars, extraVars))
+		if err != nil {
+			return err
+		}
+		cliVars = varfile.Merge(answers, extraVars)
+	}
+
+	// Check every value before converting any, so all problems show at once
+	if validateVarsSchema {
+		if err := checkVariables(variables, mergeContext(variables, fileVars, cliVars, varLists)); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the template variables
+	vars, err := buildContext(variables, fileVars, cliVars, varLists)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		if err := addComputed(eng, config.Computed, vars); err != nil {
+			return err
+		}
+	}
+
+	if dumpContext {
+		if err := printContextJSON(cmd.ErrOrStderr(), redactedContext(variables, vars)); err != nil {
+			return err
+		}
+	}
+
+	// The real run would prompt for required variables a dry run leaves unset
+	if missing := missingRequired(variables, vars); len(missing) > 0 && !(dryRun && noPrompt == "") {
+		return missingVariablesError(missing, noPrompt)
+	}
+
+	if !outputGiven && config != nil && config.OutputName != "" {
+		name, err := renderOutputName(eng, config.OutputName, vars)
+		if err != nil {
+			return err
+		}
+		outputDir = name
+		fmt.Fprintf(status, "📁 Generating into %s (the template's output_name)\n", outputDir)
+	}
+
+	opts := generatorOptions(stream, status)
+	opts.Ask = askOverwrite(cmd, noPrompt)
+	// --into keeps the files already there unless told otherwise
+	if into && !cmd.Flags().Changed("overwrite-policy") {
+		opts.Overwrite = generator.OverwriteSkip
+		if force {
+			opts.Overwrite = generator.OverwriteReplace
+		}
+	}
+	opts.EngineOptions = engineOpts
+	// Seeded helpers only repeat their values when files render in order
+	if cmd.Flags().Changed("seed") {
+		opts.Jobs = 1
+	}
+	if err := generate(cmd, gen, vars, opts); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if saveDefaults {
+		userConfig.SaveTemplateDefaults(defaultsKey, savableValues(variables, vars))
+		if err := userConfig.Save(userConfigPath); err != nil {
+			return fmt.Errorf("failed to save defaults: %w", err)
+		}
+		fmt.Fprintf(status, "💾 Saved the variable values as defaults for %s\n", defaultsKey)
+	}
+
+	var steps []string
+	if config != nil {
+		steps = config.NextSteps
+	}
+	nextSteps := renderNextSteps(eng, steps, publicContext(variables, vars))
+
+	if jsonOutput {
+		return printSummaryJSON(cmd.OutOrStdout(), outputDir, gen.Summary(), nextSteps)
+	}
+
+	fmt.Fprintln(status, "※ The rhythm is complete! Project manifested successfully!")
+	printSummary(status, gen.Summary(), nextSteps)
+
+	return nil
+}
+
+// withAuthorDefault returns variables with author as the default of an
+// author variable that declares none
+func withAuthorDefault(variables []template.Variable, author string) []template.Variable {
+	if author == "" {
+		return variables
+	}
+
+	withDefault := slices.Clone(variables)
+	for i, v := range withDefault {
+		if v.Name == "author" && v.Default == nil {
+			withDefault[i].Default = author
+		}
+	}
+	return withDefault
+}
+
+// withSavedDefaults returns variables with the values saved by
+// --save-defaults as their defaults
+func withSavedDefaults(variables []template.Variable, saved map[string]string) []template.Variable {
+	if len(saved) == 0 {
+		return variables
+	}
+
+	withDefaults := slices.Clone(variables)
+	for i, v := range withDefaults {
+		if value, ok := saved[v.Name]; ok {
+			withDefaults[i].Default = value
+		}
+	}
+	return withDefaults
+}
+
+// savableValues returns the values of the declared variables as text for
+// --save-defaults. Secrets are never saved.
+func savableValues(variables []template.Variable, context map[string]interface{}) map[string]string {
+	values := make(map[string]string)
+	for _, v := range variables {
+		value, ok := context[v.Name]
+		if !ok || value == nil || v.IsSecret() {
+			continue
+		}
+		if list, ok := value.([]string); ok {
+			values[v.Name] = strings.Join(list, ",")
+			continue
+		}
+		values[v.Name] = fmt.Sprintf("%v", value)
+	}
+	return values
+}
+
+// generate runs the generator into outputDir until it finishes, --timeout
+// passes or the user presses Ctrl-C. The generator removes the partial
+// output of a run that stops early, unless --no-cleanup is set.
+func generate(cmd *cobra.Command, gen *generator.Generator, vars map[string]interface{}, opts generator.Options) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stopProfile func() error
+	if pprofFile != "" {
+		var err error
+		if stopProfile, err = startCPUProfile(pprofFile); err != nil {
+			return err
+		}
+	}
+
+	err := gen.Generate(ctx, outputDir, vars, opts)
+	if stopProfile != nil {
+		if profileErr := stopProfile(); profileErr != nil && err == nil {
+			return profileErr
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("generation timed out after %s: %w", timeout, err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("generation was interrupted: %w", err)
+	}
+	return err
+}
+
+// startCPUProfile starts writing a CPU profile to path for --pprof and
+// returns the function that finishes it
+func startCPUProfile(path string) (func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start profiling: %w", err)
+	}
+
+	return func() error {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write profile: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// renderOutputName renders a template's output_name into the name of a
+// directory inside the current one
+func renderOutputName(eng engine.Engine, expr string, context map[string]interface{}) (string, error) {
+	out, err := eng.Render(expr, context)
+	if err != nil {
+		return "", fmt.Errorf("failed to render output_name: %w", err)
+	}
+
+	name := filepath.Clean(strings.TrimSpace(out))
+	if name == "." || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("output_name %q rendered to %q, which is not a directory inside the current one; give an output directory instead", expr, out)
+	}
+	return name, nil
+}
+
+// addComputed renders the template's computed variables into context, in
+// declaration order, so each sees the variables and the computed values
+// before it
+func addComputed(eng engine.Engine, computed []template.Computed, context map[string]interface{}) error {
+	for _, c := range computed {
+		value, err := eng.Render(c.Value, context)
+		if err != nil {
+			return fmt.Errorf("failed to render computed variable %s: %w", c.Name, err)
+		}
+		context[c.Name] = value
+	}
+	return nil
+}
+
+// loadAutoVars loads the first ason.vars file found in the current
+// directory or the output directory, if any
+func loadAutoVars(status io.Writer, outputDir string) (map[string]string, error) {
+	dirs := []string{"."}
+	if outputDir != "-" && filepath.Clean(outputDir) != "." {
+		dirs = append(dirs, outputDir)
+	}
+
+	path, err := varfile.Find(dirs...)
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	vars, err := varfile.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variables from %s: %w", path, err)
+	}
+	fmt.Fprintf(status, "📄 Using variables from %s (disable with --no-auto-vars)\n", path)
+	return vars, nil
+}
+
+// generatorOptions builds the generation options from the new command's
+// flags, streaming to stream when set and logging progress to log
+func generatorOptions(stream, log io.Writer) generator.Options {
+	return generator.Options{
+		SkipHooks:      skipHooks,
+		DryRun:         dryRun,
+		Verbose:        verbose,
+		Include:        includes,
+		Exclude:        excludes,
+		Stream:         stream,
+		Log:            log,
+		Overwrite:      generator.OverwritePolicy(overwrite),
+		NoCleanup:      noCleanup,
+		PruneEmptyDirs: pruneEmpty,
+		Jobs:           jobs,
+		// Leave identical files alone so regenerating doesn't touch mtimes
+		SkipUnchanged: true,
+	}
+}
+
+// confirmInto asks before generating into dir with --into when it already
+// holds files. It fails when prompting is disabled; noPrompt says why.
+func confirmInto(cmd *cobra.Command, dir, noPrompt string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the current directory: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	if noPrompt != "" {
+		return fmt.Errorf("%s is not empty; pass --force to generate into it anyway (not prompting: %s)", abs, noPrompt)
+	}
+
+	question := fmt.Sprintf("%s is not empty (%d entries). Generate into it?", abs, len(entries))
+	final, err := runPrompt(cmd, prompt.NewConfirmPrompt(question, false))
+	if err != nil {
+		return fmt.Errorf("failed to ask about %s: %w", abs, err)
+	}
+	answer, ok := final.(prompt.ConfirmPrompt)
+	if !ok || !answer.Done() || !answer.Answer {
+		return fmt.Errorf("%w: not generating into %s", prompt.ErrCancelled, abs)
+	}
+	return nil
+}
+
+// askOverwrite returns the callback --overwrite-policy ask uses to confirm
+// overwriting each existing file. It fails when prompting is disabled;
+// noPrompt says why.
+func askOverwrite(cmd *cobra.Command, noPrompt string) func(path string) (bool, error) {
+	return func(path string) (bool, error) {
+		if noPrompt != "" {
+			return false, fmt.Errorf("%s already exists and --overwrite-policy ask cannot prompt (%s)", path, noPrompt)
+		}
+
+		final, err := runPrompt(cmd, prompt.NewConfirmPrompt(fmt.Sprintf("Overwrite %s?", path), false))
+		if err != nil {
+			return false, fmt.Errorf("failed to ask about %s: %w", path, err)
+		}
+		answer, ok := final.(prompt.ConfirmPrompt)
+		if !ok || !answer.Done() {
+			return false, fmt.Errorf("%w while asking to overwrite %s", prompt.ErrCancelled, path)
+		}
+		return answer.Answer, nil
+	}
+}
+
+// checkAsonVersion refuses templates that need a newer ason than this one.
+// Development builds carry no comparable version, so they only warn.
+func checkAsonVersion(status io.Writer, minVersion string) error {
+	if minVersion == "" {
+		return nil
+	}
+
+	if _, err := asonversion.Parse(minVersion); err != nil {
+		return fmt.Errorf("template has an invalid min_ason_version: %w", err)
+	}
+
+	ok, err := asonversion.AtLeast(version, minVersion)
+	if err != nil {
+		fmt.Fprintf(status, "⚠️  Template requires ason >= %s; can't check development build %q\n", minVersion, version)
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("template requires ason >= %s, but this is ason %s; upgrade ason to use it", minVersion, version)
+	}
+	return nil
+}
+
+// resolveConfigFile locates a --config file: as given when it exists,
+// otherwise relative to the template directory, where config profiles
+// usually live
+func resolveConfigFile(path, templatePath string) string {
+	if _, err := os.Stat(path); err == nil || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(templatePath, path)
+}
+
+// buildContext computes the generation variables from the declared defaults,
+// the variable file and --var values, later sources taking precedence. Values
+// of declared variables are converted to their declared type; list variables
+// given with repeated --var flags collect every value.
+func buildContext(variables []template.Variable, fileVars, cliVars map[string]string, cliLists map[string][]string) (map[string]interface{}, error) {
+	context := mergeContext(variables, fileVars, cliVars, cliLists)
+	for _, v := range variables {
+		raw, ok := context[v.Name].(string)
+		if !ok || raw == "" {
+			continue
+		}
+		value, err := template.CoerceValue(v, raw)
+		if err != nil {
+			return nil, err
+		}
+		context[v.Name] = value
 	}
 
-	// Merge variables (CLI vars override file vars)
-	mergedVars := varfile.Merge(fileVars, extraVars)
+	return context, nil
+}
+
+// Where a variable's value comes from, as shown by a dry run
+const (
+	sourceVar     = "from --var"
+	sourceFile    = "from file"
+	sourcePrompt  = "will prompt"
+	sourceDefault = "from default"
+	sourceUnset   = "not set"
+)
+
+// variableSource pairs a declared variable with where its value comes from
+type variableSource struct {
+	Name   string
+	Source string
+}
+
+// variableSources resolves where each declared variable gets its value,
+// with the precedence generation uses: --var, then variable files, then a
+// prompt when one can be shown, then the declared default
+func variableSources(variables []template.Variable, fileVars, cliVars map[string]string, canPrompt bool) []variableSource {
+	sources := make([]variableSource, 0, len(variables))
+	for _, v := range variables {
+		source := sourceUnset
+		if _, ok := cliVars[v.Name]; ok {
+			source = sourceVar
+		} else if _, ok := fileVars[v.Name]; ok {
+			source = sourceFile
+		} else if canPrompt {
+			source = sourcePrompt
+		} else if v.Default != nil {
+			source = sourceDefault
+		}
+		sources = append(sources, variableSource{Name: v.Name, Source: source})
+	}
+	return sources
+}
 
-	// Generate with context
+// printVariablePlan lists each declared variable with where its value
+// comes from
+func printVariablePlan(w io.Writer, sources []variableSource) {
+	if len(sources) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "📋 Variables:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range sources {
+		fmt.Fprintf(tw, "   %s\t%s\n", s.Name, s.Source)
+	}
+	tw.Flush()
+}
+
+// mergeContext layers the declared defaults, the variable file and --var
+// values, later sources taking precedence, without converting any values.
+// List variables given with repeated --var flags are joined with commas.
+func mergeContext(variables []template.Variable, fileVars, cliVars map[string]string, cliLists map[string][]string) map[string]interface{} {
 	context := make(map[string]interface{})
-	for k, v := range mergedVars {
+	for _, v := range variables {
+		if v.Default != nil {
+			context[v.Name] = v.Default
+		}
+	}
+
+	// CLI vars override file vars
+	for k, v := range varfile.Merge(fileVars, cliVars) {
 		context[k] = v
 	}
 
-	if err := gen.Generate(outputDir, context, generator.Options{
-		DryRun: dryRun,
-	}); err != nil {
-		return err
+	for _, v := range variables {
+		if v.Type == "list" && len(cliLists[v.Name]) > 1 {
+			context[v.Name] = strings.Join(cliLists[v.Name], ",")
+		}
+	}
+	return context
+}
+
+// checkVariables checks the resolved variable values against the
+// template's declarations, reporting every violation at once
+func checkVariables(variables []template.Variable, context map[string]interface{}) error {
+	errs := template.CheckValues(variables, context)
+	if len(errs) == 0 {
+		return nil
 	}
 
-	if !dryRun {
-		fmt.Println("※ The rhythm is complete! Project manifested successfully!")
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = "  - " + err.Error()
+	}
+	return fmt.Errorf("variables don't match the template's declarations:\n%s", strings.Join(lines, "\n"))
+}
+
+// inputIsTerminal reports whether r is an interactive terminal
+var inputIsTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(f.Fd())
+}
+
+// promptingDisabled returns why variables can't be prompted for, or an
+// empty string when they can. Prompting needs a terminal on the command's
+// input and is skipped in CI, where nobody is there to answer.
+func promptingDisabled(cmd *cobra.Command) string {
+	switch {
+	case noInput:
+		return "--no-input is set"
+	case acceptDefaults:
+		return "--accept-defaults is set"
+	case isCI():
+		return "running in CI"
+	case !inputIsTerminal(cmd.InOrStdin()):
+		return "standard input is not a terminal"
+	}
+	return ""
+}
+
+// isCI reports whether the CI environment variable marks a CI run, as set
+// by GitHub Actions, GitLab CI and most other CI services
+func isCI() bool {
+	ci := os.Getenv("CI")
+	return ci != "" && ci != "0" && !strings.EqualFold(ci, "false")
+}
+
+// missingVariablesError describes the required variables left without a
+// value and how to set them. reason says why they weren't prompted for.
+func missingVariablesError(missing []string, reason string) error {
+	flags := make([]string, len(missing))
+	for i, name := range missing {
+		flags[i] = "--var " + name + "=<value>"
+	}
+
+	msg := fmt.Sprintf("missing required variables: %s; set them with %s", strings.Join(missing, ", "), strings.Join(flags, " "))
+	if reason != "" {
+		msg += fmt.Sprintf(" (not prompting: %s)", reason)
+	}
+	return errors.New(msg)
+}
+
+// runPrompt runs a prompt model to completion and returns its final state.
+// An interrupt signal leaves the prompt unanswered, like Ctrl-C.
+var runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+	final, err := tea.NewProgram(model, tea.WithInput(cmd.InOrStdin()), tea.WithOutput(cmd.ErrOrStderr())).Run()
+	if errors.Is(err, tea.ErrInterrupted) {
+		return model, nil
+	}
+	return final, err
+}
+
+// promptVariables asks for each declared variable without a value in
+// supplied, offering its default, and returns the answers. A single variable
+// gets a plain prompt; several are asked together as a form.
+func promptVariables(cmd *cobra.Command, variables []template.Variable, supplied map[string]string) (map[string]string, error) {
+	// Variables are asked for in declaration order unless --prompt-order
+	// says otherwise
+	var pending []template.Variable
+	for _, v := range variables {
+		if _, ok := supplied[v.Name]; !ok {
+			pending = append(pending, v)
+		}
+	}
+	if promptOrder == promptOrderAlpha {
+		sort.SliceStable(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	}
+
+	switch len(pending) {
+	case 0:
+		return nil, nil
+	case 1:
+		// Answers that don't fit the variable are refused and asked for
+		// again, keeping the refused value to edit
+		v := pending[0]
+		var refused, problem string
+		for {
+			text := prompt.NewVariablePrompt(v)
+			text.Value, text.Error = refused, problem
+			var model tea.Model = text
+			if v.IsSecret() {
+				secret := prompt.NewSecretPrompt(v)
+				secret.Value, secret.Error = refused, problem
+				model = secret
+			}
+
+			final, err := runPrompt(cmd, model)
+			if err != nil {
+				return nil, fmt.Errorf("failed to prompt for %s: %w", v.Name, err)
+			}
+
+			var answer prompt.TextPrompt
+			switch p := final.(type) {
+			case prompt.TextPrompt:
+				answer = p
+			case prompt.PasswordPrompt:
+				answer = p.TextPrompt
+			}
+			if !answer.Done() {
+				return nil, fmt.Errorf("%w while prompting for %s", prompt.ErrCancelled, v.Name)
+			}
+
+			if err := prompt.CheckAnswer(v, answer.Value); err != nil {
+				refused, problem = answer.Value, err.Error()
+				continue
+			}
+			return map[string]string{v.Name: answer.Value}, nil
+		}
+	default:
+		final, err := runPrompt(cmd, prompt.NewFormPrompt(pending))
+		if err != nil {
+			return nil, fmt.Errorf("failed to prompt for variables: %w", err)
+		}
+		form, ok := final.(prompt.FormPrompt)
+		if !ok || !form.Done() {
+			return nil, fmt.Errorf("%w while prompting for variables", prompt.ErrCancelled)
+		}
+		return form.Values(), nil
+	}
+}
+
+// missingRequired lists the required variables that have no value, or an
+// empty one, in context
+func missingRequired(variables []template.Variable, context map[string]interface{}) []string {
+	var missing []string
+	for _, v := range variables {
+		if !v.Required {
+			continue
+		}
+		if value, ok := context[v.Name]; !ok || value == nil || value == "" {
+			missing = append(missing, v.Name)
+		}
+	}
+	return missing
+}
+
+// publicContext returns context without the values of secret variables,
+// for anything ason prints
+func publicContext(variables []template.Variable, context map[string]interface{}) map[string]interface{} {
+	public := make(map[string]interface{}, len(context))
+	for k, v := range context {
+		public[k] = v
+	}
+	for _, v := range variables {
+		if v.IsSecret() {
+			delete(public, v.Name)
+		}
+	}
+	return public
+}
+
+// redactedContext returns context with the values of secret variables
+// replaced, so dumps show which secrets are set without revealing them
+func redactedContext(variables []template.Variable, context map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(context))
+	for k, v := range context {
+		redacted[k] = v
+	}
+	for _, v := range variables {
+		if _, ok := redacted[v.Name]; ok && v.IsSecret() {
+			redacted[v.Name] = "[redacted]"
+		}
+	}
+	return redacted
+}
+
+// printContextJSON writes the template variables as an indented JSON object
+func printContextJSON(w io.Writer, context map[string]interface{}) error {
+	data, err := json.MarshalIndent(context, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode context: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// renderNextSteps renders the template's next-step hints with the generation
+// variables; a hint that fails to render is shown as written
+func renderNextSteps(eng engine.Engine, steps []string, context map[string]interface{}) []string {
+	rendered := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if out, err := eng.Render(step, context); err == nil {
+			step = out
+		}
+		rendered = append(rendered, step)
+	}
+	return rendered
+}
+
+// printSummary prints the generation counts followed by any next steps
+func printSummary(w io.Writer, summary generator.Summary, nextSteps []string) {
+	fmt.Fprintf(w, "   Files:        %d\n", summary.Files)
+	if summary.Unchanged > 0 {
+		fmt.Fprintf(w, "   Unchanged:    %d\n", summary.Unchanged)
+	}
+	fmt.Fprintf(w, "   Directories:  %d\n", summary.Directories)
+	fmt.Fprintf(w, "   Size:         %s\n", formatSize(summary.Bytes))
+	fmt.Fprintf(w, "   Duration:     %s\n", summary.Duration.Round(time.Millisecond))
+
+	if len(nextSteps) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "💡 Next steps:")
+		for i, step := range nextSteps {
+			fmt.Fprintf(w, "   %d. %s\n", i+1, step)
+		}
+	}
+}
+
+// printSummaryJSON prints the generation summary as a JSON object
+func printSummaryJSON(w io.Writer, output string, summary generator.Summary, nextSteps []string) error {
+	data, err := json.MarshalIndent(struct {
+		Output      string   `json:"output"`
+		Files       int      `json:"files"`
+		Unchanged   int      `json:"unchanged"`
+		Directories int      `json:"directories"`
+		Bytes       int64    `json:"bytes"`
+		DurationMS  int64    `json:"duration_ms"`
+		NextSteps   []string `json:"next_steps"`
+	}{
+		Output:      output,
+		Files:       summary.Files,
+		Unchanged:   summary.Unchanged,
+		Directories: summary.Directories,
+		Bytes:       summary.Bytes,
+		DurationMS:  summary.Duration.Milliseconds(),
+		NextSteps:   nextSteps,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// maxIncludeDepth bounds how deeply templates may include other templates
+const maxIncludeDepth = 8
+
+// resolveComposition fills in the registered templates tmpl extends and
+// includes, as declared in its config, recursively. chain lists the templates
+// extending tmpl, to detect cycles, and depth counts the includes leading to
+// it. tmpl's variables are merged with those it inherits.
+func resolveComposition(reg *registry.Registry, tmpl *generator.Template, chain []string, depth int) error {
+	config := tmpl.Config
+	if config == nil {
+		return nil
+	}
+
+	if name := config.Extends; name != "" {
+		for _, seen := range chain {
+			if seen == name {
+				return fmt.Errorf("template inheritance cycle: %s", strings.Join(append(chain, name), " → "))
+			}
+		}
+
+		base, err := loadRegistered(reg, name)
+		if err != nil {
+			return fmt.Errorf("base template %q: %w", name, err)
+		}
+		if err := resolveComposition(reg, base, append(chain, name), depth); err != nil {
+			return err
+		}
+
+		tmpl.Base = base
+		if base.Config != nil {
+			config.Variables = template.MergeVariables(base.Config.Variables, config.Variables)
+			config.Computed = template.MergeComputed(base.Config.Computed, config.Computed)
+		}
+	}
+
+	for _, inc := range config.Includes {
+		if inc.Template == "" || inc.Path == "" {
+			return fmt.Errorf("include entries need both template and path")
+		}
+		if clean := filepath.Clean(inc.Path); filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("include path %q must stay inside the output directory", inc.Path)
+		}
+		if depth >= maxIncludeDepth {
+			return fmt.Errorf("templates include each other more than %d levels deep at %q", maxIncludeDepth, inc.Template)
+		}
+
+		included, err := loadRegistered(reg, inc.Template)
+		if err != nil {
+			return fmt.Errorf("included template %q: %w", inc.Template, err)
+		}
+		if err := resolveComposition(reg, included, []string{inc.Template}, depth+1); err != nil {
+			return err
+		}
+
+		tmpl.Includes = append(tmpl.Includes, generator.Inclusion{
+			Template: included,
+			Path:     inc.Path,
+			Vars:     inc.Vars,
+		})
 	}
 
 	return nil
 }
+
+// archiveTemplateDir returns the template root of an extracted archive:
+// the single directory archives of a project usually wrap their files in,
+// unless its name is templated, or the extraction directory itself
+func archiveTemplateDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() && !strings.ContainsAny(entries[0].Name(), "{}") {
+		return filepath.Join(dir, entries[0].Name())
+	}
+	return dir
+}
+
+// loadRegistered loads a registered template and its config
+func loadRegistered(reg *registry.Registry, name string) (*generator.Template, error) {
+	entry, err := reg.Entry(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := template.Load(entry.Path)
+	if err != nil && !errors.Is(err, template.ErrNoConfig) {
+		return nil, fmt.Errorf("failed to load template config: %w", err)
+	}
+	if entry.IncludeHidden {
+		config = config.WithIncludeHidden()
+	}
+
+	return &generator.Template{
+		Path:   entry.Path,
+		Config: config,
+	}, nil
+}
+
+// varsValue is the --var flag. Like a string-to-string flag, the last value
+// given for a key wins, but every value is also kept in lists so that
+// repeating a key builds up a list variable.
+type varsValue struct {
+	values *map[string]string
+	lists  *map[string][]string
+}
+
+func (v *varsValue) Set(val string) error {
+	key, value, ok := strings.Cut(val, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%s must be formatted as key=value", val)
+	}
+
+	if *v.values == nil {
+		*v.values = make(map[string]string)
+	}
+	if *v.lists == nil {
+		*v.lists = make(map[string][]string)
+	}
+	(*v.values)[key] = value
+	(*v.lists)[key] = append((*v.lists)[key], value)
+	return nil
+}
+
+func (v *varsValue) Type() string {
+	return "key=value"
+}
+
+func (v *varsValue) String() string {
+	pairs := make([]string, 0, len(*v.values))
+	for key, value := range *v.values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// resolveEngine picks the engine name to use: the --engine flag wins over
+// the template's declared engine, and an empty result selects the default.
+func resolveEngine(flagEngine, templateEngine string) string {
+	if flagEngine != "" {
+		return flagEngine
+	}
+	return templateEngine
+}
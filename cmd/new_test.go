@@ -1,11 +1,24 @@
 package cmd
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/prompt"
+	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/madstone-tech/ason/internal/template"
+	"github.com/spf13/cobra"
 )
 
 func TestNewCmd(t *testing.T) {
@@ -324,3 +337,2526 @@ func TestNewCmdWithExtraVars(t *testing.T) {
 	// Reset
 	newCmd.SetOut(nil)
 }
+
+func TestNewCmdUsesRegisteredEngine(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+
+	tmpHome := t.TempDir()
+	os.Setenv("HOME", tmpHome)
+
+	// Create a template authored for Go templates
+	templateDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(`engine = "go"`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ .name }}"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	reg, err := registry.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry() failed: %v", err)
+	}
+	if err := reg.Add("go-template", templateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	// Remove the on-disk config so the engine can only come from the registry entry
+	entry, err := reg.Entry("go-template")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if entry.Engine != "go" {
+		t.Fatalf("Entry().Engine = %v, want go", entry.Engine)
+	}
+	if err := os.Remove(filepath.Join(entry.Path, "ason.toml")); err != nil {
+		t.Fatalf("Failed to remove registered ason.toml: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "gopher"}
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{"go-template", outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(got) != "# gopher" {
+		t.Errorf("Generated README.md = %q, want %q", got, "# gopher")
+	}
+}
+
+func TestNewCmdEngineFlagOverridesTemplate(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	// Template declares the Go engine but uses Pongo2 syntax
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(`engine = "go"`), 0644); err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ name|upper }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "rattle"}
+
+	engineName = "pongo2"
+	defer func() { engineName = "" }()
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(got) != "# RATTLE" {
+		t.Errorf("Generated README.md = %q, want %q", got, "# RATTLE")
+	}
+
+	// Unknown engines are rejected
+	engineName = "jinja"
+	if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err == nil {
+		t.Error("Expected error for unknown engine, got nil")
+	}
+}
+
+func TestNewCmdOutputTarStream(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "streamed"}
+
+	originalOutputDir := outputDir
+	defer func() { outputDir = originalOutputDir }()
+	outputDir = "-"
+
+	var stdout, stderr bytes.Buffer
+	newCmd.SetOut(&stdout)
+	newCmd.SetErr(&stderr)
+	defer func() {
+		newCmd.SetOut(nil)
+		newCmd.SetErr(nil)
+	}()
+
+	if err := newCmd.RunE(newCmd, []string{templateDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	tr := tar.NewReader(&stdout)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Failed to read tar stream: %v", err)
+	}
+	if hdr.Name != "README.md" {
+		t.Errorf("tar entry name = %q, want %q", hdr.Name, "README.md")
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("Failed to read tar entry: %v", err)
+	}
+	if string(content) != "# streamed" {
+		t.Errorf("tar entry content = %q, want %q", content, "# streamed")
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Errorf("Expected end of tar stream, got %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "The ason shakes") {
+		t.Errorf("Status messages should go to stderr, got %q", stderr.String())
+	}
+}
+
+func TestNewCmdIncludeFlag(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(templateDir, "docs"), 0755); err != nil {
+		t.Fatalf("Failed to create docs dir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(templateDir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create src dir: %v", err)
+	}
+	for _, name := range []string{"README.md", "docs/guide.md", "src/main.go", "Makefile"} {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	includes = []string{"*.md"}
+	defer func() { includes = nil }()
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	var generated []string
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != outputDir {
+			rel, _ := filepath.Rel(outputDir, path)
+			generated = append(generated, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk output: %v", err)
+	}
+
+	want := []string{"README.md", "docs", "docs/guide.md"}
+	if strings.Join(generated, ",") != strings.Join(want, ",") {
+		t.Errorf("Generated paths = %v, want %v", generated, want)
+	}
+}
+
+func TestNewCmdSummary(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `next_steps = ["cd {{ name }}", "run ` + "`go mod tidy`" + `"]`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "summary-demo"}
+
+	var stdout, stderr bytes.Buffer
+	newCmd.SetOut(&stdout)
+	newCmd.SetErr(&stderr)
+	defer func() {
+		newCmd.SetOut(nil)
+		newCmd.SetErr(nil)
+		quiet = false
+		jsonOutput = false
+	}()
+
+	t.Run("text", func(t *testing.T) {
+		stdout.Reset()
+		if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+
+		out := stdout.String()
+		for _, want := range []string{"Files:        2", "Next steps:", "1. cd summary-demo", "2. run `go mod tidy`"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Output should contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("regenerated", func(t *testing.T) {
+		outputDir := t.TempDir()
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+
+		stdout.Reset()
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("second newCmd execution failed: %v", err)
+		}
+		out := stdout.String()
+		for _, want := range []string{"Unchanged: ason.toml", "Files:        0", "Unchanged:    2"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Output should contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("quiet", func(t *testing.T) {
+		stdout.Reset()
+		quiet = true
+		defer func() { quiet = false }()
+
+		if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("--quiet should print nothing, got %q", stdout.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		stdout.Reset()
+		jsonOutput = true
+		defer func() { jsonOutput = false }()
+
+		if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+
+		var summary struct {
+			Files     int      `json:"files"`
+			Bytes     int64    `json:"bytes"`
+			NextSteps []string `json:"next_steps"`
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &summary); err != nil {
+			t.Fatalf("--json output is not valid JSON: %v\n%s", err, stdout.String())
+		}
+		if summary.Files != 2 {
+			t.Errorf("files = %d, want 2", summary.Files)
+		}
+		if len(summary.NextSteps) != 2 || summary.NextSteps[0] != "cd summary-demo" {
+			t.Errorf("next_steps = %v, want rendered steps", summary.NextSteps)
+		}
+	})
+
+	t.Run("json with a tar stream", func(t *testing.T) {
+		jsonOutput = true
+		defer func() { jsonOutput, outputDir = false, "." }()
+
+		err := newCmd.RunE(newCmd, []string{templateDir, "-"})
+		if ExitCode(err) != ExitUsage {
+			t.Errorf("--json with --output - error = %v, want a usage error", err)
+		}
+	})
+}
+
+func TestNewCmdSeed(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	content := `id: {{ ""|uuid }} token: {{ 10|random_string }}`
+	if err := os.WriteFile(filepath.Join(templateDir, "data.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	defer func() {
+		seed = 0
+		newCmd.Flags().Lookup("seed").Changed = false
+	}()
+
+	generate := func(value string) string {
+		t.Helper()
+		if err := newCmd.Flags().Set("seed", value); err != nil {
+			t.Fatalf("Failed to set --seed: %v", err)
+		}
+		out := t.TempDir()
+		if err := newCmd.RunE(newCmd, []string{templateDir, out}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(out, "data.txt"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		return string(data)
+	}
+
+	first := generate("1234")
+	if second := generate("1234"); second != first {
+		t.Errorf("Same seed generated %q and %q, want identical output", first, second)
+	}
+	if other := generate("5678"); other == first {
+		t.Errorf("Different seeds both generated %q", first)
+	}
+}
+
+func TestNewCmdExtends(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	register := func(name string, files map[string]string) {
+		t.Helper()
+		dir := t.TempDir()
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create %s: %v", file, err)
+			}
+		}
+		if err := reg.Add(name, dir, "", ""); err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+	}
+
+	register("foundation", map[string]string{
+		"LICENSE":   "MIT",
+		"README.md": "# foundation",
+	})
+	register("service", map[string]string{
+		"ason.toml": `extends = "foundation"`,
+		"README.md": "# service",
+		"main.go":   "package main",
+	})
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{"service", outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	want := map[string]string{
+		"LICENSE":   "MIT",
+		"README.md": "# service",
+		"main.go":   "package main",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Errorf("%s was not generated: %v", file, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", file, got, content)
+		}
+	}
+
+	// Templates extending each other are rejected
+	register("loop-a", map[string]string{"ason.toml": `extends = "loop-b"`})
+	register("loop-b", map[string]string{"ason.toml": `extends = "loop-a"`})
+
+	err = newCmd.RunE(newCmd, []string{"loop-a", t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected inheritance cycle error, got %v", err)
+	}
+}
+
+func TestNewCmdIncludes(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+
+	register := func(name string, files map[string]string) {
+		t.Helper()
+		dir := t.TempDir()
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create %s: %v", file, err)
+			}
+		}
+		if err := reg.Add(name, dir, "", ""); err != nil {
+			t.Fatalf("Failed to register %s: %v", name, err)
+		}
+	}
+
+	register("api-service", map[string]string{
+		"main.go": "// {{ name }} service for {{ org }}",
+	})
+	register("monorepo", map[string]string{
+		"ason.toml": `
+[[include]]
+template = "api-service"
+path = "services/api"
+
+[include.vars]
+name = "{{ org }}-api"
+`,
+		"README.md": "# {{ org }} monorepo",
+	})
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"org": "acme", "name": "monorepo"}
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{"monorepo", outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	want := map[string]string{
+		"README.md":            "# acme monorepo",
+		"services/api/main.go": "// acme-api service for acme",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Errorf("%s was not generated: %v", file, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", file, got, content)
+		}
+	}
+
+	// A template including itself hits the depth limit instead of recursing forever
+	register("recursive", map[string]string{"ason.toml": `
+[[include]]
+template = "recursive"
+path = "again"
+`})
+	err = newCmd.RunE(newCmd, []string{"recursive", t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "levels deep") {
+		t.Errorf("Expected include depth error, got %v", err)
+	}
+}
+
+func TestNewCmdStrictVars(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ project_nmae }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"project_name": "demo"}
+
+	// Lenient by default
+	if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	strictVars = true
+	defer func() { strictVars = false }()
+
+	err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+	if err == nil {
+		t.Fatal("Expected error for undefined variable with --strict-vars, got nil")
+	}
+	if !strings.Contains(err.Error(), "project_nmae") {
+		t.Errorf("Error = %q, want it to name the undefined variable", err.Error())
+	}
+}
+
+func TestResolveEngine(t *testing.T) {
+	tests := []struct {
+		flag, template, want string
+	}{
+		{"", "", ""},
+		{"", "go", "go"},
+		{"pongo2", "go", "pongo2"},
+		{"go", "", "go"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveEngine(tt.flag, tt.template); got != tt.want {
+			t.Errorf("resolveEngine(%q, %q) = %q, want %q", tt.flag, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestNewCmdRequiredVariables(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+
+[[variables]]
+name = "project_name"
+required = true
+
+[[variables]]
+name = "owner"
+required = true
+
+[[variables]]
+name = "license"
+required = true
+default = "MIT"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ project_name }} by {{ owner }} ({{ license }})"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	noInput = true
+	defer func() { noInput = false }()
+
+	extraVars = map[string]string{"owner": "platform"}
+	err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+	if err == nil {
+		t.Fatal("Expected error for missing required variable with --no-input, got nil")
+	}
+	if !strings.Contains(err.Error(), "project_name") || strings.Contains(err.Error(), "owner") || strings.Contains(err.Error(), "license") {
+		t.Errorf("Error = %q, want it to name only project_name", err.Error())
+	}
+
+	extraVars = map[string]string{"project_name": "demo", "owner": "platform"}
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "demo by platform (MIT)" {
+		t.Errorf("Generated content = %q, want %q", content, "demo by platform (MIT)")
+	}
+}
+
+func TestNewCmdTypedVariables(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+
+[[variables]]
+name = "port"
+type = "integer"
+default = 80
+
+[[variables]]
+name = "tls"
+type = "boolean"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	tmpl := `{% if port > 1024 %}unprivileged{% else %}privileged{% endif %} {% if tls %}https{% else %}http{% endif %}`
+	if err := os.WriteFile(filepath.Join(templateDir, "mode.txt"), []byte(tmpl), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+
+	extraVars = map[string]string{"port": "abc"}
+	err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "port") {
+		t.Fatalf("Expected error naming port for a non-integer value, got %v", err)
+	}
+
+	extraVars = map[string]string{"port": "8080", "tls": "false"}
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "mode.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "unprivileged http" {
+		t.Errorf("Generated content = %q, want %q", content, "unprivileged http")
+	}
+}
+
+func TestNewCmdListVariables(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "multi-region"
+
+[[variables]]
+name = "regions"
+type = "list"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	tmpl := `{% for r in regions %}[{{ r }}]{% endfor %}`
+	if err := os.WriteFile(filepath.Join(templateDir, "regions.txt"), []byte(tmpl), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	defer func() {
+		extraVars = nil
+		varLists = nil
+	}()
+
+	tests := map[string][]string{
+		"comma separated": {"regions=us-east-1,us-west-2"},
+		"repeated":        {"regions=us-east-1", "regions=us-west-2"},
+	}
+
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			extraVars = nil
+			varLists = nil
+			for _, value := range values {
+				if err := newCmd.Flags().Set("var", value); err != nil {
+					t.Fatalf("Failed to set --var %s: %v", value, err)
+				}
+			}
+
+			outputDir := t.TempDir()
+			if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+				t.Fatalf("newCmd execution failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "regions.txt"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			if string(content) != "[us-east-1][us-west-2]" {
+				t.Errorf("Generated content = %q, want %q", content, "[us-east-1][us-west-2]")
+			}
+		})
+	}
+}
+
+func TestNewCmdListVariablesFromVarFile(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "multi-region"
+
+[[variables]]
+name = "regions"
+type = "list"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	tmpl := `{% for r in regions %}[{{ r }}]{% endfor %}`
+	if err := os.WriteFile(filepath.Join(templateDir, "regions.txt"), []byte(tmpl), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	defer func() { varFile = "" }()
+
+	files := map[string]string{
+		"vars.yaml": "regions:\n  - us-east-1\n  - us-west-2\n",
+		"vars.json": `{"regions": ["us-east-1", "us-west-2"]}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			varFile = filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(varFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create vars file: %v", err)
+			}
+
+			outputDir := t.TempDir()
+			if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+				t.Fatalf("newCmd execution failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "regions.txt"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			if string(content) != "[us-east-1][us-west-2]" {
+				t.Errorf("Generated content = %q, want %q", content, "[us-east-1][us-west-2]")
+			}
+		})
+	}
+}
+
+func TestVarsValue(t *testing.T) {
+	values := map[string]string{}
+	lists := map[string][]string{}
+	v := &varsValue{values: &values, lists: &lists}
+
+	for _, arg := range []string{"env=dev", "env=prod", "url=https://example.com/?a=b"} {
+		if err := v.Set(arg); err != nil {
+			t.Fatalf("Set(%q) failed: %v", arg, err)
+		}
+	}
+
+	if values["env"] != "prod" {
+		t.Errorf("env = %q, want the last value %q", values["env"], "prod")
+	}
+	if values["url"] != "https://example.com/?a=b" {
+		t.Errorf("url = %q, want the value after the first =", values["url"])
+	}
+	if len(lists["env"]) != 2 {
+		t.Errorf("lists[env] = %v, want both values", lists["env"])
+	}
+
+	if err := v.Set("novalue"); err == nil {
+		t.Error("Set without = should fail")
+	}
+}
+
+func TestNewCmdPrompting(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+
+[[variables]]
+name = "project_name"
+required = true
+
+[[variables]]
+name = "license"
+default = "MIT"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ project_name }} ({{ license }})"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+
+	// Answer the form by typing "demo" into the first field and accepting
+	// the defaults of the rest
+	var prompted []string
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		prompted = append(prompted, model.View())
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("demo")})
+		for !model.(prompt.FormPrompt).Done() {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		return model, nil
+	}
+
+	t.Run("terminal", func(t *testing.T) {
+		prompted = nil
+		inputIsTerminal = func(io.Reader) bool { return true }
+
+		outputDir := t.TempDir()
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if len(prompted) != 1 || !strings.Contains(prompted[0], "license") {
+			t.Errorf("Expected one form asking for both variables, got %q", prompted)
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		if string(content) != "demo (MIT)" {
+			t.Errorf("Generated content = %q, want %q", content, "demo (MIT)")
+		}
+	})
+
+	t.Run("no terminal", func(t *testing.T) {
+		prompted = nil
+		inputIsTerminal = func(io.Reader) bool { return false }
+
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "project_name") {
+			t.Errorf("Expected missing project_name error, got %v", err)
+		}
+		if len(prompted) != 0 {
+			t.Errorf("Prompted without a terminal: %q", prompted)
+		}
+	})
+
+	t.Run("no-input", func(t *testing.T) {
+		prompted = nil
+		inputIsTerminal = func(io.Reader) bool { return true }
+		noInput = true
+		defer func() { noInput = false }()
+
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "project_name") {
+			t.Errorf("Expected missing project_name error, got %v", err)
+		}
+		if len(prompted) != 0 {
+			t.Errorf("Prompted with --no-input: %q", prompted)
+		}
+	})
+
+	t.Run("CI", func(t *testing.T) {
+		prompted = nil
+		inputIsTerminal = func(io.Reader) bool { return true }
+		t.Setenv("CI", "true")
+
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "running in CI") {
+			t.Errorf("Expected missing variable error mentioning CI, got %v", err)
+		}
+		if len(prompted) != 0 {
+			t.Errorf("Prompted in CI: %q", prompted)
+		}
+	})
+}
+
+func TestNewCmdPipedInput(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("CI", "")
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+
+[[variables]]
+name = "project_name"
+required = true
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	// Piped input is not a terminal, so generation must fail fast
+	// instead of waiting on a prompt
+	newCmd.SetIn(strings.NewReader("demo\n"))
+	defer newCmd.SetIn(nil)
+
+	done := make(chan error, 1)
+	go func() { done <- newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}) }()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Expected missing variable error, got nil")
+		}
+		for _, want := range []string{"project_name", "--var project_name=", "not a terminal"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Error = %q, want it to contain %q", err.Error(), want)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ason new blocked on piped input")
+	}
+}
+
+func TestNewCmdSecretsStayOutOfSummary(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+next_steps = ["Key: {{ api_key }}", "Run {{ project_name }}"]
+
+[[variables]]
+name = "project_name"
+
+[[variables]]
+name = "api_key"
+type = "password"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "secrets.env"), []byte("API_KEY={{ api_key }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"project_name": "demo", "api_key": "s3cr3t"}
+
+	outputDir := t.TempDir()
+	var buf bytes.Buffer
+	newCmd.SetOut(&buf)
+	defer newCmd.SetOut(nil)
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("Output reveals the secret:\n%s", out)
+	}
+	if !strings.Contains(out, "Run demo") {
+		t.Errorf("Output should still render next steps, got:\n%s", out)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "secrets.env"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "API_KEY=s3cr3t" {
+		t.Errorf("Generated content = %q, want the secret rendered", content)
+	}
+}
+
+func TestNewCmdConfigFlag(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	files := map[string]string{
+		"ason.toml": `name = "service"
+
+[[variables]]
+name = "flavor"
+default = "full"
+`,
+		"alt.toml": `name = "service-minimal"
+engine = "go"
+
+[[variables]]
+name = "flavor"
+default = "minimal"
+`,
+		"flavor.txt": `{{ .flavor }}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	configFile = "alt.toml"
+	defer func() { configFile = "" }()
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	// The go engine and default both come from alt.toml
+	content, err := os.ReadFile(filepath.Join(outputDir, "flavor.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "minimal" {
+		t.Errorf("Generated content = %q, want %q from alt.toml", content, "minimal")
+	}
+
+	configFile = filepath.Join(templateDir, "missing.toml")
+	if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err == nil {
+		t.Error("Expected error for a missing --config file, got nil")
+	}
+}
+
+func TestNewCmdSkipHooksFlag(t *testing.T) {
+	if opts := generatorOptions(nil, io.Discard); opts.SkipHooks {
+		t.Error("SkipHooks should be off by default")
+	}
+
+	if err := newCmd.Flags().Set("skip-hooks", "true"); err != nil {
+		t.Fatalf("Failed to set --skip-hooks: %v", err)
+	}
+	defer func() { skipHooks = false }()
+
+	if opts := generatorOptions(nil, io.Discard); !opts.SkipHooks {
+		t.Error("--skip-hooks should set Options.SkipHooks")
+	}
+}
+
+func TestNewCmdVerbose(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(templateDir, "cmd", "app"), 0755); err != nil {
+		t.Fatalf("Failed to create template dirs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "cmd", "app", "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	if err := newCmd.Flags().Set("verbose", "true"); err != nil {
+		t.Fatalf("Failed to set --verbose: %v", err)
+	}
+	defer func() { verbose = false }()
+
+	var buf bytes.Buffer
+	newCmd.SetOut(&buf)
+	defer newCmd.SetOut(nil)
+
+	if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	for _, want := range []string{"Created directory: cmd", "Created directory: cmd/app"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Verbose output should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestNewCmdTemplateNameIgnoresCase(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := reg.Add("golang", sourceDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	newCmd.SetOut(&buf)
+	newCmd.SetErr(&buf)
+	defer func() {
+		newCmd.SetOut(nil)
+		newCmd.SetErr(nil)
+	}()
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{"GoLang", outputDir}); err != nil {
+		t.Fatalf("newCmd with a differently cased name failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "main.go")); err != nil {
+		t.Errorf("golang template was not generated: %v", err)
+	}
+	if !strings.Contains(buf.String(), "using 'golang'") {
+		t.Errorf("Expected a warning naming the canonical template:\n%s", buf.String())
+	}
+
+	if err := reg.Add("GOLANG", sourceDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	err = newCmd.RunE(newCmd, []string{"GoLang", t.TempDir()})
+	if !errors.Is(err, registry.ErrAmbiguousTemplate) {
+		t.Errorf("ambiguous name error = %v, want ErrAmbiguousTemplate", err)
+	}
+}
+
+func TestNewCmdOverwritePolicy(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("generated"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	// outputWithReadme returns an output directory holding an edited README.md
+	outputWithReadme := func(t *testing.T) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("edited"), 0644); err != nil {
+			t.Fatalf("Failed to create existing file: %v", err)
+		}
+		return dir
+	}
+	defer func() { overwrite = "overwrite" }()
+
+	t.Run("skip", func(t *testing.T) {
+		overwrite = "skip"
+		outputDir := outputWithReadme(t)
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md")); string(content) != "edited" {
+			t.Errorf("README.md = %q, want the existing file unchanged", content)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		overwrite = "backup"
+		outputDir := outputWithReadme(t)
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md.bak")); string(content) != "edited" {
+			t.Errorf("README.md.bak = %q, want the previous content", content)
+		}
+		if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md")); string(content) != "generated" {
+			t.Errorf("README.md = %q, want the generated content", content)
+		}
+	})
+
+	t.Run("ask", func(t *testing.T) {
+		overwrite = "ask"
+		t.Setenv("CI", "")
+		originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+		defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+		inputIsTerminal = func(io.Reader) bool { return true }
+
+		var asked []string
+		runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+			asked = append(asked, model.View())
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+			return model, nil
+		}
+
+		outputDir := outputWithReadme(t)
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if len(asked) != 1 || !strings.Contains(asked[0], "README.md") {
+			t.Errorf("Expected one question about README.md, got %q", asked)
+		}
+		if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md")); string(content) != "generated" {
+			t.Errorf("README.md = %q, want it overwritten after answering yes", content)
+		}
+
+		// Without a terminal there is nobody to ask
+		inputIsTerminal = func(io.Reader) bool { return false }
+		err := newCmd.RunE(newCmd, []string{templateDir, outputWithReadme(t)})
+		if err == nil || !strings.Contains(err.Error(), "cannot prompt") {
+			t.Errorf("Expected an error when asking without a terminal, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		overwrite = "merge"
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if ExitCode(err) != ExitUsage {
+			t.Errorf("invalid policy error = %v, want a usage error", err)
+		}
+	})
+}
+
+func TestNewCmdMinAsonVersion(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	files := map[string]string{
+		"README.md": "hello",
+		"ason.toml": "min_ason_version = \"99.0.0\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	originalVersion := version
+	defer func() { version = originalVersion }()
+
+	t.Run("too old", func(t *testing.T) {
+		version = "0.1.0"
+		outputDir := filepath.Join(t.TempDir(), "out")
+		err := newCmd.RunE(newCmd, []string{templateDir, outputDir})
+		if err == nil {
+			t.Fatal("newCmd should refuse a template requiring a newer ason")
+		}
+		want := "template requires ason >= 99.0.0, but this is ason 0.1.0"
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+		if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+			t.Error("nothing should be generated for a refused template")
+		}
+	})
+
+	t.Run("dev build", func(t *testing.T) {
+		version = "dev"
+		var buf bytes.Buffer
+		newCmd.SetOut(&buf)
+		defer newCmd.SetOut(nil)
+
+		outputDir := filepath.Join(t.TempDir(), "out")
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd should only warn on a development build: %v", err)
+		}
+		if !strings.Contains(buf.String(), "can't check development build") {
+			t.Errorf("output = %q, want a development build warning", buf.String())
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, "README.md")); err != nil {
+			t.Errorf("README.md should be generated: %v", err)
+		}
+	})
+}
+
+func TestNewCmdPartials(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(templateDir, "_partials"), 0755); err != nil {
+		t.Fatalf("Failed to create partials directory: %v", err)
+	}
+	files := map[string]string{
+		"README.md":            `{% include "header.txt" %}body`,
+		"_partials/header.txt": "# {{ name }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	extraVars = map[string]string{"name": "demo"}
+	defer func() { extraVars = nil }()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("README.md was not created: %v", err)
+	}
+	if string(content) != "# demo\nbody" {
+		t.Errorf("README.md = %q, want the partial included", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "_partials")); !os.IsNotExist(err) {
+		t.Error("_partials should not be generated")
+	}
+}
+
+func TestNewCmdAutoVars(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ name }}-{{ env }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "ason.vars.toml"), []byte("name = \"auto\"\nenv = \"dev\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create vars file: %v", err)
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	extraVars = map[string]string{"env": "prod"}
+	defer func() { extraVars = nil }()
+
+	readme := func(t *testing.T, outputDir string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+		if err != nil {
+			t.Fatalf("README.md was not created: %v", err)
+		}
+		return string(content)
+	}
+
+	t.Run("discovered below --var", func(t *testing.T) {
+		var buf bytes.Buffer
+		newCmd.SetOut(&buf)
+		defer newCmd.SetOut(nil)
+
+		outputDir := filepath.Join(t.TempDir(), "out")
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if got := readme(t, outputDir); got != "auto-prod" {
+			t.Errorf("README.md = %q, want the --var value to override the vars file", got)
+		}
+		if !strings.Contains(buf.String(), "Using variables from ason.vars.toml") {
+			t.Errorf("output = %q, want it to name the vars file", buf.String())
+		}
+	})
+
+	t.Run("below --var-file", func(t *testing.T) {
+		explicit := filepath.Join(t.TempDir(), "vars.toml")
+		if err := os.WriteFile(explicit, []byte("name = \"explicit\"\n"), 0644); err != nil {
+			t.Fatalf("Failed to create vars file: %v", err)
+		}
+		varFile = explicit
+		defer func() { varFile = "" }()
+
+		outputDir := filepath.Join(t.TempDir(), "out")
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if got := readme(t, outputDir); got != "explicit-prod" {
+			t.Errorf("README.md = %q, want --var-file to override the vars file", got)
+		}
+	})
+
+	t.Run("no-auto-vars", func(t *testing.T) {
+		noAutoVars = true
+		defer func() { noAutoVars = false }()
+
+		outputDir := filepath.Join(t.TempDir(), "out")
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if got := readme(t, outputDir); got != "-prod" {
+			t.Errorf("README.md = %q, want the vars file ignored", got)
+		}
+	})
+}
+
+func TestNewCmdDumpContext(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	files := map[string]string{
+		"README.md": "{{ name }}",
+		"ason.toml": `[[variables]]
+name = "name"
+
+[[variables]]
+name = "region"
+default = "us-east-1"
+
+[[variables]]
+name = "api_key"
+type = "password"
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	vars := filepath.Join(t.TempDir(), "vars.toml")
+	if err := os.WriteFile(vars, []byte("name = \"from-file\"\nregion = \"eu-west-1\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create vars file: %v", err)
+	}
+
+	varFile = vars
+	extraVars = map[string]string{"name": "from-cli", "api_key": "s3cret"}
+	dumpContext = true
+	defer func() {
+		varFile, extraVars, dumpContext = "", nil, false
+	}()
+
+	var stderr bytes.Buffer
+	newCmd.SetErr(&stderr)
+	defer newCmd.SetErr(nil)
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	var context map[string]interface{}
+	if err := json.Unmarshal(stderr.Bytes(), &context); err != nil {
+		t.Fatalf("stderr is not a JSON context: %v\n%s", err, stderr.String())
+	}
+
+	want := map[string]interface{}{
+		"name":    "from-cli",
+		"region":  "eu-west-1",
+		"api_key": "[redacted]",
+	}
+	for key, value := range want {
+		if context[key] != value {
+			t.Errorf("context[%s] = %v, want %v", key, context[key], value)
+		}
+	}
+	if strings.Contains(stderr.String(), "s3cret") {
+		t.Error("the dumped context should not reveal secret values")
+	}
+}
+
+func TestNewCmdTimeout(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	timeout = time.Nanosecond
+	defer func() { timeout = 0 }()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	err := newCmd.RunE(newCmd, []string{templateDir, outputDir})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want a deadline error", err)
+	}
+	if !strings.Contains(err.Error(), "generation timed out after 1ns") {
+		t.Errorf("error = %q, want it to name the timeout", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("the partial output directory should be removed")
+	}
+}
+
+func TestNewCmdPprof(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	pprofFile = filepath.Join(t.TempDir(), "cpu.pprof")
+	defer func() { pprofFile = "" }()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+	if info, err := os.Stat(pprofFile); err != nil || info.Size() == 0 {
+		t.Errorf("CPU profile should be written: %v", err)
+	}
+	if flag := newCmd.Flags().Lookup("pprof"); flag == nil || !flag.Hidden {
+		t.Error("--pprof should be a hidden flag")
+	}
+}
+
+func TestNewCmdInterrupted(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	// An already cancelled context stands in for Ctrl-C
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	newCmd.SetContext(ctx)
+	defer newCmd.SetContext(context.Background())
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	err := newCmd.RunE(newCmd, []string{templateDir, outputDir})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want a cancellation error", err)
+	}
+	if !strings.Contains(err.Error(), "generation was interrupted") {
+		t.Errorf("error = %q, want it to report the interruption", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("the partial output directory should be removed")
+	}
+}
+
+func TestNewCmdPromptOrder(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `[[variables]]
+name = "zone"
+default = "a"
+
+[[variables]]
+name = "project_name"
+default = "demo"
+
+[[variables]]
+name = "author"
+default = "me"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ project_name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	// Accept every default, remembering the form as first shown
+	var form string
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		form = model.View()
+		for !model.(prompt.FormPrompt).Done() {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		return model, nil
+	}
+	defer func() { promptOrder = promptOrderDeclared }()
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: promptOrderDeclared, want: []string{"zone", "project_name", "author"}},
+		{order: promptOrderAlpha, want: []string{"author", "project_name", "zone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			promptOrder = tt.order
+			if err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()}); err != nil {
+				t.Fatalf("newCmd execution failed: %v", err)
+			}
+
+			last := -1
+			for _, name := range tt.want {
+				i := strings.Index(form, name)
+				if i <= last {
+					t.Fatalf("form asks for %v out of order:\n%s", tt.want, form)
+				}
+				last = i
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		promptOrder = "random"
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		var usage *usageError
+		if !errors.As(err, &usage) {
+			t.Errorf("error = %v, want a usage error", err)
+		}
+	})
+}
+
+func TestNewCmdRegisteredPromptOrder(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	templateDir := t.TempDir()
+	config := `[[variables]]
+name = "zone"
+default = "a"
+
+[[variables]]
+name = "project_name"
+default = "demo"
+
+[[variables]]
+name = "author"
+default = "me"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ project_name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	if err := reg.Add("ordered", templateDir, "", ""); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	var form string
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		form = model.View()
+		for !model.(prompt.FormPrompt).Done() {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		return model, nil
+	}
+
+	if err := newCmd.RunE(newCmd, []string{"ordered", t.TempDir()}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	zone, project, author := strings.Index(form, "zone"), strings.Index(form, "project_name"), strings.Index(form, "author")
+	if zone < 0 || !(zone < project && project < author) {
+		t.Errorf("registered template prompts out of declaration order:\n%s", form)
+	}
+}
+
+func TestVariableSources(t *testing.T) {
+	variables := []template.Variable{
+		{Name: "name"},
+		{Name: "region", Default: "us-east-1"},
+		{Name: "owner"},
+		{Name: "port", Default: int64(8080)},
+	}
+	fileVars := map[string]string{"name": "from-file", "region": "eu-west-1"}
+	cliVars := map[string]string{"name": "from-cli"}
+
+	tests := []struct {
+		name      string
+		canPrompt bool
+		want      []string
+	}{
+		{"prompting", true, []string{sourceVar, sourceFile, sourcePrompt, sourcePrompt}},
+		{"not prompting", false, []string{sourceVar, sourceFile, sourceUnset, sourceDefault}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := variableSources(variables, fileVars, cliVars, tt.canPrompt)
+			if len(sources) != len(variables) {
+				t.Fatalf("variableSources() = %v, want one per variable", sources)
+			}
+			for i, source := range sources {
+				if source.Name != variables[i].Name || source.Source != tt.want[i] {
+					t.Errorf("sources[%d] = %+v, want {%s %s}", i, source, variables[i].Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewCmdDryRunVariablePlan(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `[[variables]]
+name = "project_name"
+required = true
+
+[[variables]]
+name = "author"
+required = true
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ project_name }} by {{ author }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		t.Fatal("a dry run should not prompt")
+		return model, nil
+	}
+
+	extraVars = map[string]string{"project_name": "demo"}
+	dryRun = true
+	defer func() { extraVars, dryRun = nil, false }()
+
+	var buf bytes.Buffer
+	newCmd.SetOut(&buf)
+	defer newCmd.SetOut(nil)
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd dry run failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"project_name  from --var", "author        will prompt"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry run output should contain %q, got:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("a dry run should not create the output directory, stat error = %v", err)
+	}
+}
+
+func TestNewCmdOutputName(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := "output_name = \"{{ project_name }}\"\n\n[[variables]]\nname = \"project_name\"\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ project_name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	workDir := t.TempDir()
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	extraVars = map[string]string{"project_name": "billing-api"}
+	defer func() { extraVars, outputDir = nil, "." }()
+
+	t.Run("named by the template", func(t *testing.T) {
+		outputDir = "."
+		if err := newCmd.RunE(newCmd, []string{templateDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(workDir, "billing-api", "README.md"))
+		if err != nil {
+			t.Fatalf("output_name should create billing-api/: %v", err)
+		}
+		if string(content) != "# billing-api" {
+			t.Errorf("README.md = %q", content)
+		}
+	})
+
+	t.Run("explicit output wins", func(t *testing.T) {
+		outputDir = "."
+		explicit := filepath.Join(t.TempDir(), "elsewhere")
+		if err := newCmd.RunE(newCmd, []string{templateDir, explicit}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(explicit, "README.md")); err != nil {
+			t.Errorf("the output argument should override output_name: %v", err)
+		}
+	})
+
+	t.Run("outside the current directory", func(t *testing.T) {
+		outputDir = "."
+		extraVars = map[string]string{"project_name": "../escape"}
+		err := newCmd.RunE(newCmd, []string{templateDir})
+		if err == nil || !strings.Contains(err.Error(), "not a directory inside the current one") {
+			t.Errorf("newCmd error = %v, want output_name to be rejected", err)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(workDir), "escape")); !os.IsNotExist(err) {
+			t.Errorf("nothing should be generated outside the current directory, stat error = %v", err)
+		}
+	})
+}
+
+func TestNewCmdInto(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	for name, content := range map[string]string{"README.md": "generated", "main.go": "package main"} {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	defer os.Chdir(originalWd)
+
+	// enterNonEmptyDir changes into a fresh directory holding an edited
+	// README.md and returns it
+	enterNonEmptyDir := func(t *testing.T) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("edited"), 0644); err != nil {
+			t.Fatalf("Failed to create existing file: %v", err)
+		}
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Failed to change directory: %v", err)
+		}
+		return dir
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	// answer makes the confirmation answer with key, counting the questions
+	var asked int
+	answer := func(key string) {
+		asked = 0
+		runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+			asked++
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+			return model, nil
+		}
+	}
+
+	into = true
+	defer func() { into, force, outputDir = false, false, "." }()
+
+	t.Run("declined", func(t *testing.T) {
+		dir := enterNonEmptyDir(t)
+		answer("n")
+		err := newCmd.RunE(newCmd, []string{templateDir})
+		if err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Errorf("newCmd error = %v, want a cancellation", err)
+		}
+		if asked != 1 {
+			t.Errorf("asked %d questions, want 1", asked)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "main.go")); !os.IsNotExist(err) {
+			t.Errorf("nothing should be generated after declining, stat error = %v", err)
+		}
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		dir := enterNonEmptyDir(t)
+		answer("y")
+		if err := newCmd.RunE(newCmd, []string{templateDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(content) != "edited" {
+			t.Errorf("README.md = %q, want the existing file kept", content)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+			t.Errorf("main.go should be generated: %v", err)
+		}
+	})
+
+	t.Run("force", func(t *testing.T) {
+		dir := enterNonEmptyDir(t)
+		answer("n")
+		force = true
+		defer func() { force = false }()
+		if err := newCmd.RunE(newCmd, []string{templateDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if asked != 0 {
+			t.Errorf("--force asked %d questions, want none", asked)
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(content) != "generated" {
+			t.Errorf("README.md = %q, want it overwritten with --force", content)
+		}
+	})
+
+	t.Run("no terminal", func(t *testing.T) {
+		enterNonEmptyDir(t)
+		inputIsTerminal = func(io.Reader) bool { return false }
+		defer func() { inputIsTerminal = func(io.Reader) bool { return true } }()
+		err := newCmd.RunE(newCmd, []string{templateDir})
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Errorf("newCmd error = %v, want a hint at --force", err)
+		}
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatalf("Failed to change directory: %v", err)
+		}
+		answer("n")
+		if err := newCmd.RunE(newCmd, []string{templateDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		if asked != 0 {
+			t.Errorf("an empty directory asked %d questions, want none", asked)
+		}
+	})
+
+	t.Run("with output", func(t *testing.T) {
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if ExitCode(err) != ExitUsage {
+			t.Errorf("--into with an output directory error = %v, want a usage error", err)
+		}
+	})
+}
+
+func TestNewCmdValidateVarsAgainstSchema(t *testing.T) {
+	// Save original home directory
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `[[variables]]
+name = "region"
+choices = ["us-east-1", "eu-west-1"]
+
+[[variables]]
+name = "port"
+type = "integer"
+
+[[variables]]
+name = "slug"
+validation = "^[a-z-]+$"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("{{ region }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	vars := filepath.Join(t.TempDir(), "vars.toml")
+	if err := os.WriteFile(vars, []byte("region = \"mars-1\"\nport = \"eighty\"\nslug = \"ok-slug\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create vars file: %v", err)
+	}
+
+	varFile = vars
+	noInput = true
+	validateVarsSchema = true
+	defer func() { varFile, noInput, validateVarsSchema = "", false, false }()
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	err := newCmd.RunE(newCmd, []string{templateDir, outputDir})
+	if err == nil {
+		t.Fatal("newCmd should refuse values that violate the template's declarations")
+	}
+	for _, want := range []string{
+		`variable region: "mars-1" is not one of us-east-1, eu-west-1`,
+		`variable port: "eighty" is not an integer`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "slug") {
+		t.Errorf("error should not mention the valid slug, got: %v", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("nothing should be generated, stat error = %v", err)
+	}
+
+	// Without the flag the choice isn't enforced and only the type fails
+	validateVarsSchema = false
+	err = newCmd.RunE(newCmd, []string{templateDir, outputDir})
+	if err == nil || strings.Contains(err.Error(), "region") {
+		t.Errorf("newCmd error without the flag = %v, want only the integer conversion to fail", err)
+	}
+}
+
+func TestNewCmdFromArchive(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	// Archives of a project usually wrap its files in one directory
+	archive := filepath.Join(t.TempDir(), "service.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	files := []struct{ name, content string }{
+		{"service-main/README.md", "# {{ name }}"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", f.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip: %v", err)
+	}
+	file.Close()
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "archived"}
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{archive, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("README.md was not generated: %v", err)
+	}
+	if string(got) != "# archived" {
+		t.Errorf("README.md = %q, want %q", got, "# archived")
+	}
+
+	// Nothing is registered and the extraction is cleaned up
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	if templates, _ := reg.List(); len(templates) != 0 {
+		t.Errorf("Generating from an archive registered %v", templates)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(tmpDir, "ason-template-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("Extraction directories were left behind: %v", leftovers)
+	}
+}
+
+func TestNewCmdFromGitURL(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	bare := newTestGitRemote(t)
+
+	originalExtraVars := extraVars
+	defer func() { extraVars = originalExtraVars }()
+	extraVars = map[string]string{"name": "cloned"}
+
+	for arg, want := range map[string]string{
+		"file://" + bare:         "# cloned v2",
+		"file://" + bare + "#v1": "# cloned v1",
+	} {
+		outputDir := t.TempDir()
+		if err := newCmd.RunE(newCmd, []string{arg, outputDir}); err != nil {
+			t.Fatalf("newCmd %s failed: %v", arg, err)
+		}
+
+		got, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+		if err != nil {
+			t.Fatalf("README.md was not generated from %s: %v", arg, err)
+		}
+		if string(got) != want {
+			t.Errorf("README.md from %s = %q, want %q", arg, got, want)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, ".git")); !os.IsNotExist(err) {
+			t.Errorf("The clone's .git was generated from %s", arg)
+		}
+	}
+
+	// Nothing is registered and the clones are cleaned up
+	reg, err := openRegistry()
+	if err != nil {
+		t.Fatalf("Failed to open registry: %v", err)
+	}
+	if templates, _ := reg.List(); len(templates) != 0 {
+		t.Errorf("Generating from a Git URL registered %v", templates)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(tmpDir, "ason-template-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("Clones were left outside the cache: %v", leftovers)
+	}
+}
+
+func TestNewCmdRemoteCache(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	bare := newTestGitRemote(t)
+	url := "file://" + bare + "#v1"
+
+	originalExtraVars := extraVars
+	defer func() {
+		extraVars = originalExtraVars
+		refresh = false
+	}()
+	extraVars = map[string]string{"name": "cached"}
+
+	generate := func() error {
+		t.Helper()
+		outputDir := t.TempDir()
+		err := newCmd.RunE(newCmd, []string{url, outputDir})
+		if err == nil {
+			got, _ := os.ReadFile(filepath.Join(outputDir, "README.md"))
+			if string(got) != "# cached v1" {
+				t.Errorf("README.md = %q, want %q", got, "# cached v1")
+			}
+		}
+		return err
+	}
+
+	if err := generate(); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	// With the remote gone, the same URL and ref come from the cache
+	if err := os.RemoveAll(bare); err != nil {
+		t.Fatalf("Failed to remove the remote: %v", err)
+	}
+	if err := generate(); err != nil {
+		t.Fatalf("newCmd should use the cached template: %v", err)
+	}
+
+	// --refresh fetches again, which fails without the remote
+	refresh = true
+	if err := generate(); err == nil {
+		t.Error("newCmd --refresh should fetch the template again")
+	}
+	refresh = false
+
+	// A failed refresh keeps the cached copy
+	if err := generate(); err != nil {
+		t.Fatalf("newCmd should still use the cached template: %v", err)
+	}
+
+	// Without a TTL nothing is cached
+	if err := os.MkdirAll(filepath.Join(configHome, "ason"), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configHome, "ason", "config.toml"), []byte(`cache_ttl = "0"`), 0644); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+	if err := generate(); err == nil {
+		t.Error("newCmd should not use the cache when cache_ttl is 0")
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(tmpDir, "ason-template-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("Clones were left behind: %v", leftovers)
+	}
+
+	// ason cache clear empties the cache
+	captureStdout(t, func() {
+		if err := cacheClearCmd.RunE(cacheClearCmd, nil); err != nil {
+			t.Fatalf("cache clear failed: %v", err)
+		}
+	})
+	if err := os.Remove(filepath.Join(configHome, "ason", "config.toml")); err != nil {
+		t.Fatalf("Failed to remove user config: %v", err)
+	}
+	if err := generate(); err == nil {
+		t.Error("newCmd should fetch the template again after cache clear")
+	}
+}
+
+func TestNewCmdPromptRetry(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `name = "service"
+
+[[variables]]
+name = "port"
+type = "integer"
+validation = "^[1-9][0-9]{3}$"
+`
+	if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("port {{ port }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	// Answer with a value that isn't an integer, then one that doesn't match
+	// the pattern, then a valid one, clearing the refused value each time
+	answers := []string{"eighty", "80", "8080"}
+	var views []string
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		views = append(views, model.View())
+		for model.(prompt.TextPrompt).Value != "" {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		}
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(answers[len(views)-1])})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return model, nil
+	}
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	if len(views) != 3 {
+		t.Fatalf("Prompted %d times, want 3", len(views))
+	}
+	if strings.Contains(views[0], "⚠️") {
+		t.Errorf("First prompt shows an error: %q", views[0])
+	}
+	if !strings.Contains(views[1], `"eighty" is not an integer`) || !strings.Contains(views[1], "eighty") {
+		t.Errorf("Second prompt = %q, want the refused value and why", views[1])
+	}
+	if !strings.Contains(views[2], `"80" does not match`) {
+		t.Errorf("Third prompt = %q, want the pattern error", views[2])
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(content) != "port 8080" {
+		t.Errorf("README.md = %q, want the valid answer", content)
+	}
+
+	// Cancelling a re-asked prompt stops generation
+	views = nil
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		views = append(views, model.View())
+		if len(views) == 1 {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("eighty")})
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		} else {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		}
+		return model, nil
+	}
+	err = newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
+
+func TestNewCmdPromptCancelled(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	// Every prompt is abandoned with Ctrl-C
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+		return model, nil
+	}
+
+	newTemplate := func(t *testing.T, config string) string {
+		t.Helper()
+		dir := t.TempDir()
+		files := map[string]string{
+			"ason.toml": config,
+			"README.md": "# {{ name }}",
+			"main.go":   "package main",
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create %s: %v", name, err)
+			}
+		}
+		return dir
+	}
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"prompt", "[[variables]]\nname = \"name\"\n"},
+		{"form", "[[variables]]\nname = \"name\"\n\n[[variables]]\nname = \"license\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "out")
+			err := newCmd.RunE(newCmd, []string{newTemplate(t, tt.config), outputDir})
+			if !errors.Is(err, prompt.ErrCancelled) || ExitCode(err) != ExitCancelled {
+				t.Errorf("newCmd error = %v (exit %d), want a cancellation", err, ExitCode(err))
+			}
+			if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+				t.Error("A cancelled prompt should not create the output directory")
+			}
+		})
+	}
+
+	t.Run("overwrite question", func(t *testing.T) {
+		overwrite = "ask"
+		defer func() { overwrite = "overwrite" }()
+		extraVars = map[string]string{"name": "demo"}
+		defer func() { extraVars = nil }()
+
+		outputDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outputDir, "README.md"), []byte("edited"), 0644); err != nil {
+			t.Fatalf("Failed to create existing file: %v", err)
+		}
+
+		err := newCmd.RunE(newCmd, []string{newTemplate(t, ""), outputDir})
+		if !errors.Is(err, prompt.ErrCancelled) || ExitCode(err) != ExitCancelled {
+			t.Errorf("newCmd error = %v (exit %d), want a cancellation", err, ExitCode(err))
+		}
+		entries, _ := os.ReadDir(outputDir)
+		if len(entries) != 1 {
+			t.Errorf("Output holds %d entries, want only the existing README.md", len(entries))
+		}
+		if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md")); string(content) != "edited" {
+			t.Errorf("README.md = %q, want it untouched", content)
+		}
+	})
+}
+
+func TestNewCmdAcceptDefaults(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		t.Errorf("Prompted with --accept-defaults: %q", model.View())
+		return model, nil
+	}
+
+	acceptDefaults = true
+	defer func() { acceptDefaults = false }()
+
+	newTemplate := func(t *testing.T, config string) string {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "ason.toml"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to create config: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("{{ project_name }} ({{ license }})"), 0644); err != nil {
+			t.Fatalf("Failed to create template file: %v", err)
+		}
+		return dir
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		templateDir := newTemplate(t, `[[variables]]
+name = "project_name"
+required = true
+default = "demo"
+
+[[variables]]
+name = "license"
+default = "MIT"
+`)
+		outputDir := t.TempDir()
+		if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+			t.Fatalf("newCmd execution failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		if string(content) != "demo (MIT)" {
+			t.Errorf("Generated content = %q, want %q", content, "demo (MIT)")
+		}
+	})
+
+	t.Run("required without default", func(t *testing.T) {
+		templateDir := newTemplate(t, `[[variables]]
+name = "project_name"
+required = true
+
+[[variables]]
+name = "license"
+default = "MIT"
+`)
+		err := newCmd.RunE(newCmd, []string{templateDir, t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "project_name") || !strings.Contains(err.Error(), "--accept-defaults") {
+			t.Errorf("Expected missing project_name error naming --accept-defaults, got %v", err)
+		}
+		if err != nil && strings.Contains(err.Error(), "license") {
+			t.Errorf("Only required variables without a default should be reported, got %v", err)
+		}
+	})
+}
+
+func TestNewCmdComputedVariables(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	templateDir := t.TempDir()
+	config := `[[variables]]
+name = "org"
+
+[[variables]]
+name = "name"
+
+[[computed]]
+name = "module_path"
+value = "github.com/{{ org }}/{{ name }}"
+
+[[computed]]
+name = "import_line"
+value = "import \"{{ module_path }}/internal\""
+`
+	files := map[string]string{
+		"ason.toml": config,
+		"go.mod":    "module {{ module_path }}",
+		"main.go":   "{{ import_line }}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	t.Setenv("CI", "")
+	originalIsTerminal, originalRunPrompt := inputIsTerminal, runPrompt
+	defer func() { inputIsTerminal, runPrompt = originalIsTerminal, originalRunPrompt }()
+	inputIsTerminal = func(io.Reader) bool { return true }
+
+	// Only the declared variables are asked for
+	runPrompt = func(cmd *cobra.Command, model tea.Model) (tea.Model, error) {
+		if view := model.View(); strings.Contains(view, "module_path") {
+			t.Errorf("Prompted for a computed variable: %q", view)
+		}
+		for _, answer := range []string{"acme", "demo"} {
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(answer)})
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		return model, nil
+	}
+
+	outputDir := t.TempDir()
+	if err := newCmd.RunE(newCmd, []string{templateDir, outputDir}); err != nil {
+		t.Fatalf("newCmd execution failed: %v", err)
+	}
+
+	want := map[string]string{
+		"go.mod":  "module github.com/acme/demo",
+		"main.go": `import "github.com/acme/demo/internal"`,
+	}
+	for name, expected := range want {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s = %q, want %q", name, content, expected)
+		}
+	}
+}
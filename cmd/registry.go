@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// registryCmd groups commands that inspect the registry itself
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Inspect the template registry",
+	Long:  `Show where the template registry lives and what it contains.`,
+}
+
+// registryPathCmd prints the resolved registry directory
+var registryPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the registry directory",
+	Args:  cobra.NoArgs,
+	RunE:  runRegistryPath,
+}
+
+// registryInfoCmd prints registry statistics
+var registryInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show registry statistics",
+	Args:  cobra.NoArgs,
+	RunE:  runRegistryInfo,
+}
+
+func init() {
+	registryCmd.AddCommand(registryPathCmd)
+	registryCmd.AddCommand(registryInfoCmd)
+}
+
+func runRegistryPath(cmd *cobra.Command, args []string) error {
+	reg, err := openRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), reg.Path())
+	return nil
+}
+
+func runRegistryInfo(cmd *cobra.Command, args []string) error {
+	reg, err := openRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	stats, err := reg.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	updated := "never"
+	if !stats.Updated.IsZero() {
+		updated = stats.Updated.Format("2006-01-02 15:04:05")
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, "※ Registry")
+	fmt.Fprintf(out, "Path:             %s\n", stats.Path)
+	fmt.Fprintf(out, "Templates:        %d\n", stats.Templates)
+	if stats.SystemTemplates > 0 {
+		fmt.Fprintf(out, "System templates: %d\n", stats.SystemTemplates)
+	}
+	fmt.Fprintf(out, "Size on disk:     %s\n", formatSize(stats.TotalSize))
+	fmt.Fprintf(out, "Last updated:     %s\n", updated)
+	fmt.Fprintf(out, "Format version:   %d\n", stats.Version)
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/ason/internal/registry"
+)
+
+func TestRegistryCmd(t *testing.T) {
+	if registryCmd.Use != "registry" {
+		t.Errorf("registryCmd.Use = %v, want %v", registryCmd.Use, "registry")
+	}
+
+	found := map[string]bool{}
+	for _, sub := range registryCmd.Commands() {
+		found[sub.Use] = true
+	}
+	for _, name := range []string{"path", "info"} {
+		if !found[name] {
+			t.Errorf("registry subcommand %v not found", name)
+		}
+	}
+}
+
+func TestRegistryPathCmd(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	var buf bytes.Buffer
+	registryPathCmd.SetOut(&buf)
+	defer registryPathCmd.SetOut(nil)
+
+	if err := registryPathCmd.RunE(registryPathCmd, nil); err != nil {
+		t.Fatalf("registry path failed: %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != registryDir {
+		t.Errorf("registry path output = %q, want %q", got, registryDir)
+	}
+}
+
+func TestRegistryInfoCmd(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	var buf bytes.Buffer
+	registryInfoCmd.SetOut(&buf)
+	defer registryInfoCmd.SetOut(nil)
+
+	// Empty registry
+	if err := registryInfoCmd.RunE(registryInfoCmd, nil); err != nil {
+		t.Fatalf("registry info failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Templates:        0") || !strings.Contains(buf.String(), "never") {
+		t.Errorf("registry info output for empty registry = %q", buf.String())
+	}
+
+	// Add two templates
+	for _, name := range []string{"one", "two"} {
+		templateDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("0123456789"), 0644); err != nil {
+			t.Fatalf("Failed to create template file: %v", err)
+		}
+		if err := registerCmd.RunE(registerCmd, []string{name, templateDir}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	}
+
+	buf.Reset()
+	if err := registryInfoCmd.RunE(registryInfoCmd, nil); err != nil {
+		t.Fatalf("registry info failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{registryDir, "Templates:        2", "Size on disk:     20 B", "Format version:   1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("registry info output should contain %q, got: %v", want, output)
+		}
+	}
+	if strings.Contains(output, "never") {
+		t.Errorf("registry info should show an update time after registering, got: %v", output)
+	}
+}
+
+func TestReadOnlyRegistry(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := registerCmd.RunE(registerCmd, []string{"baked", templateDir}); err != nil {
+		t.Fatalf("registerCmd execution failed: %v", err)
+	}
+
+	// Make the registry read-only, as if baked into a container image
+	for _, dir := range []string{filepath.Join(registryDir, "templates"), registryDir} {
+		if err := os.Chmod(dir, 0555); err != nil {
+			t.Fatalf("Failed to make %s read-only: %v", dir, err)
+		}
+		defer os.Chmod(dir, 0755)
+	}
+	if probe, err := os.CreateTemp(registryDir, "probe"); err == nil {
+		probe.Close()
+		os.Remove(probe.Name())
+		t.Skip("file permissions aren't enforced for this user")
+	}
+
+	if err := listCmd.RunE(listCmd, nil); err != nil {
+		t.Errorf("list on a read-only registry failed: %v", err)
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	extraVars = map[string]string{"name": "demo"}
+	defer func() { extraVars = nil }()
+	if err := newCmd.RunE(newCmd, []string{"baked", outputDir}); err != nil {
+		t.Fatalf("new from a read-only registry failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(outputDir, "README.md")); string(content) != "# demo" {
+		t.Errorf("README.md = %q, want %q", content, "# demo")
+	}
+
+	err := registerCmd.RunE(registerCmd, []string{"another", templateDir})
+	if !errors.Is(err, registry.ErrReadOnly) {
+		t.Errorf("register on a read-only registry error = %v, want registry.ErrReadOnly", err)
+	}
+}
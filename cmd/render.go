@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/varfile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderString  string
+	renderVars    map[string]string
+	renderVarFile string
+	renderEngine  string
+)
+
+// renderCmd renders a single file or string to stdout
+var renderCmd = &cobra.Command{
+	Use:   "render [file]",
+	Short: "Render a single file or string to stdout",
+	Long: `Render a single template file or string and print the result.
+
+Useful for trying out filters and conditionals without generating a project.
+
+Examples:
+  # Render a file
+  ason render README.md --var name=demo
+
+  # Render a string
+  ason render --string '{{ name|upper }}' --var name=demo
+
+  # Use the Go template engine
+  ason render --engine go --string '{{ .name }}' --var name=demo`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRender,
+}
+
+func init() {
+	renderCmd.Flags().StringVarP(&renderString, "string", "s", "", "Template string to render instead of a file")
+	renderCmd.Flags().StringToStringVar(&renderVars, "var", nil, "Set variables (key=value)")
+	renderCmd.Flags().StringVarP(&renderVarFile, "var-file", "f", "", "Load variables from file (TOML, YAML, JSON, .tfvars, or .env)")
+	renderCmd.Flags().StringVar(&renderEngine, "engine", "", "Template engine (pongo2, go)")
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	if (len(args) == 0) == (renderString == "") {
+		return usageErrorf("provide either a file or --string to render")
+	}
+
+	eng, err := engine.New(renderEngine, engine.Options{})
+	if err != nil {
+		return err
+	}
+
+	var fileVars map[string]string
+	if renderVarFile != "" {
+		fileVars, err = varfile.Load(renderVarFile)
+		if err != nil {
+			return fmt.Errorf("failed to load variables from file: %w", err)
+		}
+	}
+
+	context := make(map[string]interface{})
+	for k, v := range varfile.Merge(fileVars, renderVars) {
+		context[k] = v
+	}
+
+	var output string
+	if len(args) > 0 {
+		output, err = eng.RenderFile(args[0], context)
+	} else {
+		output, err = eng.Render(renderString, context)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), output)
+	return nil
+}
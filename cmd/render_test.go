@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderCmd(t *testing.T) {
+	if renderCmd == nil {
+		t.Fatal("renderCmd should not be nil")
+	}
+
+	if renderCmd.Use != "render [file]" {
+		t.Errorf("renderCmd.Use = %v, want %v", renderCmd.Use, "render [file]")
+	}
+
+	for _, flag := range []string{"string", "var", "var-file", "engine"} {
+		if renderCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("--%s flag should be defined", flag)
+		}
+	}
+}
+
+func TestRenderCmdExecution(t *testing.T) {
+	defer func() {
+		renderString = ""
+		renderVars = nil
+		renderEngine = ""
+	}()
+
+	tmpFile := filepath.Join(t.TempDir(), "greeting.txt")
+	err := os.WriteFile(tmpFile, []byte("Hello {{ name }}{% if loud %}!{% endif %}"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		str     string
+		engine  string
+		vars    map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "file",
+			args: []string{tmpFile},
+			vars: map[string]string{"name": "World", "loud": "yes"},
+			want: "Hello World!",
+		},
+		{
+			name: "string",
+			str:  "{{ name|upper }}",
+			vars: map[string]string{"name": "ason"},
+			want: "ASON",
+		},
+		{
+			name:   "go engine",
+			str:    "{{ .name }}",
+			engine: "go",
+			vars:   map[string]string{"name": "gopher"},
+			want:   "gopher",
+		},
+		{
+			name:    "neither file nor string",
+			wantErr: true,
+		},
+		{
+			name:    "both file and string",
+			args:    []string{tmpFile},
+			str:     "x",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderString = tt.str
+			renderVars = tt.vars
+			renderEngine = tt.engine
+
+			var buf bytes.Buffer
+			renderCmd.SetOut(&buf)
+			defer renderCmd.SetOut(nil)
+
+			err := renderCmd.RunE(renderCmd, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderCmd error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("renderCmd output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/madstone-tech/ason/internal/registry"
+	"github.com/madstone-tech/ason/internal/xdg"
 	"github.com/spf13/cobra"
 )
 
@@ -34,23 +39,74 @@ Named after the ason, the ritual rattle that activates spiritual work
 in Haitian Vodou, this tool activates your templates, transforming them
 into ready-to-use projects with rhythm and purpose.`,
 	Version: version,
+	Args:    cobra.NoArgs,
+	// Bare `ason` shows the help rather than doing nothing
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+	// Execute reports errors itself, without the usage text cobra prints
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// registryDir overrides the registry location for all commands
+var registryDir string
+
+// verbose turns on detailed progress output
+var verbose bool
+
+// openRegistry opens the registry selected by --registry-dir, $ASON_HOME or
+// the XDG data directory, in that order
+func openRegistry() (*registry.Registry, error) {
+	if registryDir != "" {
+		reg, err := registry.NewRegistryAt(registryDir)
+		if err != nil {
+			return nil, err
+		}
+		return reg.WithSystemDirs(xdg.DataDirs()), nil
+	}
+	return registry.NewRegistry()
 }
 
+// Execute runs the command line and prints any error to stderr, pointing at
+// the command's help for usage errors. Use ExitCode for the exit status.
 func Execute() error {
-	return rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), "Error:", err)
+
+		var usage *usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintf(rootCmd.ErrOrStderr(), "Run '%s --help' for usage.\n", cmd.CommandPath())
+		}
+	}
+	return err
 }
 
 func init() {
 	rootCmd.SetVersionTemplate(`※ Ason {{.Version}}
 `)
 
+	rootCmd.PersistentFlags().StringVar(&registryDir, "registry-dir", "", "Registry directory (overrides $ASON_HOME and the XDG data directory)")
+	// -v is --version on the root command; subcommands may claim it for --verbose
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Show detailed progress output")
+
 	// Add commands
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(registerCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(registryCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(tagsCmd)
+	rootCmd.AddCommand(typesCmd)
+	rootCmd.AddCommand(versionCmd)
 
 	// Setup autocompletion
 	setupCompletions()
+
+	markUsageErrors(rootCmd)
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"log/slog"
 	"strings"
 	"testing"
 )
@@ -118,3 +119,80 @@ func TestRootCmdHelp(t *testing.T) {
 	rootCmd.SetOut(nil)
 	rootCmd.SetArgs(nil)
 }
+
+// resetRootFlags clears --help and --version left set by earlier Execute calls
+func resetRootFlags(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"help", "version"} {
+		if f := rootCmd.Flags().Lookup(name); f != nil {
+			if err := f.Value.Set("false"); err != nil {
+				t.Fatalf("Failed to reset --%s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestRootCmdBareInvocationShowsHelp(t *testing.T) {
+	resetRootFlags(t)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := Execute(); err != nil {
+		t.Fatalf("bare ason should succeed, got %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"Available Commands:", "new", "register"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("help output should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRootCmdUnknownCommand(t *testing.T) {
+	resetRootFlags(t)
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs([]string{"frobnicate"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	err := Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("Expected unknown command error, got %v", err)
+	}
+}
+
+func TestLogLevelFlag(t *testing.T) {
+	flag := rootCmd.PersistentFlags().Lookup("log-level")
+	if flag == nil {
+		t.Fatal("rootCmd should have a --log-level flag")
+	}
+	defer flag.Value.Set("warn")
+
+	if flag.Value.String() != "warn" {
+		t.Errorf("default --log-level = %q, want warn", flag.Value.String())
+	}
+
+	if err := flag.Value.Set("debug"); err != nil {
+		t.Fatalf("Set(debug) error = %v", err)
+	}
+	if logLevel.Level() != slog.LevelDebug {
+		t.Errorf("logLevel = %v, want debug", logLevel.Level())
+	}
+
+	if err := flag.Value.Set("chatty"); err == nil {
+		t.Error("Set(chatty) should fail for an unknown level")
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/cache"
+	"github.com/madstone-tech/ason/internal/gitclone"
+	"github.com/spf13/cobra"
+)
+
+// sourceShorthands are the Git provider prefixes expandTemplateSource
+// expands, with the URL they stand for
+var sourceShorthands = []struct{ prefix, base string }{
+	{"gh:", "https://github.com/"},
+	{"gl:", "https://gitlab.com/"},
+}
+
+// expandTemplateSource expands a gh:owner/repo or gl:owner/repo template
+// source into the HTTPS URL of the repository, keeping any #ref. Other
+// sources, such as URLs, paths and template names, are returned unchanged.
+func expandTemplateSource(src string) string {
+	for _, s := range sourceShorthands {
+		repo, ok := strings.CutPrefix(src, s.prefix)
+		if !ok || repo == "" || strings.HasPrefix(repo, "#") {
+			continue
+		}
+		repo, ref, hasRef := strings.Cut(repo, "#")
+		url := s.base + strings.TrimSuffix(repo, ".git") + ".git"
+		if hasRef {
+			url += "#" + ref
+		}
+		return url
+	}
+	return src
+}
+
+// remoteSource reports whether a template source names a Git repository.
+// Existing paths are always local, even if they look like a URL.
+func remoteSource(src string) (gitclone.Source, bool) {
+	if _, err := os.Stat(src); err == nil {
+		return gitclone.Source{}, false
+	}
+	return gitclone.Parse(expandTemplateSource(src))
+}
+
+// fetchRemote returns a directory holding a remote template and a function
+// to call when done with it. A template fetched less than ttl ago is used
+// from the cache, unless refresh is set; otherwise it is cloned into the
+// cache. A ttl of 0 skips the cache and clones into a temporary directory,
+// which the function removes.
+func fetchRemote(cmd *cobra.Command, status io.Writer, remote gitclone.Source, ttl time.Duration, refresh bool) (string, func(), error) {
+	if ttl <= 0 {
+		tmpDir, err := os.MkdirTemp("", "ason-template-*")
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		cleanup := func() { os.RemoveAll(tmpDir) }
+
+		fmt.Fprintf(status, "📡 Cloning template from %s\n", remote)
+		dir := filepath.Join(tmpDir, "template")
+		if err := cloneRemote(cmd, remote, dir); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		return dir, cleanup, nil
+	}
+
+	templates, err := cache.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	source := remote.String()
+	if !refresh {
+		if entry, ok := templates.Lookup(source, ttl); ok {
+			fmt.Fprintf(status, "📦 Using %s from the cache, fetched %s\n", remote, entry.Fetched.Local().Format("2006-01-02 15:04"))
+			return entry.Path, func() {}, nil
+		}
+	}
+
+	fmt.Fprintf(status, "📡 Cloning template from %s\n", remote)
+	entry, err := templates.Store(source, func(dir string) error {
+		return cloneRemote(cmd, remote, dir)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return entry.Path, func() {}, nil
+}
+
+// cloneRemote clones a remote template into dir, stopping when the user
+// presses Ctrl-C
+func cloneRemote(cmd *cobra.Command, remote gitclone.Source, dir string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	return gitclone.Clone(ctx, remote, dir)
+}
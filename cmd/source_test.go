@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newTestGitRemote creates a bare repository standing in for a remote
+// template and returns its path. Its README.md renders "# {{ name }} v1" at
+// tag v1 and "# {{ name }} v2" at tag v2, the latest commit.
+func newTestGitRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	work := t.TempDir()
+	git(work, "init", "--quiet")
+	for _, version := range []string{"v1", "v2"} {
+		if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("# {{ name }} "+version), 0644); err != nil {
+			t.Fatalf("Failed to write README.md: %v", err)
+		}
+		git(work, "add", "README.md")
+		git(work, "commit", "--quiet", "-m", version)
+		git(work, "tag", version)
+	}
+
+	bare := filepath.Join(t.TempDir(), "service.git")
+	git(work, "clone", "--quiet", "--bare", work, bare)
+	return bare
+}
+
+func TestExpandTemplateSource(t *testing.T) {
+	tests := map[string]string{
+		"gh:owner/repo":        "https://github.com/owner/repo.git",
+		"gh:owner/repo.git":    "https://github.com/owner/repo.git",
+		"gh:owner/repo#v1.2.0": "https://github.com/owner/repo.git#v1.2.0",
+		"gl:group/sub/repo":    "https://gitlab.com/group/sub/repo.git",
+
+		// Everything else passes through
+		"https://github.com/owner/repo.git#main": "https://github.com/owner/repo.git#main",
+		"git@github.com:owner/repo.git":          "git@github.com:owner/repo.git",
+		"./templates/web-app":                    "./templates/web-app",
+		"/srv/templates/web-app":                 "/srv/templates/web-app",
+		"web-app":                                "web-app",
+		"gh:":                                    "gh:",
+		"bb:owner/repo":                          "bb:owner/repo",
+	}
+
+	for src, want := range tests {
+		if got := expandTemplateSource(src); got != want {
+			t.Errorf("expandTemplateSource(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestRemoteSource(t *testing.T) {
+	if remote, ok := remoteSource("gh:owner/repo#v1"); !ok || remote.URL != "https://github.com/owner/repo.git" || remote.Ref != "v1" {
+		t.Errorf("remoteSource(gh:owner/repo#v1) = %+v, %v", remote, ok)
+	}
+	if _, ok := remoteSource("web-app"); ok {
+		t.Error("remoteSource(web-app) should not be remote")
+	}
+
+	// An existing path is local, even when it looks like a shorthand
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("gh:owner", 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if _, ok := remoteSource("gh:owner"); ok {
+		t.Error("remoteSource() should treat an existing path as local")
+	}
+}
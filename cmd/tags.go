@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Orders for tables of counted values
+const (
+	countSortCount = "count"
+	countSortName  = "name"
+)
+
+var tagsSort string
+
+// tagsCmd lists the tags used across the registry
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List the tags used by registered templates",
+	Long: `List every distinct tag declared by registered templates, with the number
+of templates carrying it. Tags come from the tags list in each template's
+config. The most used tags come first; use --sort name to list them
+alphabetically.`,
+	Args: cobra.NoArgs,
+	RunE: runTags,
+}
+
+func init() {
+	tagsCmd.Flags().StringVar(&tagsSort, "sort", countSortCount, "Sort by count or name")
+}
+
+func runTags(cmd *cobra.Command, args []string) error {
+	if tagsSort != countSortCount && tagsSort != countSortName {
+		return usageErrorf("invalid --sort %q (supported: %s, %s)", tagsSort, countSortCount, countSortName)
+	}
+
+	reg, err := openRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	tags, err := reg.AllTags()
+	if err != nil {
+		return fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(tags) == 0 {
+		fmt.Fprintln(out, "No tagged templates found")
+		fmt.Fprintln(out, "💡 Add tags = [\"...\"] to a template's ason.toml and register it again")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tTEMPLATES")
+	fmt.Fprintln(w, "---\t---------")
+	for _, tag := range sortCounts(tags, tagsSort) {
+		fmt.Fprintf(w, "%s\t%d\n", tag, tags[tag])
+	}
+	return w.Flush()
+}
+
+// sortCounts returns the keys of counts, most frequent first with ties in
+// name order, or purely by name for countSortName
+func sortCounts(counts map[string]int, order string) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if order == countSortCount && counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
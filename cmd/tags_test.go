@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagsCmd(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	defer func() { tagsSort = countSortCount }()
+
+	var buf bytes.Buffer
+	tagsCmd.SetOut(&buf)
+	defer tagsCmd.SetOut(nil)
+
+	if err := tagsCmd.RunE(tagsCmd, nil); err != nil {
+		t.Fatalf("tags failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No tagged templates found") {
+		t.Errorf("tags output for empty registry = %q", buf.String())
+	}
+
+	for name, tags := range map[string]string{
+		"api":  `["go", "api"]`,
+		"cli":  `["go", "cli"]`,
+		"site": `["web", "api"]`,
+		"docs": `["go"]`,
+	} {
+		templateDir := t.TempDir()
+		config := "description = \"Demo\"\ntags = " + tags + "\n"
+		if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to create ason.toml: %v", err)
+		}
+		if err := registerCmd.RunE(registerCmd, []string{name, templateDir}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{countSortCount, []string{"go 3", "api 2", "cli 1", "web 1"}},
+		{countSortName, []string{"api 2", "cli 1", "go 3", "web 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			tagsSort = tt.sort
+			buf.Reset()
+			if err := tagsCmd.RunE(tagsCmd, nil); err != nil {
+				t.Fatalf("tags failed: %v", err)
+			}
+
+			var rows []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[2:] {
+				rows = append(rows, strings.Join(strings.Fields(line), " "))
+			}
+			if strings.Join(rows, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("tags rows = %v, want %v", rows, tt.want)
+			}
+		})
+	}
+
+	tagsSort = "size"
+	if err := tagsCmd.RunE(tagsCmd, nil); ExitCode(err) != ExitUsage {
+		t.Errorf("tags --sort size error = %v, want a usage error", err)
+	}
+}
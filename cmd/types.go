@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	typesSort   string
+	typesFormat string
+)
+
+// typesCmd lists the template types used across the registry
+var typesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "List the types of registered templates",
+	Long: `List every distinct template type in the registry, with the number of
+templates of that type. Types are set with register --type or the template's
+config; templates without one aren't listed.`,
+	Args: cobra.NoArgs,
+	RunE: runTypes,
+}
+
+func init() {
+	typesCmd.Flags().StringVar(&typesSort, "sort", countSortCount, "Sort by count or name")
+	typesCmd.Flags().StringVar(&typesFormat, "format", "table", "Output format (table, json)")
+}
+
+// typeCount is one row of `ason types --format json`
+type typeCount struct {
+	Type      string `json:"type"`
+	Templates int    `json:"templates"`
+}
+
+func runTypes(cmd *cobra.Command, args []string) error {
+	if typesSort != countSortCount && typesSort != countSortName {
+		return usageErrorf("invalid --sort %q (supported: %s, %s)", typesSort, countSortCount, countSortName)
+	}
+	if typesFormat != "table" && typesFormat != "json" {
+		return usageErrorf("invalid --format %q (supported: table, json)", typesFormat)
+	}
+
+	reg, err := openRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	types, err := reg.Types()
+	if err != nil {
+		return fmt.Errorf("failed to read registry: %w", err)
+	}
+	names := sortCounts(types, typesSort)
+
+	out := cmd.OutOrStdout()
+	if typesFormat == "json" {
+		rows := make([]typeCount, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, typeCount{Type: name, Templates: types[name]})
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{"types": rows}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode types: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if len(names) == 0 {
+		fmt.Fprintln(out, "No typed templates found")
+		fmt.Fprintln(out, "💡 Set a type with 'ason register --type TYPE NAME PATH'")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTEMPLATES")
+	fmt.Fprintln(w, "----\t---------")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\n", name, types[name])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypesCmd(t *testing.T) {
+	registryDir = t.TempDir()
+	defer func() { registryDir = "" }()
+	defer func() { typesSort, typesFormat, registerType = countSortCount, "table", "" }()
+
+	var buf bytes.Buffer
+	typesCmd.SetOut(&buf)
+	defer typesCmd.SetOut(nil)
+
+	if err := typesCmd.RunE(typesCmd, nil); err != nil {
+		t.Fatalf("types failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No typed templates found") {
+		t.Errorf("types output for empty registry = %q", buf.String())
+	}
+
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# Demo"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	for name, templateType := range map[string]string{
+		"api":    "service",
+		"worker": "service",
+		"tool":   "cli",
+		"notes":  "",
+	} {
+		registerType = templateType
+		if err := registerCmd.RunE(registerCmd, []string{name, templateDir}); err != nil {
+			t.Fatalf("registerCmd execution failed: %v", err)
+		}
+	}
+
+	buf.Reset()
+	typesSort = countSortName
+	if err := typesCmd.RunE(typesCmd, nil); err != nil {
+		t.Fatalf("types failed: %v", err)
+	}
+	var rows []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[2:] {
+		rows = append(rows, strings.Join(strings.Fields(line), " "))
+	}
+	if want := []string{"cli 1", "service 2"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("types rows = %v, want %v", rows, want)
+	}
+
+	buf.Reset()
+	typesSort, typesFormat = countSortCount, "json"
+	if err := typesCmd.RunE(typesCmd, nil); err != nil {
+		t.Fatalf("types --format json failed: %v", err)
+	}
+	var output struct {
+		Types []typeCount `json:"types"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("types --format json output is not JSON: %v\n%s", err, buf.String())
+	}
+	if want := []typeCount{{"service", 2}, {"cli", 1}}; !reflect.DeepEqual(output.Types, want) {
+		t.Errorf("types JSON = %+v, want %+v", output.Types, want)
+	}
+
+	typesFormat = "yaml"
+	if err := typesCmd.RunE(typesCmd, nil); ExitCode(err) != ExitUsage {
+		t.Errorf("types --format yaml error = %v, want a usage error", err)
+	}
+}
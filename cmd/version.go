@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+// versionCmd prints the full build information; --version on the root
+// command only shows the version number
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Print the ason version together with the commit, build date and builder
+it was built from. Use --json for a machine-readable object.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print the build information as JSON")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	if versionJSON {
+		data, err := json.MarshalIndent(map[string]string{
+			"version": version,
+			"commit":  commit,
+			"date":    date,
+			"builtBy": builtBy,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode version information: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	fmt.Fprintf(out, "※ Ason %s\n", version)
+	fmt.Fprintf(out, "Commit:   %s\n", commit)
+	fmt.Fprintf(out, "Built:    %s\n", date)
+	fmt.Fprintf(out, "Built by: %s\n", builtBy)
+	return nil
+}
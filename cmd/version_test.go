@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setVersionInfo sets the build information for the test and restores it,
+// along with rootCmd.Version, afterwards
+func setVersionInfo(t *testing.T, v, c, d, b string) {
+	t.Helper()
+	originalVersion, originalCommit, originalDate, originalBuiltBy := version, commit, date, builtBy
+	t.Cleanup(func() {
+		SetVersionInfo(originalVersion, originalCommit, originalDate, originalBuiltBy)
+	})
+	SetVersionInfo(v, c, d, b)
+}
+
+func TestVersionCmd(t *testing.T) {
+	setVersionInfo(t, "1.4.0", "abc1234", "2025-10-22T10:00:00Z", "goreleaser")
+
+	var buf bytes.Buffer
+	versionCmd.SetOut(&buf)
+	defer versionCmd.SetOut(nil)
+
+	if err := versionCmd.RunE(versionCmd, nil); err != nil {
+		t.Fatalf("versionCmd execution failed: %v", err)
+	}
+
+	for _, want := range []string{"※ Ason 1.4.0", "abc1234", "2025-10-22T10:00:00Z", "goreleaser"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestVersionCmdJSON(t *testing.T) {
+	setVersionInfo(t, "1.4.0", "abc1234", "2025-10-22T10:00:00Z", "goreleaser")
+
+	var buf bytes.Buffer
+	versionCmd.SetOut(&buf)
+	defer versionCmd.SetOut(nil)
+
+	versionJSON = true
+	defer func() { versionJSON = false }()
+
+	if err := versionCmd.RunE(versionCmd, nil); err != nil {
+		t.Fatalf("versionCmd execution failed: %v", err)
+	}
+
+	var info map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]string{
+		"version": "1.4.0",
+		"commit":  "abc1234",
+		"date":    "2025-10-22T10:00:00Z",
+		"builtBy": "goreleaser",
+	}
+	for key, value := range want {
+		if info[key] != value {
+			t.Errorf("%s = %q, want %q", key, info[key], value)
+		}
+	}
+}
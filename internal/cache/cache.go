@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/fsutil"
+	"github.com/madstone-tech/ason/internal/xdg"
+)
+
+// metaFile records where a cached template came from and when
+const metaFile = "source.json"
+
+// templateDir is the directory of an entry holding the template itself
+const templateDir = "template"
+
+// Cache keeps copies of remote templates, keyed by their source, so that
+// using one again needs no network
+type Cache struct {
+	dir string
+}
+
+// Entry is one cached template
+type Entry struct {
+	// Source is the URL and ref the template was fetched from
+	Source string `json:"source"`
+
+	// Fetched is when the template was fetched
+	Fetched time.Time `json:"fetched"`
+
+	// Path is the template directory
+	Path string `json:"-"`
+
+	// Size is the total size of the template files, set by List
+	Size int64 `json:"-"`
+}
+
+// New returns a cache kept in dir
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Open returns the cache in the XDG cache directory
+func Open() (*Cache, error) {
+	home, err := xdg.CacheHome()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return New(filepath.Join(home, "templates")), nil
+}
+
+// Dir returns the directory the cache is kept in
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// entryDir returns where the template fetched from source is cached
+func (c *Cache) entryDir(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16]))
+}
+
+// Lookup returns the template cached for source if it was fetched less
+// than ttl ago
+func (c *Cache) Lookup(source string, ttl time.Duration) (Entry, bool) {
+	entry, err := readEntry(c.entryDir(source))
+	if err != nil || entry.Source != source || time.Since(entry.Fetched) >= ttl {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Store caches the template fetched from source, replacing any cached
+// before. fetch creates the template in the directory it is given, which
+// doesn't exist yet; nothing is cached if it fails.
+func (c *Cache) Store(source string, fetch func(dir string) error) (Entry, error) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return Entry{}, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Fetch next to the entry, so it can be moved into place in one step
+	staging, err := os.MkdirTemp(c.dir, ".fetch-*")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := fetch(filepath.Join(staging, templateDir)); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Source: source, Fetched: time.Now().UTC()}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, metaFile), data, 0644); err != nil {
+		return Entry{}, fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	dir := c.entryDir(source)
+	if err := os.RemoveAll(dir); err != nil {
+		return Entry{}, fmt.Errorf("failed to replace cached template: %w", err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return Entry{}, fmt.Errorf("failed to cache template: %w", err)
+	}
+	entry.Path = filepath.Join(dir, templateDir)
+	return entry, nil
+}
+
+// List returns the cached templates, sorted by source. The metadata each
+// entry keeps next to its template serves as the index; directories
+// without it, such as fetches in progress, are left out.
+func (c *Cache) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entries []Entry
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(c.dir, dir.Name()))
+		if err != nil {
+			continue
+		}
+		stats, err := fsutil.Analyze(entry.Path, fsutil.Policy{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure cached template %s: %w", entry.Source, err)
+		}
+		entry.Size = stats.Size
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Source < entries[j].Source })
+	return entries, nil
+}
+
+// Prune removes the cached templates fetched more than age ago and returns
+// them
+func (c *Cache) Prune(age time.Duration) ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Entry
+	for _, entry := range entries {
+		if time.Since(entry.Fetched) <= age {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Dir(entry.Path)); err != nil {
+			return removed, fmt.Errorf("failed to remove cached template %s: %w", entry.Source, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// Clear removes every cached template
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// readEntry reads the cache entry in dir
+func readEntry(dir string) (Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if err != nil {
+		return Entry{}, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to read cache entry %s: %w", dir, err)
+	}
+	entry.Path = filepath.Join(dir, templateDir)
+	return entry, nil
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeTemplate returns a fetch function creating a template whose
+// README.md holds content, counting its calls in fetches
+func writeTemplate(content string, fetches *int) func(dir string) error {
+	return func(dir string) error {
+		*fetches++
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0644)
+	}
+}
+
+func TestCache_StoreAndLookup(t *testing.T) {
+	c := New(t.TempDir())
+	const source = "https://github.com/example/service.git#v1"
+
+	if _, ok := c.Lookup(source, time.Hour); ok {
+		t.Fatal("Lookup() found a template before any was stored")
+	}
+
+	var fetches int
+	stored, err := c.Store(source, writeTemplate("v1", &fetches))
+	if err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	entry, ok := c.Lookup(source, time.Hour)
+	if !ok {
+		t.Fatal("Lookup() should find the stored template")
+	}
+	if entry.Source != source || entry.Path != stored.Path {
+		t.Errorf("Lookup() = %+v, want %+v", entry, stored)
+	}
+	content, err := os.ReadFile(filepath.Join(entry.Path, "README.md"))
+	if err != nil || string(content) != "v1" {
+		t.Errorf("Cached README.md = %q, %v", content, err)
+	}
+
+	// Other refs of the same repository are cached apart
+	if _, ok := c.Lookup("https://github.com/example/service.git#v2", time.Hour); ok {
+		t.Error("Lookup() should not find a template stored for another ref")
+	}
+
+	// Expired templates aren't used
+	if _, ok := c.Lookup(source, 0); ok {
+		t.Error("Lookup() should not return templates older than the TTL")
+	}
+
+	// Storing again replaces the template
+	if _, err := c.Store(source, writeTemplate("v1 again", &fetches)); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(entry.Path, "README.md"))
+	if string(content) != "v1 again" {
+		t.Errorf("README.md after storing again = %q", content)
+	}
+	if fetches != 2 {
+		t.Errorf("fetch was called %d times, want 2", fetches)
+	}
+}
+
+func TestCache_StoreFailure(t *testing.T) {
+	c := New(t.TempDir())
+	const source = "https://github.com/example/service.git"
+
+	var fetches int
+	if _, err := c.Store(source, writeTemplate("old", &fetches)); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	errFetch := errors.New("network down")
+	if _, err := c.Store(source, func(dir string) error { return errFetch }); !errors.Is(err, errFetch) {
+		t.Errorf("Store() error = %v, want %v", err, errFetch)
+	}
+
+	// The template cached before survives, and no staging is left over
+	entry, ok := c.Lookup(source, time.Hour)
+	if !ok {
+		t.Fatal("A failed fetch should keep the cached template")
+	}
+	if content, _ := os.ReadFile(filepath.Join(entry.Path, "README.md")); string(content) != "old" {
+		t.Errorf("README.md = %q, want the old template", content)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(c.Dir(), ".fetch-*")); len(leftovers) != 0 {
+		t.Errorf("Staging directories were left behind: %v", leftovers)
+	}
+}
+
+func TestCache_Clear(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "templates"))
+	var fetches int
+	if _, err := c.Store("https://github.com/example/service.git", writeTemplate("v1", &fetches)); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear() failed: %v", err)
+	}
+	if _, err := os.Stat(c.Dir()); !os.IsNotExist(err) {
+		t.Error("Clear() should remove the cache directory")
+	}
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear() of an empty cache failed: %v", err)
+	}
+}
+
+// backdate makes the template cached for source look fetched age ago
+func backdate(t *testing.T, c *Cache, source string, age time.Duration) {
+	t.Helper()
+	entry := Entry{Source: source, Fetched: time.Now().Add(-age).UTC()}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Failed to encode entry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.entryDir(source), metaFile), data, 0644); err != nil {
+		t.Fatalf("Failed to backdate %s: %v", source, err)
+	}
+}
+
+func TestCache_ListAndPrune(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "templates"))
+
+	entries, err := c.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() of a missing cache = %v, %v, want nothing", entries, err)
+	}
+
+	var fetches int
+	sources := []string{"https://gitlab.com/b.git", "https://github.com/a.git#v1", "https://github.com/a.git#v2"}
+	for _, source := range sources {
+		if _, err := c.Store(source, writeTemplate("12345", &fetches)); err != nil {
+			t.Fatalf("Store(%s) failed: %v", source, err)
+		}
+	}
+	backdate(t, c, sources[0], 10*24*time.Hour)
+	backdate(t, c, sources[1], 48*time.Hour)
+
+	// Fetches in progress aren't listed
+	if err := os.MkdirAll(filepath.Join(c.Dir(), ".fetch-123", "template"), 0755); err != nil {
+		t.Fatalf("Failed to create staging directory: %v", err)
+	}
+
+	entries, err = c.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	var listed []string
+	for _, entry := range entries {
+		listed = append(listed, entry.Source)
+		if entry.Size != 5 {
+			t.Errorf("%s Size = %d, want 5", entry.Source, entry.Size)
+		}
+	}
+	want := []string{"https://github.com/a.git#v1", "https://github.com/a.git#v2", "https://gitlab.com/b.git"}
+	if !reflect.DeepEqual(listed, want) {
+		t.Errorf("List() = %v, want %v", listed, want)
+	}
+
+	removed, err := c.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Prune() removed %d templates, want 2", len(removed))
+	}
+	entries, _ = c.List()
+	if len(entries) != 1 || entries[0].Source != sources[2] {
+		t.Errorf("List() after Prune() = %v, want only %s", entries, sources[2])
+	}
+	if _, ok := c.Lookup(sources[0], 365*24*time.Hour); ok {
+		t.Error("Lookup() found a pruned template")
+	}
+}
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func BenchmarkPongo2Engine_Render(b *testing.B) {
+	context := map[string]interface{}{
+		"project_name": "bench",
+		"features":     []string{"api", "db", "auth"},
+	}
+	templates := map[string]string{
+		"variable": "# {{ project_name }}",
+		"filters":  "{{ project_name|upper }}-{{ project_name|title }}",
+		"loop":     "{% for f in features %}- {{ f }}\n{% endfor %}",
+		"large":    strings.Repeat("{{ project_name }} {% if features %}{{ features|length }}{% endif %}\n", 200),
+	}
+
+	for name, tmpl := range templates {
+		b.Run(name, func(b *testing.B) {
+			eng := NewPongo2EngineWithOptions(Options{})
+			for b.Loop() {
+				if _, err := eng.Render(tmpl, context); err != nil {
+					b.Fatalf("Render() failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGoEngine_Render(b *testing.B) {
+	context := map[string]interface{}{"project_name": "bench"}
+	eng := NewGoEngine()
+
+	for b.Loop() {
+		if _, err := eng.Render("# {{ .project_name }}", context); err != nil {
+			b.Fatalf("Render() failed: %v", err)
+		}
+	}
+}
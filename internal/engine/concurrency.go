@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+// Pongo2 engines are safe for concurrent use, and parsed templates execute
+// in parallel. Two pieces of Pongo2 state are shared and need guarding:
+//
+//   - Template sets aren't safe for concurrent use, so setMu serializes
+//     parsing. An include whose file name is computed loads its template
+//     from the set while executing, so templates containing one, directly
+//     or in a partial they include, execute under setMu as well.
+//   - Autoescaping can only be set globally and is read as execution
+//     starts. Executions hold autoescapeMu for reading; it is only taken
+//     for writing when an engine with a different setting renders, which in
+//     a normal run happens once.
+var (
+	setMu sync.Mutex
+
+	autoescapeMu sync.RWMutex
+	// autoescape mirrors Pongo2's global setting, which defaults to on
+	autoescape = true
+)
+
+// lazyIncludePattern matches include tags whose file name isn't a string
+// literal, which Pongo2 resolves while executing
+var lazyIncludePattern = regexp.MustCompile(`\{%-?\s*include\s+[^"'\s]`)
+
+// loadScan records whether a template loaded through a scanningLoader since
+// the last reset contains a lazy include. It is guarded by setMu.
+type loadScan struct {
+	lazy bool
+}
+
+// scanningLoader wraps a template loader to look for lazy includes in the
+// partials a template pulls in while it is parsed
+type scanningLoader struct {
+	pongo2.TemplateLoader
+	scan *loadScan
+}
+
+func (l scanningLoader) Get(path string) (io.Reader, error) {
+	r, err := l.TemplateLoader.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if lazyIncludePattern.Match(data) {
+		l.scan.lazy = true
+	}
+	return bytes.NewReader(data), nil
+}
+
+// parse loads a template from the engine's set and reports whether it,
+// or a partial it includes, has a lazy include. source is the template's
+// text when it isn't loaded through the set's loaders. Templates on
+// Pongo2's shared default set are always reported lazy, since its loaders
+// can't be scanned.
+func (e *Pongo2Engine) parse(source string, load func(*pongo2.TemplateSet) (*pongo2.Template, error)) (*pongo2.Template, bool, error) {
+	setMu.Lock()
+	defer setMu.Unlock()
+
+	if e.scan == nil {
+		tpl, err := load(e.templateSet())
+		return tpl, true, err
+	}
+
+	e.scan.lazy = false
+	tpl, err := load(e.templateSet())
+	return tpl, e.scan.lazy || lazyIncludePattern.MatchString(source), err
+}
+
+// execute runs a parsed template with the engine's autoescape setting
+func (e *Pongo2Engine) execute(tpl *pongo2.Template, lazy bool, context map[string]interface{}) (string, error) {
+	release := useAutoescape(e.autoescape)
+	defer release()
+
+	if lazy {
+		setMu.Lock()
+		defer setMu.Unlock()
+	}
+	return tpl.Execute(pongo2.Context(context))
+}
+
+// useAutoescape switches Pongo2's global autoescaping to on if needed and
+// holds it there until the returned function is called
+func useAutoescape(on bool) (release func()) {
+	for {
+		autoescapeMu.RLock()
+		if autoescape == on {
+			return autoescapeMu.RUnlock
+		}
+		autoescapeMu.RUnlock()
+
+		autoescapeMu.Lock()
+		pongo2.SetAutoescape(on)
+		autoescape = on
+		autoescapeMu.Unlock()
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+func TestPongo2Engine_Concurrent(t *testing.T) {
+	root := t.TempDir()
+	partials := map[string]string{
+		"header.txt": "# {{ title }}\n",
+		// A partial with an include resolved while executing
+		"section.txt": "{% include part %}",
+		"body.txt":    "{{ body }}",
+	}
+	for name, content := range partials {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	plain := NewPongo2EngineWithOptions(Options{Root: root, TrimBlocks: true})
+	escaping := NewPongo2EngineWithOptions(Options{Root: root, Autoescape: true})
+	context := map[string]interface{}{"title": "A & B", "body": "<p>", "part": "body.txt"}
+
+	tests := []struct {
+		engine *Pongo2Engine
+		tmpl   string
+		want   string
+	}{
+		{plain, `{% include "header.txt" %}{% if title %}{{ title }}{% endif %}`, "# A & B\nA & B"},
+		{plain, `{% include part %}`, "<p>"},
+		{plain, `{% include "section.txt" %}`, "<p>"},
+		{escaping, `{{ title }}{% include part %}`, "A &amp; B&lt;p&gt;"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*len(tests))
+	for range 8 {
+		for _, tt := range tests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := tt.engine.RenderNamed("page.txt", tt.tmpl, context)
+				if err != nil {
+					errs <- fmt.Errorf("RenderNamed(%q) failed: %w", tt.tmpl, err)
+				} else if got != tt.want {
+					errs <- fmt.Errorf("RenderNamed(%q) = %q, want %q", tt.tmpl, got, tt.want)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPongo2Engine_ExecutesInParallel(t *testing.T) {
+	// The filter only returns once two renders are executing at the same
+	// time, so it times out if execution is serialized
+	arrived := make(chan struct{}, 2)
+	if err := pongo2.RegisterFilter("rendezvous", func(in *pongo2.Value, _ *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+		arrived <- struct{}{}
+		deadline := time.After(5 * time.Second)
+		for len(arrived) < 2 {
+			select {
+			case <-deadline:
+				return nil, &pongo2.Error{Sender: "filter:rendezvous", OrigError: fmt.Errorf("renders did not overlap")}
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return in, nil
+	}); err != nil {
+		t.Fatalf("RegisterFilter() failed: %v", err)
+	}
+
+	engine := NewPongo2EngineWithOptions(Options{})
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.Render("{{ name|rendezvous }}", map[string]interface{}{"name": "x"}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/flosch/pongo2/v6"
 )
@@ -10,32 +12,185 @@ import (
 type Engine interface {
 	Render(template string, context map[string]interface{}) (string, error)
 	RenderFile(filepath string, context map[string]interface{}) (string, error)
+	// RenderNamed renders a template string, naming it in errors
+	RenderNamed(name, template string, context map[string]interface{}) (string, error)
 }
 
-// Pongo2Engine implements Engine using Pongo2
-type Pongo2Engine struct{}
+// Supported engine names, as used in a template's `engine` setting
+const (
+	Pongo2 = "pongo2"
+	Go     = "go"
+)
+
+// Names lists the supported engine names
+var Names = []string{Pongo2, Go}
+
+// Options configure how an engine renders templates
+type Options struct {
+	// TrimBlocks removes the first newline after a block tag
+	TrimBlocks bool
+	// LStripBlocks strips spaces and tabs from the start of a line up to a block tag
+	LStripBlocks bool
+	// Autoescape HTML-escapes variable output. It is off by default since
+	// most scaffolded files aren't HTML.
+	Autoescape bool
+	// StrictUndefined makes rendering fail when a template uses a variable
+	// that isn't defined, instead of rendering it empty
+	StrictUndefined bool
+	// Root is the directory Pongo2 resolves `{% include %}`, `{% extends %}`
+	// and `{% import %}` paths against, usually the template directory
+	Root string
+	// IncludeDirs are searched, in order, for include paths not found under
+	// Root, so partials can be named without their directory
+	IncludeDirs []string
+}
+
+// New returns the engine with the given name. An empty name selects the
+// default Pongo2 engine. The Go engine has its own `{{- -}}` trim markers
+// and ignores the whitespace options.
+func New(name string, opts Options) (Engine, error) {
+	switch name {
+	case "", Pongo2:
+		return NewPongo2EngineWithOptions(opts), nil
+	case Go:
+		return &GoEngine{strict: opts.StrictUndefined}, nil
+	default:
+		return nil, fmt.Errorf("unknown engine %q (supported: %s)", name, strings.Join(Names, ", "))
+	}
+}
+
+// Name returns the name of a built-in engine, or the Go type of any other
+// Engine implementation
+func Name(e Engine) string {
+	switch e.(type) {
+	case *Pongo2Engine:
+		return Pongo2
+	case *GoEngine:
+		return Go
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+// Pongo2Engine implements Engine using Pongo2. It is safe for concurrent
+// use: parsing is serialized, and parsed templates execute in parallel.
+type Pongo2Engine struct {
+	set        *pongo2.TemplateSet
+	scan       *loadScan
+	strict     bool
+	autoescape bool
+}
 
 // NewPongo2Engine creates a new Pongo2 templating engine
 func NewPongo2Engine() *Pongo2Engine {
 	return &Pongo2Engine{}
 }
 
+// NewPongo2EngineWithOptions creates a Pongo2 engine with its own template set
+// configured from opts
+func NewPongo2EngineWithOptions(opts Options) *Pongo2Engine {
+	scan := &loadScan{}
+	loaders := includeLoaders(opts)
+	for i, loader := range loaders {
+		loaders[i] = scanningLoader{TemplateLoader: loader, scan: scan}
+	}
+
+	set := pongo2.NewSet("ason", loaders...)
+	set.Options.TrimBlocks = opts.TrimBlocks
+	set.Options.LStripBlocks = opts.LStripBlocks
+
+	return &Pongo2Engine{set: set, scan: scan, strict: opts.StrictUndefined, autoescape: opts.Autoescape}
+}
+
+// includeLoaders returns the loaders for Root and IncludeDirs. Directories
+// that can't be used are left out, so includes from them fail with their own
+// not-found errors.
+func includeLoaders(opts Options) []pongo2.TemplateLoader {
+	if opts.Root == "" && len(opts.IncludeDirs) == 0 {
+		return []pongo2.TemplateLoader{pongo2.DefaultLoader}
+	}
+
+	var loaders []pongo2.TemplateLoader
+	for _, dir := range append([]string{opts.Root}, opts.IncludeDirs...) {
+		if dir == "" {
+			continue
+		}
+		if loader, err := pongo2.NewLocalFileSystemLoader(dir); err == nil {
+			loaders = append(loaders, loader)
+		}
+	}
+	if len(loaders) == 0 {
+		return []pongo2.TemplateLoader{pongo2.DefaultLoader}
+	}
+	return loaders
+}
+
+// templateSet returns the engine's template set, defaulting to Pongo2's shared set
+func (e *Pongo2Engine) templateSet() *pongo2.TemplateSet {
+	if e.set == nil {
+		return pongo2.DefaultSet
+	}
+	return e.set
+}
+
 // Render renders a template string with the given context
 func (e *Pongo2Engine) Render(template string, context map[string]interface{}) (string, error) {
-	tpl, err := pongo2.FromString(template)
+	return e.RenderNamed("", template, context)
+}
+
+// RenderNamed renders a template string, reporting errors against name
+func (e *Pongo2Engine) RenderNamed(name, template string, context map[string]interface{}) (string, error) {
+	tpl, lazy, err := e.parse(template, func(set *pongo2.TemplateSet) (*pongo2.Template, error) {
+		return set.FromString(template)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return "", fmt.Errorf("failed to parse template: %w", pongo2Error(name, err))
+	}
+
+	if e.strict {
+		if err := e.checkDefined(name, template, context); err != nil {
+			return "", err
+		}
 	}
 
-	return tpl.Execute(pongo2.Context(context))
+	out, err := e.execute(tpl, lazy, context)
+	if err != nil {
+		return "", pongo2Error(name, err)
+	}
+	return out, nil
+}
+
+// checkDefined returns a RenderError for the first undefined variable the
+// template uses
+func (e *Pongo2Engine) checkDefined(name, template string, context map[string]interface{}) error {
+	if variable, line := undefinedVariable(template, context); variable != "" {
+		return &RenderError{Name: name, Line: line, Err: fmt.Errorf("undefined variable %q", variable)}
+	}
+	return nil
 }
 
 // RenderFile renders a template file with the given context
 func (e *Pongo2Engine) RenderFile(filepath string, context map[string]interface{}) (string, error) {
-	tpl, err := pongo2.FromFile(filepath)
+	tpl, lazy, err := e.parse("", func(set *pongo2.TemplateSet) (*pongo2.Template, error) {
+		return set.FromFile(filepath)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to load template file: %w", err)
+		return "", fmt.Errorf("failed to load template file: %w", pongo2Error(filepath, err))
 	}
 
-	return tpl.Execute(pongo2.Context(context))
+	if e.strict {
+		data, err := os.ReadFile(filepath)
+		if err != nil {
+			return "", fmt.Errorf("failed to load template file: %w", err)
+		}
+		if err := e.checkDefined(filepath, string(data), context); err != nil {
+			return "", err
+		}
+	}
+
+	out, err := e.execute(tpl, lazy, context)
+	if err != nil {
+		return "", pongo2Error(filepath, err)
+	}
+	return out, nil
 }
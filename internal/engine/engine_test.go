@@ -128,3 +128,147 @@ func TestPongo2Engine_RenderFile(t *testing.T) {
 		}
 	})
 }
+
+func TestPongo2Engine_WhitespaceOptions(t *testing.T) {
+	template := "start\n{% if x %}\n  yes\n{% endif %}\nend\n"
+	context := map[string]interface{}{"x": true}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "default keeps block newlines",
+			opts: Options{},
+			want: "start\n\n  yes\n\nend\n",
+		},
+		{
+			name: "trim blocks",
+			opts: Options{TrimBlocks: true},
+			want: "start\n  yes\nend\n",
+		},
+		{
+			name: "trim and lstrip blocks",
+			opts: Options{TrimBlocks: true, LStripBlocks: true},
+			want: "start\n  yes\nend\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPongo2EngineWithOptions(tt.opts).Render(template, context)
+			if err != nil {
+				t.Fatalf("Render() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Indented block tags are only stripped with LStripBlocks
+	indented := "a\n    {% if x %}b{% endif %}\n"
+	got, err := NewPongo2EngineWithOptions(Options{LStripBlocks: true}).Render(indented, context)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if got != "a\nb\n" {
+		t.Errorf("Render() with LStripBlocks = %q, want %q", got, "a\nb\n")
+	}
+}
+
+func TestPongo2Engine_Autoescape(t *testing.T) {
+	context := map[string]interface{}{"cmd": "a && b <c>"}
+
+	// Off by default, so shell scripts and code come out as written
+	for name, engine := range map[string]*Pongo2Engine{
+		"default set":  NewPongo2Engine(),
+		"with options": NewPongo2EngineWithOptions(Options{}),
+	} {
+		got, err := engine.Render("{{ cmd }}", context)
+		if err != nil {
+			t.Fatalf("%s: Render() failed: %v", name, err)
+		}
+		if got != "a && b <c>" {
+			t.Errorf("%s: Render() = %q, want it unescaped", name, got)
+		}
+	}
+
+	// HTML templates can opt in
+	got, err := NewPongo2EngineWithOptions(Options{Autoescape: true}).Render("{{ cmd }}", context)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if got != "a &amp;&amp; b &lt;c&gt;" {
+		t.Errorf("Render() with Autoescape = %q, want HTML-escaped output", got)
+	}
+}
+
+func TestPongo2Engine_IncludeFromRoot(t *testing.T) {
+	root := t.TempDir()
+	partials := filepath.Join(root, "_includes")
+	if err := os.MkdirAll(partials, 0755); err != nil {
+		t.Fatalf("Failed to create partials directory: %v", err)
+	}
+	files := map[string]string{
+		"header.txt": "# {{ title }}\n",
+		"base.txt":   "{% block body %}{% endblock %}--",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(partials, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	engine := NewPongo2EngineWithOptions(Options{Root: root})
+	context := map[string]interface{}{"title": "Demo"}
+
+	got, err := engine.RenderNamed("README.md", `{% include "_includes/header.txt" %}body`, context)
+	if err != nil {
+		t.Fatalf("RenderNamed() with include failed: %v", err)
+	}
+	if got != "# Demo\nbody" {
+		t.Errorf("RenderNamed() with include = %q, want %q", got, "# Demo\nbody")
+	}
+
+	got, err = engine.RenderNamed("page.txt", `{% extends "_includes/base.txt" %}{% block body %}{{ title }}{% endblock %}`, context)
+	if err != nil {
+		t.Fatalf("RenderNamed() with extends failed: %v", err)
+	}
+	if got != "Demo--" {
+		t.Errorf("RenderNamed() with extends = %q, want %q", got, "Demo--")
+	}
+}
+
+func TestPongo2Engine_IncludeDirs(t *testing.T) {
+	root := t.TempDir()
+	partials := filepath.Join(root, "_partials")
+	if err := os.MkdirAll(partials, 0755); err != nil {
+		t.Fatalf("Failed to create partials directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(partials, "footer.txt"), []byte("-- {{ title }}"), 0644); err != nil {
+		t.Fatalf("Failed to create footer.txt: %v", err)
+	}
+
+	engine := NewPongo2EngineWithOptions(Options{
+		Root:        root,
+		IncludeDirs: []string{partials, filepath.Join(root, "missing")},
+	})
+	context := map[string]interface{}{"title": "Demo"}
+
+	// Partials can be named with or without their directory
+	for _, tmpl := range []string{`{% include "footer.txt" %}`, `{% include "_partials/footer.txt" %}`} {
+		got, err := engine.RenderNamed("README.md", tmpl, context)
+		if err != nil {
+			t.Fatalf("RenderNamed(%q) failed: %v", tmpl, err)
+		}
+		if got != "-- Demo" {
+			t.Errorf("RenderNamed(%q) = %q, want %q", tmpl, got, "-- Demo")
+		}
+	}
+
+	if _, err := engine.RenderNamed("README.md", `{% include "nowhere.txt" %}`, context); err == nil {
+		t.Error("RenderNamed() should fail for a partial that doesn't exist")
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+// RenderError describes a template that failed to parse or execute, with
+// the position reported by the engine when it has one
+type RenderError struct {
+	// Name is the template's name or file path, when known
+	Name string
+	// Line and Column are 1-based, or 0 when unknown
+	Line   int
+	Column int
+	// Err is the underlying error
+	Err error
+}
+
+// Error formats the error as `name:line:column: message`, leaving out the
+// parts that are unknown
+func (e *RenderError) Error() string {
+	pos := e.Name
+	if e.Line > 0 {
+		if pos != "" {
+			pos += ":"
+		}
+		pos += fmt.Sprintf("%d", e.Line)
+		if e.Column > 0 {
+			pos += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+
+	if pos == "" {
+		return e.Err.Error()
+	}
+	return pos + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// pongo2Error converts a Pongo2 error into a RenderError carrying its position
+func pongo2Error(name string, err error) error {
+	var perr *pongo2.Error
+	if !errors.As(err, &perr) {
+		return err
+	}
+
+	if name == "" && perr.Filename != "" && perr.Filename != "<string>" {
+		name = perr.Filename
+	}
+
+	cause := perr.OrigError
+	if cause == nil {
+		cause = errors.New(perr.Error())
+	}
+
+	return &RenderError{
+		Name:   name,
+		Line:   perr.Line,
+		Column: perr.Column,
+		Err:    cause,
+	}
+}
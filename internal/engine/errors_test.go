@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPongo2Engine_RenderError(t *testing.T) {
+	engine := NewPongo2Engine()
+
+	_, err := engine.Render("first line\nsecond line {{ name", map[string]interface{}{})
+	if err == nil {
+		t.Fatal("Expected error for broken template, got nil")
+	}
+
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("Render() error = %T %v, want a *RenderError", err, err)
+	}
+	if renderErr.Line != 2 {
+		t.Errorf("RenderError.Line = %d, want 2", renderErr.Line)
+	}
+	if renderErr.Column == 0 {
+		t.Error("RenderError.Column should be set")
+	}
+	if !strings.Contains(err.Error(), "2:") {
+		t.Errorf("Error() = %q, want the line number in it", err.Error())
+	}
+}
+
+func TestPongo2Engine_RenderFileError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go.tmpl")
+	if err := os.WriteFile(path, []byte("package main\n\n{% if %}{% endif %}"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	_, err := NewPongo2Engine().RenderFile(path, map[string]interface{}{})
+
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("RenderFile() error = %T %v, want a *RenderError", err, err)
+	}
+	if renderErr.Line != 3 {
+		t.Errorf("RenderError.Line = %d, want 3", renderErr.Line)
+	}
+	if !strings.Contains(err.Error(), "main.go.tmpl:3:") {
+		t.Errorf("Error() = %q, want it to start with the file and line", err.Error())
+	}
+}
+
+func TestRenderError_Error(t *testing.T) {
+	cause := errors.New("unexpected token")
+
+	tests := []struct {
+		err  *RenderError
+		want string
+	}{
+		{&RenderError{Name: "main.go", Line: 12, Column: 4, Err: cause}, "main.go:12:4: unexpected token"},
+		{&RenderError{Name: "main.go", Line: 12, Err: cause}, "main.go:12: unexpected token"},
+		{&RenderError{Line: 3, Column: 1, Err: cause}, "3:1: unexpected token"},
+		{&RenderError{Name: "main.go", Err: cause}, "main.go: unexpected token"},
+		{&RenderError{Err: cause}, "unexpected token"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+		if !errors.Is(tt.err, cause) {
+			t.Errorf("errors.Is(%v, cause) = false, want true", tt.err)
+		}
+	}
+}
+
+func TestRenderNamed_NamesTemplate(t *testing.T) {
+	engines := map[string]Engine{
+		Pongo2: NewPongo2Engine(),
+		Go:     NewGoEngine(),
+	}
+
+	for name, engine := range engines {
+		t.Run(name, func(t *testing.T) {
+			_, err := engine.RenderNamed("docs/README.md", "ok\n{{ broken", map[string]interface{}{})
+			if err == nil {
+				t.Fatal("Expected error for broken template, got nil")
+			}
+			if !strings.Contains(err.Error(), "docs/README.md:2") {
+				t.Errorf("RenderNamed() error = %q, want it to name docs/README.md:2", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// goFuncs exposes the fake-data helpers to Go templates, e.g. `{{ uuid }}`
+var goFuncs = template.FuncMap{
+	"random_int":    RandomInt,
+	"random_string": RandomString,
+	"uuid":          UUID,
+}
+
+// GoEngine implements Engine using Go's text/template
+type GoEngine struct {
+	strict bool
+}
+
+// NewGoEngine creates a new Go text/template engine
+func NewGoEngine() *GoEngine {
+	return &GoEngine{}
+}
+
+// Render renders a template string with the given context
+func (e *GoEngine) Render(tmpl string, context map[string]interface{}) (string, error) {
+	return e.render("template", tmpl, context)
+}
+
+// RenderNamed renders a template string, reporting errors against name
+func (e *GoEngine) RenderNamed(name, tmpl string, context map[string]interface{}) (string, error) {
+	if name == "" {
+		name = "template"
+	}
+	return e.render(name, tmpl, context)
+}
+
+// RenderFile renders a template file with the given context
+func (e *GoEngine) RenderFile(filepath string, context map[string]interface{}) (string, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load template file: %w", err)
+	}
+
+	return e.render(filepath, string(data), context)
+}
+
+func (e *GoEngine) render(name, tmpl string, context map[string]interface{}) (string, error) {
+	tpl := template.New(name).Funcs(goFuncs)
+	if e.strict {
+		tpl = tpl.Option("missingkey=error")
+	}
+
+	tpl, err := tpl.Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, context); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoEngine_Render(t *testing.T) {
+	engine := NewGoEngine()
+
+	tests := []struct {
+		name     string
+		template string
+		context  map[string]interface{}
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "simple template",
+			template: "Hello {{ .name }}!",
+			context:  map[string]interface{}{"name": "World"},
+			want:     "Hello World!",
+		},
+		{
+			name:     "template with range",
+			template: "{{ range .items }}{{ . }}{{ end }}",
+			context:  map[string]interface{}{"items": []string{"a", "b", "c"}},
+			want:     "abc",
+		},
+		{
+			name:     "invalid template syntax",
+			template: "Hello {{ .name",
+			context:  map[string]interface{}{"name": "World"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Render(tt.template, tt.context)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GoEngine.Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GoEngine.Render() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoEngine_RenderFile(t *testing.T) {
+	engine := NewGoEngine()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.tmpl")
+	if err := os.WriteFile(tmpFile, []byte("{{ if .show }}Visible{{ else }}Hidden{{ end }}"), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	got, err := engine.RenderFile(tmpFile, map[string]interface{}{"show": true})
+	if err != nil {
+		t.Fatalf("GoEngine.RenderFile() failed: %v", err)
+	}
+	if got != "Visible" {
+		t.Errorf("GoEngine.RenderFile() = %v, want %v", got, "Visible")
+	}
+
+	if _, err := engine.RenderFile("/non/existent/file.tmpl", nil); err == nil {
+		t.Error("Expected error for non-existent file, got nil")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "", want: &Pongo2Engine{}},
+		{name: Pongo2, want: &Pongo2Engine{}},
+		{name: Go, want: &GoEngine{}},
+		{name: "jinja", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.name, Options{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			switch tt.want.(type) {
+			case *Pongo2Engine:
+				if _, ok := got.(*Pongo2Engine); !ok {
+					t.Errorf("New(%q) = %T, want *Pongo2Engine", tt.name, got)
+				}
+			case *GoEngine:
+				if _, ok := got.(*GoEngine); !ok {
+					t.Errorf("New(%q) = %T, want *GoEngine", tt.name, got)
+				}
+			}
+			want := tt.name
+			if want == "" {
+				want = Pongo2
+			}
+			if Name(got) != want {
+				t.Errorf("Name(New(%q)) = %q, want %q", tt.name, Name(got), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+const (
+	defaultRandomIntMax    = 100
+	defaultRandomStringLen = 16
+	randomStringAlphabet   = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// rng is the source behind the fake-data helpers. It is time-seeded unless
+// Seed is called, so generation is only reproducible with an explicit seed.
+var (
+	rngMu sync.Mutex
+	rng   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func init() {
+	pongo2.RegisterFilter("random_int", filterRandomInt)
+	pongo2.RegisterFilter("random_string", filterRandomString)
+	pongo2.RegisterFilter("uuid", filterUUID)
+}
+
+// Seed reseeds the source used by the random_int, random_string and uuid
+// helpers so that generation produces the same values for the same seed
+func Seed(seed int64) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rng = rand.New(rand.NewSource(seed))
+}
+
+// RandomInt returns a random integer in [0, max). A max below 1 uses 100.
+func RandomInt(max int) int {
+	if max < 1 {
+		max = defaultRandomIntMax
+	}
+
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	return rng.Intn(max)
+}
+
+// RandomString returns a random lowercase alphanumeric string of length n.
+// A length below 1 uses 16.
+func RandomString(n int) string {
+	if n < 1 {
+		n = defaultRandomStringLen
+	}
+
+	rngMu.Lock()
+	defer rngMu.Unlock()
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randomStringAlphabet[rng.Intn(len(randomStringAlphabet))]
+	}
+	return string(b)
+}
+
+// UUID returns a random version 4 UUID
+func UUID() string {
+	rngMu.Lock()
+	var b [16]byte
+	rng.Read(b[:])
+	rngMu.Unlock()
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// filterRandomInt implements `{{ 10|random_int }}`, a number below the input
+func filterRandomInt(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	return pongo2.AsValue(RandomInt(in.Integer())), nil
+}
+
+// filterRandomString implements `{{ 8|random_string }}`, a string of the input length
+func filterRandomString(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	return pongo2.AsValue(RandomString(in.Integer())), nil
+}
+
+// filterUUID implements `{{ ""|uuid }}`; the input is ignored
+func filterUUID(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
+	return pongo2.AsValue(UUID()), nil
+}
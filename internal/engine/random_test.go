@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"regexp"
+	"testing"
+)
+
+const randomTemplate = `{{ ""|uuid }} {{ 12|random_string }} {{ 1000|random_int }}`
+
+func TestSeed_Reproducible(t *testing.T) {
+	engine := NewPongo2Engine()
+
+	render := func(seed int64) string {
+		t.Helper()
+		Seed(seed)
+		got, err := engine.Render(randomTemplate, map[string]interface{}{})
+		if err != nil {
+			t.Fatalf("Render() failed: %v", err)
+		}
+		return got
+	}
+
+	first := render(42)
+	if second := render(42); second != first {
+		t.Errorf("Same seed rendered %q and %q, want identical output", first, second)
+	}
+	if other := render(7); other == first {
+		t.Errorf("Different seeds both rendered %q, want different output", first)
+	}
+}
+
+func TestSeed_GoEngine(t *testing.T) {
+	engine := NewGoEngine()
+	tmpl := `{{ uuid }} {{ random_string 8 }} {{ random_int 50 }}`
+
+	Seed(1)
+	first, err := engine.Render(tmpl, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	Seed(1)
+	second, err := engine.Render(tmpl, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("Same seed rendered %q and %q, want identical output", first, second)
+	}
+}
+
+func TestUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	for i := 0; i < 10; i++ {
+		if id := UUID(); !pattern.MatchString(id) {
+			t.Errorf("UUID() = %q, want a version 4 UUID", id)
+		}
+	}
+}
+
+func TestRandomString(t *testing.T) {
+	if got := RandomString(8); len(got) != 8 {
+		t.Errorf("RandomString(8) length = %d, want 8", len(got))
+	}
+	if got := RandomString(0); len(got) != defaultRandomStringLen {
+		t.Errorf("RandomString(0) length = %d, want %d", len(got), defaultRandomStringLen)
+	}
+	if got := RandomString(32); !regexp.MustCompile(`^[a-z0-9]+$`).MatchString(got) {
+		t.Errorf("RandomString(32) = %q, want lowercase alphanumerics", got)
+	}
+}
+
+func TestRandomInt(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if got := RandomInt(5); got < 0 || got >= 5 {
+			t.Fatalf("RandomInt(5) = %d, want 0 <= n < 5", got)
+		}
+		if got := RandomInt(0); got < 0 || got >= defaultRandomIntMax {
+			t.Fatalf("RandomInt(0) = %d, want 0 <= n < %d", got, defaultRandomIntMax)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Strict-undefined checking for Pongo2, which has no hook for variable
+// lookups. Templates are scanned for the variables used in `{{ }}` output
+// tags; names bound by for, with, set and macro tags anywhere in the
+// template are treated as defined, as are expressions using the default
+// filters. The check errs on the side of accepting templates.
+var (
+	outputTagPattern  = regexp.MustCompile(`(?s)\{\{-?(.*?)-?\}\}`)
+	stringPattern     = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	identPattern      = regexp.MustCompile(`(^|[^.\w])([A-Za-z_]\w*)`)
+	forBindPattern    = regexp.MustCompile(`\{%-?\s*for\s+([\w\s,]+?)\s+in\s`)
+	withBindPattern   = regexp.MustCompile(`(?s)\{%-?\s*with\s+(.*?)-?%\}`)
+	setBindPattern    = regexp.MustCompile(`\{%-?\s*set\s+(\w+)\s*=`)
+	macroBindPattern  = regexp.MustCompile(`\{%-?\s*macro\s+(\w+)\s*\(([^)]*)\)`)
+	assignNamePattern = regexp.MustCompile(`(\w+)\s*=`)
+)
+
+// keywords are identifiers in expressions that are never variables
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true,
+	"true": true, "false": true, "True": true, "False": true,
+	"none": true, "None": true, "nil": true, "forloop": true,
+}
+
+// undefinedVariable returns the first variable used in a template's output
+// tags that is neither in context nor bound by the template, and the line it
+// is used on. It returns an empty name when there is none.
+func undefinedVariable(tmpl string, context map[string]interface{}) (string, int) {
+	bound := boundNames(tmpl)
+
+	for _, loc := range outputTagPattern.FindAllStringSubmatchIndex(tmpl, -1) {
+		expr := stringPattern.ReplaceAllString(tmpl[loc[2]:loc[3]], `""`)
+
+		// Filters come after the first pipe; a default makes the variable optional
+		parts := strings.Split(expr, "|")
+		if hasDefaultFilter(parts[1:]) {
+			continue
+		}
+
+		for _, m := range identPattern.FindAllStringSubmatch(parts[0], -1) {
+			name := m[2]
+			if keywords[name] || bound[name] {
+				continue
+			}
+			if _, ok := context[name]; !ok {
+				return name, strings.Count(tmpl[:loc[0]], "\n") + 1
+			}
+		}
+	}
+
+	return "", 0
+}
+
+// hasDefaultFilter reports whether a filter chain supplies a default value
+func hasDefaultFilter(filters []string) bool {
+	for _, filter := range filters {
+		name, _, _ := strings.Cut(strings.TrimSpace(filter), ":")
+		if name == "default" || name == "default_if_none" {
+			return true
+		}
+	}
+	return false
+}
+
+// boundNames collects the names a template defines for itself
+func boundNames(tmpl string) map[string]bool {
+	bound := make(map[string]bool)
+
+	for _, m := range forBindPattern.FindAllStringSubmatch(tmpl, -1) {
+		for _, name := range strings.Split(m[1], ",") {
+			bound[strings.TrimSpace(name)] = true
+		}
+	}
+	for _, m := range withBindPattern.FindAllStringSubmatch(tmpl, -1) {
+		for _, a := range assignNamePattern.FindAllStringSubmatch(m[1], -1) {
+			bound[a[1]] = true
+		}
+		// The older `{% with value as name %}` form
+		if fields := strings.Fields(m[1]); len(fields) == 3 && fields[1] == "as" {
+			bound[fields[2]] = true
+		}
+	}
+	for _, m := range setBindPattern.FindAllStringSubmatch(tmpl, -1) {
+		bound[m[1]] = true
+	}
+	for _, m := range macroBindPattern.FindAllStringSubmatch(tmpl, -1) {
+		bound[m[1]] = true
+		for _, arg := range strings.Split(m[2], ",") {
+			name, _, _ := strings.Cut(arg, "=")
+			bound[strings.TrimSpace(name)] = true
+		}
+	}
+
+	return bound
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPongo2Engine_StrictUndefined(t *testing.T) {
+	lenient := NewPongo2EngineWithOptions(Options{})
+	strict := NewPongo2EngineWithOptions(Options{StrictUndefined: true})
+
+	// Default mode keeps rendering missing variables empty
+	got, err := lenient.Render("Hello {{ name }}!", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if got != "Hello !" {
+		t.Errorf("Render() = %q, want %q", got, "Hello !")
+	}
+
+	// Strict mode names the missing variable and its line
+	_, err = strict.RenderNamed("README.md", "# Title\nHello {{ name|upper }}!", map[string]interface{}{})
+	if err == nil {
+		t.Fatal("Expected error for undefined variable in strict mode, got nil")
+	}
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) || renderErr.Line != 2 {
+		t.Errorf("Render() error = %v, want a RenderError on line 2", err)
+	}
+	if !strings.Contains(err.Error(), `"name"`) {
+		t.Errorf("Render() error = %q, want it to name the variable", err.Error())
+	}
+
+	// Defined variables render as usual
+	got, err = strict.Render("Hello {{ name }}!", map[string]interface{}{"name": "World"})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if got != "Hello World!" {
+		t.Errorf("Render() = %q, want %q", got, "Hello World!")
+	}
+}
+
+func TestUndefinedVariable(t *testing.T) {
+	context := map[string]interface{}{"items": []string{"a"}, "user": map[string]string{"name": "x"}}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"plain variable", "{{ missing }}", "missing"},
+		{"attribute of defined variable", "{{ user.name }}", ""},
+		{"attribute of missing variable", "{{ account.name }}", "account"},
+		{"loop variable", "{% for item in items %}{{ item }}{{ forloop.Counter }}{% endfor %}", ""},
+		{"loop key and value", "{% for k, v in items %}{{ k }}={{ v }}{% endfor %}", ""},
+		{"with binding", "{% with greeting=\"hi\" %}{{ greeting }}{% endwith %}", ""},
+		{"set binding", "{% set x = 1 %}{{ x }}", ""},
+		{"macro arguments", "{% macro field(label, size=3) %}{{ label }}{{ size }}{% endmacro %}", ""},
+		{"default filter", `{{ missing|default:"x" }}`, ""},
+		{"string literal", `{{ "missing" }}`, ""},
+		{"keywords", "{{ true and not false }}", ""},
+		{"filter argument is ignored", `{{ items|join:sep }}`, ""},
+		{"operand before filter", "{{ count + 1|add:2 }}", "count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := undefinedVariable(tt.template, context); got != tt.want {
+				t.Errorf("undefinedVariable(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoEngine_StrictUndefined(t *testing.T) {
+	strict, err := New(Go, Options{StrictUndefined: true})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	if _, err := strict.Render("Hello {{ .name }}!", map[string]interface{}{}); err == nil {
+		t.Error("Expected error for undefined variable in strict mode, got nil")
+	}
+
+	got, err := NewGoEngine().Render("Hello {{ .name }}!", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if got != "Hello <no value>!" {
+		t.Errorf("Render() = %q, want %q", got, "Hello <no value>!")
+	}
+}
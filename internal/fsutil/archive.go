@@ -0,0 +1,187 @@
+package fsutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveExtensions are the archive formats Extract reads
+var ArchiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// maxExtractSize bounds the bytes Extract writes, so a small archive can't
+// fill the disk
+var maxExtractSize int64 = 1 << 30
+
+// IsArchive reports whether path names an archive Extract reads, by its
+// extension
+func IsArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range ArchiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Extract unpacks the archive at path into dst, which is created if needed.
+// Entries that would land outside dst, links and special files are refused,
+// so an archive can only write below dst. Files keep their permission bits.
+func Extract(path, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		err = extractZip(path, dst)
+	} else {
+		err = extractTarGz(path, dst)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", path, err)
+	}
+	return nil
+}
+
+// extractTarGz unpacks a gzip-compressed tar archive
+func extractTarGz(path, dst string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	limit := &sizeLimit{remaining: maxExtractSize}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeXGlobalHeader:
+			// Metadata such as the commit git archive records
+			continue
+		case tar.TypeDir:
+			target, err := extractPath(dst, hdr.Name)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			target, err := extractPath(dst, hdr.Name)
+			if err != nil {
+				return err
+			}
+			if err := writeEntry(target, tr, hdr.FileInfo().Mode(), limit); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("refusing %s: only files and directories can be extracted", hdr.Name)
+		}
+	}
+}
+
+// extractZip unpacks a zip archive
+func extractZip(path, dst string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	limit := &sizeLimit{remaining: maxExtractSize}
+	for _, f := range zr.File {
+		target, err := extractPath(dst, f.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeEntry(target, rc, mode, limit)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("refusing %s: only files and directories can be extracted", f.Name)
+		}
+	}
+	return nil
+}
+
+// extractPath returns where the archive entry name goes under dst, refusing
+// absolute names and names that climb out of dst
+func extractPath(dst, name string) (string, error) {
+	rel := filepath.FromSlash(strings.TrimSuffix(name, "/"))
+	if rel == "" || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("refusing %s: it would be extracted outside the destination", name)
+	}
+	return filepath.Join(dst, rel), nil
+}
+
+// writeEntry writes the contents of one archive file to target
+func writeEntry(target string, r io.Reader, mode fs.FileMode, limit *sizeLimit) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, io.LimitReader(r, limit.remaining+1)); err != nil {
+		file.Close()
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	limit.remaining -= info.Size()
+	if limit.remaining < 0 {
+		return fmt.Errorf("archive expands to more than %d bytes", maxExtractSize)
+	}
+	return nil
+}
+
+// sizeLimit tracks how many more bytes an extraction may write
+type sizeLimit struct {
+	remaining int64
+}
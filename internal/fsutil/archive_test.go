@@ -0,0 +1,173 @@
+package fsutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveEntry is one entry of a test archive; a name ending in / is a
+// directory
+type archiveEntry struct {
+	name    string
+	content string
+	mode    int64
+	link    string
+}
+
+// writeTarGz creates a .tar.gz at path holding entries
+func writeTarGz(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		case strings.HasSuffix(e.name, "/"):
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", e.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip: %v", err)
+	}
+}
+
+// writeZip creates a .zip at path holding entries
+func writeZip(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name}
+		hdr.SetMode(os.FileMode(e.mode))
+		if strings.HasSuffix(e.name, "/") {
+			hdr.SetMode(os.ModeDir | 0755)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", e.name, err)
+		}
+		if _, err := w.Write([]byte(e.content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", e.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	tests := map[string]bool{
+		"template.tar.gz":  true,
+		"template.TGZ":     true,
+		"dir/template.zip": true,
+		"template.tar":     false,
+		"template":         false,
+	}
+	for path, want := range tests {
+		if got := IsArchive(path); got != want {
+			t.Errorf("IsArchive(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "demo/", mode: 0755},
+		{name: "demo/README.md", content: "# {{ name }}", mode: 0644},
+		{name: "demo/scripts/run.sh", content: "#!/bin/sh", mode: 0755},
+	}
+	writers := map[string]func(*testing.T, string, []archiveEntry){
+		"template.tar.gz": writeTarGz,
+		"template.zip":    writeZip,
+	}
+
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), name)
+			write(t, archive, entries)
+
+			dst := filepath.Join(t.TempDir(), "out")
+			if err := Extract(archive, dst); err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dst, "demo", "README.md"))
+			if err != nil || string(content) != "# {{ name }}" {
+				t.Errorf("README.md = %q, %v", content, err)
+			}
+			info, err := os.Stat(filepath.Join(dst, "demo", "scripts", "run.sh"))
+			if err != nil {
+				t.Fatalf("run.sh should be extracted: %v", err)
+			}
+			if info.Mode().Perm() != 0755 {
+				t.Errorf("run.sh mode = %v, want 0755", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestExtract_Refused(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+	}{
+		{"parent directory", []archiveEntry{{name: "../evil.txt", content: "x", mode: 0644}}},
+		{"nested parent directory", []archiveEntry{{name: "demo/../../evil.txt", content: "x", mode: 0644}}},
+		{"absolute path", []archiveEntry{{name: "/tmp/evil.txt", content: "x", mode: 0644}}},
+		{"symlink", []archiveEntry{{name: "passwd", link: "/etc/passwd", mode: 0777}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "evil.tar.gz")
+			writeTarGz(t, archive, tt.entries)
+
+			dst := filepath.Join(dir, "out")
+			if err := Extract(archive, dst); err == nil {
+				t.Error("Extract() should refuse the archive")
+			}
+			if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+				t.Error("Extract() wrote outside the destination")
+			}
+		})
+	}
+}
+
+func TestExtract_SizeLimit(t *testing.T) {
+	original := maxExtractSize
+	maxExtractSize = 8
+	defer func() { maxExtractSize = original }()
+
+	archive := filepath.Join(t.TempDir(), "big.zip")
+	writeZip(t, archive, []archiveEntry{{name: "big.txt", content: strings.Repeat("x", 100), mode: 0644}})
+
+	if err := Extract(archive, t.TempDir()); err == nil || !strings.Contains(err.Error(), "more than 8 bytes") {
+		t.Errorf("Extract() error = %v, want the size limit error", err)
+	}
+}
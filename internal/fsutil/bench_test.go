@@ -0,0 +1,33 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// BenchmarkCopyTree copies a tree of medium-sized files. Allocations stay
+// flat in the file size since io.Copy hands file-to-file copies to the
+// kernel.
+func BenchmarkCopyTree(b *testing.B) {
+	for _, size := range []int{64 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("size=%dKiB", size>>10), func(b *testing.B) {
+			src := b.TempDir()
+			content := make([]byte, size)
+			for i := range 50 {
+				if err := os.WriteFile(filepath.Join(src, fmt.Sprintf("file%02d.bin", i)), content, 0644); err != nil {
+					b.Fatalf("Failed to create file: %v", err)
+				}
+			}
+
+			b.ReportAllocs()
+			b.SetBytes(int64(50 * size))
+			for b.Loop() {
+				if _, err := CopyTree(src, filepath.Join(b.TempDir(), "copy"), Policy{}); err != nil {
+					b.Fatalf("CopyTree() failed: %v", err)
+				}
+			}
+		})
+	}
+}
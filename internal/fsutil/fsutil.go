@@ -0,0 +1,35 @@
+package fsutil
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// CopyFile copies src to dst, creating or truncating dst, and gives dst the
+// permission bits of mode regardless of the umask. io.Copy lets the kernel
+// copy between the files without a user-space buffer where it can.
+func CopyFile(src, dst string, mode fs.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	// An existing dst keeps its old mode, and new files are masked
+	if err := dstFile.Chmod(mode.Perm()); err != nil {
+		dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
@@ -0,0 +1,78 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(src, []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		existing bool
+		mode     os.FileMode
+	}{
+		{"executable", false, 0755},
+		{"read-only", false, 0444},
+		{"replaces an existing file and its mode", true, 0750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "copy.sh")
+			if tt.existing {
+				if err := os.WriteFile(dst, []byte("old content that is longer than the new one"), 0600); err != nil {
+					t.Fatalf("Failed to create existing file: %v", err)
+				}
+			}
+
+			if err := CopyFile(src, dst, tt.mode); err != nil {
+				t.Fatalf("CopyFile() failed: %v", err)
+			}
+
+			content, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatalf("Failed to read copy: %v", err)
+			}
+			if string(content) != "#!/bin/sh\necho hi\n" {
+				t.Errorf("copy content = %q", content)
+			}
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatalf("Failed to stat copy: %v", err)
+			}
+			if info.Mode().Perm() != tt.mode {
+				t.Errorf("copy mode = %v, want %v", info.Mode().Perm(), tt.mode)
+			}
+		})
+	}
+}
+
+func TestCopyFile_UnreadableSource(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := CopyFile(filepath.Join(dir, "missing"), filepath.Join(dir, "copy"), 0644); !os.IsNotExist(err) {
+		t.Errorf("CopyFile() of a missing source error = %v, want not-exist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "copy")); !os.IsNotExist(err) {
+		t.Error("no destination should be created for a missing source")
+	}
+
+	src := filepath.Join(dir, "secret")
+	if err := os.WriteFile(src, []byte("secret"), 0000); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if f, err := os.Open(src); err == nil {
+		f.Close()
+		t.Skip("file permissions aren't enforced for this user")
+	}
+	if err := CopyFile(src, filepath.Join(dir, "copy"), 0644); !os.IsPermission(err) {
+		t.Errorf("CopyFile() of an unreadable source error = %v, want a permission error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/glob"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// Policy decides which paths of a template tree are left out. The zero
+// Policy keeps everything.
+type Policy struct {
+	// KeepHidden reports whether a hidden file or directory name is kept;
+	// nil keeps them all
+	KeepHidden func(name string) bool
+
+	// Ignore lists globs of root-relative paths to leave out
+	Ignore []string
+
+	// Keep lists root-relative paths never left out by Ignore, such as the
+	// template config
+	Keep []string
+
+	// OnIgnore, when set, is called for each path left out by Ignore
+	OnIgnore func(relPath string, info fs.FileInfo)
+}
+
+// WalkFunc is called by Walk for each path kept, with its path relative to
+// the root. Returning filepath.SkipDir from a directory skips its contents.
+type WalkFunc func(path, relPath string, info fs.FileInfo) error
+
+// Walk calls fn for every path below root that the policy keeps, in lexical
+// order; it isn't called for root itself. Directories left out are skipped
+// as a whole. It returns the hidden paths left out, apart from ones such as
+// .git that are never wanted.
+func Walk(root string, policy Policy, fn WalkFunc) ([]string, error) {
+	var skippedHidden []string
+	err := filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if name := info.Name(); policy.KeepHidden != nil && template.IsHidden(name) && !policy.KeepHidden(name) {
+			if !template.AlwaysSkipped(name) {
+				skippedHidden = append(skippedHidden, relPath)
+			}
+			return skip(info)
+		}
+
+		if glob.MatchAny(policy.Ignore, relPath) && !slices.Contains(policy.Keep, filepath.ToSlash(relPath)) {
+			if policy.OnIgnore != nil {
+				policy.OnIgnore(relPath, info)
+			}
+			return skip(info)
+		}
+
+		return fn(path, relPath, info)
+	})
+	return skippedHidden, err
+}
+
+// skip leaves out a path from filepath.Walk, with its contents if it is a
+// directory
+func skip(info fs.FileInfo) error {
+	if info.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// CopyTree copies the tree at src to dst, leaving out what the policy skips
+// and keeping file and directory modes. It returns the hidden paths left
+// out as Walk does.
+func CopyTree(src, dst string, policy Policy) ([]string, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	return Walk(src, policy, func(path, relPath string, info fs.FileInfo) error {
+		dstPath := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(dstPath, info.Mode().Perm())
+		}
+		return CopyFile(path, dstPath, info.Mode())
+	})
+}
+
+// Stats describes the files of a tree
+type Stats struct {
+	Size     int64
+	Files    int
+	Modified time.Time
+}
+
+// Analyze returns the total size, file count and latest modification time of
+// the files in the tree at root that the policy keeps
+func Analyze(root string, policy Policy) (Stats, error) {
+	var stats Stats
+	_, err := Walk(root, policy, func(path, relPath string, info fs.FileInfo) error {
+		if info.IsDir() {
+			return nil
+		}
+		stats.Size += info.Size()
+		stats.Files++
+		if info.ModTime().After(stats.Modified) {
+			stats.Modified = info.ModTime()
+		}
+		return nil
+	})
+	return stats, err
+}
@@ -0,0 +1,203 @@
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// writeTree creates files under a new temporary directory
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+var treeFiles = map[string]string{
+	"ason.toml":                "ignore = []",
+	"README.md":                "# readme",
+	".env.example":             "KEY=",
+	".private/notes.txt":       "notes",
+	".git/HEAD":                "ref: refs/heads/main",
+	".github/workflows/ci.yml": "name: ci",
+	"logs/app.log":             "log",
+	"src/main.go":              "package main",
+}
+
+func TestWalk(t *testing.T) {
+	root := writeTree(t, treeFiles)
+	var nilConfig *template.Config
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		want        []string
+		wantHidden  []string
+		wantIgnored []string
+	}{
+		{
+			name: "zero policy keeps everything",
+			want: []string{
+				".env.example", ".git", ".git/HEAD", ".github", ".github/workflows", ".github/workflows/ci.yml",
+				".private", ".private/notes.txt", "README.md", "ason.toml", "logs", "logs/app.log", "src", "src/main.go",
+			},
+		},
+		{
+			name:       "hidden policy",
+			policy:     Policy{KeepHidden: nilConfig.KeepHidden},
+			want:       []string{".env.example", ".github", ".github/workflows", ".github/workflows/ci.yml", "README.md", "ason.toml", "logs", "logs/app.log", "src", "src/main.go"},
+			wantHidden: []string{".private"},
+		},
+		{
+			name:        "ignore patterns skip directories as a whole",
+			policy:      Policy{Ignore: []string{"logs/**", "*.{md,toml}"}, Keep: template.ConfigFileNames},
+			want:        []string{".env.example", ".git", ".git/HEAD", ".github", ".github/workflows", ".github/workflows/ci.yml", ".private", ".private/notes.txt", "ason.toml", "src", "src/main.go"},
+			wantIgnored: []string{"README.md", "logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ignored []string
+			tt.policy.OnIgnore = func(relPath string, _ fs.FileInfo) {
+				ignored = append(ignored, filepath.ToSlash(relPath))
+			}
+
+			var got []string
+			hidden, err := Walk(root, tt.policy, func(path, relPath string, info fs.FileInfo) error {
+				if path != filepath.Join(root, relPath) {
+					t.Errorf("path %s doesn't match relative path %s", path, relPath)
+				}
+				got = append(got, filepath.ToSlash(relPath))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visited %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(hidden, tt.wantHidden) {
+				t.Errorf("skipped hidden %v, want %v", hidden, tt.wantHidden)
+			}
+			if !reflect.DeepEqual(ignored, tt.wantIgnored) {
+				t.Errorf("ignored %v, want %v", ignored, tt.wantIgnored)
+			}
+		})
+	}
+}
+
+func TestWalk_SkipDir(t *testing.T) {
+	root := writeTree(t, treeFiles)
+
+	var got []string
+	_, err := Walk(root, Policy{Ignore: []string{".*"}}, func(path, relPath string, info fs.FileInfo) error {
+		got = append(got, filepath.ToSlash(relPath))
+		if info.IsDir() && relPath == "src" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() failed: %v", err)
+	}
+	if want := []string{"README.md", "ason.toml", "logs", "logs/app.log", "src"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visited %v, want %v", got, want)
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	root := writeTree(t, treeFiles)
+	if err := os.Chmod(filepath.Join(root, "src", "main.go"), 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0700); err != nil {
+		t.Fatalf("Failed to create empty: %v", err)
+	}
+
+	var nilConfig *template.Config
+	dst := filepath.Join(t.TempDir(), "copy")
+	hidden, err := CopyTree(root, dst, Policy{KeepHidden: nilConfig.KeepHidden, Ignore: []string{"**/*.log"}})
+	if err != nil {
+		t.Fatalf("CopyTree() failed: %v", err)
+	}
+	if !reflect.DeepEqual(hidden, []string{".private"}) {
+		t.Errorf("skipped hidden %v, want [.private]", hidden)
+	}
+
+	for _, name := range []string{"README.md", ".env.example", ".github/workflows/ci.yml", "logs", "empty"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("%s should be copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{".git", ".private", "logs/app.log"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be copied", name)
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(dst, "src", "main.go")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("src/main.go should keep mode 0755: %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "empty")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("empty should keep mode 0700: %v, %v", info, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "README.md")); string(content) != "# readme" {
+		t.Errorf("README.md = %q", content)
+	}
+}
+
+func TestCopyTree_MissingSource(t *testing.T) {
+	if _, err := CopyTree(filepath.Join(t.TempDir(), "missing"), t.TempDir(), Policy{}); !os.IsNotExist(err) {
+		t.Errorf("CopyTree() error = %v, want not-exist", err)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a.txt":       "12345",
+		"dir/b.txt":   "123",
+		".hidden/c":   "1234567",
+		"skip/d.log":  "12",
+		"dir/e/f.txt": "1",
+	})
+	newest := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(root, "dir", "b.txt"), newest, newest); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	var nilConfig *template.Config
+	stats, err := Analyze(root, Policy{KeepHidden: nilConfig.KeepHidden, Ignore: []string{"skip"}})
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+	if stats.Files != 3 || stats.Size != 9 {
+		t.Errorf("Analyze() = %d files, %d bytes, want 3 files, 9 bytes", stats.Files, stats.Size)
+	}
+	if !stats.Modified.Equal(newest) {
+		t.Errorf("Modified = %v, want %v", stats.Modified, newest)
+	}
+
+	all, err := Analyze(root, Policy{})
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+	if all.Files != 5 || all.Size != 18 {
+		t.Errorf("Analyze() with the zero policy = %d files, %d bytes, want 5 files, 18 bytes", all.Files, all.Size)
+	}
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/madstone-tech/ason/internal/engine"
+)
+
+// writeBenchTemplate creates a template of small rendered files spread over
+// ten directories
+func writeBenchTemplate(b *testing.B, files int) string {
+	b.Helper()
+	dir := b.TempDir()
+	for i := range files {
+		path := filepath.Join(dir, fmt.Sprintf("dir%d", i%10), fmt.Sprintf("file%04d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatalf("Failed to create directory: %v", err)
+		}
+		content := fmt.Sprintf("# {{ project_name }}\n\nFile %d by {{ author|default:\"nobody\" }}\n", i)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			b.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	return dir
+}
+
+func BenchmarkGenerator_Generate(b *testing.B) {
+	context := map[string]interface{}{"project_name": "bench", "author": "ason"}
+
+	for _, files := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("files=%d", files), func(b *testing.B) {
+			tmpl := &Template{Path: writeBenchTemplate(b, files)}
+			opts := Options{Log: io.Discard}
+
+			for b.Loop() {
+				opts.Sink = NewInMemorySink()
+				if err := New(tmpl, engine.NewPongo2Engine()).Generate(b.Context(), "", context, opts); err != nil {
+					b.Fatalf("Generate() failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGenerator_Generate_Filesystem(b *testing.B) {
+	context := map[string]interface{}{"project_name": "bench", "author": "ason"}
+	tmpl := &Template{Path: writeBenchTemplate(b, 1000)}
+	opts := Options{Log: io.Discard}
+
+	for b.Loop() {
+		outputDir := filepath.Join(b.TempDir(), "out")
+		if err := New(tmpl, engine.NewPongo2Engine()).Generate(b.Context(), outputDir, context, opts); err != nil {
+			b.Fatalf("Generate() failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkGenerator_Generate_Jobs compares rendering on one worker with
+// several; Pongo2 templates execute in parallel, so jobs=8 should be faster
+// on a multi-core machine
+func BenchmarkGenerator_Generate_Jobs(b *testing.B) {
+	context := map[string]interface{}{"project_name": "bench", "author": "ason"}
+	tmpl := &Template{Path: writeBenchTemplate(b, 1000)}
+
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			opts := Options{Log: io.Discard, Jobs: jobs}
+			for b.Loop() {
+				opts.Sink = NewInMemorySink()
+				if err := New(tmpl, engine.NewPongo2EngineWithOptions(engine.Options{})).Generate(b.Context(), "", context, opts); err != nil {
+					b.Fatalf("Generate() failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGenerator_Generate_Binary(b *testing.B) {
+	dir := b.TempDir()
+	content := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 4<<20/4)
+	for i := range 8 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("image%d.png", i)), content, 0644); err != nil {
+			b.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	tmpl := &Template{Path: dir}
+	opts := Options{Log: io.Discard}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		outputDir := filepath.Join(b.TempDir(), "out")
+		if err := New(tmpl, engine.NewPongo2Engine()).Generate(b.Context(), outputDir, nil, opts); err != nil {
+			b.Fatalf("Generate() failed: %v", err)
+		}
+	}
+}
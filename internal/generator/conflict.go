@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// OverwritePolicy decides what happens to output files that already exist
+type OverwritePolicy string
+
+const (
+	// OverwriteReplace replaces existing files; it is the default
+	OverwriteReplace OverwritePolicy = "overwrite"
+	// OverwriteSkip leaves existing files untouched
+	OverwriteSkip OverwritePolicy = "skip"
+	// OverwriteBackup renames existing files to name.bak before writing
+	OverwriteBackup OverwritePolicy = "backup"
+	// OverwriteAsk asks, through Options.Ask, for each existing file
+	OverwriteAsk OverwritePolicy = "ask"
+)
+
+// OverwritePolicies lists the supported policies
+var OverwritePolicies = []OverwritePolicy{OverwriteAsk, OverwriteReplace, OverwriteSkip, OverwriteBackup}
+
+// ParseOverwritePolicy returns the policy with the given name
+func ParseOverwritePolicy(name string) (OverwritePolicy, error) {
+	for _, policy := range OverwritePolicies {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+
+	names := make([]string, len(OverwritePolicies))
+	for i, policy := range OverwritePolicies {
+		names[i] = string(policy)
+	}
+	return "", fmt.Errorf("unknown overwrite policy %q (supported: %s)", name, strings.Join(names, ", "))
+}
+
+// BackupSuffix is appended to existing files moved aside by OverwriteBackup
+const BackupSuffix = ".bak"
+
+// ExistingFileSink is implemented by sinks that can already hold files
+// before generation, so conflicts with them can be resolved
+type ExistingFileSink interface {
+	OutputSink
+	Exists(path string) (bool, error)
+	ReadFile(path string) ([]byte, error)
+	Mode(path string) (fs.FileMode, error)
+	Rename(oldPath, newPath string) error
+}
+
+// Exists reports whether a file exists at path
+func (FilesystemSink) Exists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+// ReadFile returns the content of an existing file
+func (FilesystemSink) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Mode returns the permission bits of an existing file
+func (FilesystemSink) Mode(path string) (fs.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
+
+// Rename moves a file, replacing any file at newPath
+func (FilesystemSink) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// Exists reports whether a file is stored at path
+func (s *InMemorySink) Exists(path string) (bool, error) {
+	_, ok := s.Files[sinkKey(path)]
+	return ok, nil
+}
+
+// ReadFile returns the content of a stored file
+func (s *InMemorySink) ReadFile(path string) ([]byte, error) {
+	file, ok := s.Files[sinkKey(path)]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return file.Content, nil
+}
+
+// Mode returns the mode of a stored file
+func (s *InMemorySink) Mode(path string) (fs.FileMode, error) {
+	file, ok := s.Files[sinkKey(path)]
+	if !ok {
+		return 0, fs.ErrNotExist
+	}
+	return file.Mode, nil
+}
+
+// Rename moves a stored file
+func (s *InMemorySink) Rename(oldPath, newPath string) error {
+	file, ok := s.Files[sinkKey(oldPath)]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	delete(s.Files, sinkKey(oldPath))
+	s.Files[sinkKey(newPath)] = file
+	return nil
+}
+
+// Errors returned by conflictSink.WriteFile when it leaves an existing file
+// in place
+var (
+	errKeptExisting = errors.New("kept existing file")
+	errUnchanged    = errors.New("existing file is unchanged")
+)
+
+// conflictSink applies an overwrite policy to files that already exist in
+// the sink it wraps, and optionally leaves files whose content wouldn't
+// change alone. Files it doesn't write never reach the inner sink, so they
+// aren't counted as written.
+type conflictSink struct {
+	OutputSink
+	existing      ExistingFileSink
+	policy        OverwritePolicy
+	ask           func(path string) (bool, error)
+	skipUnchanged bool
+	summary       *Summary
+	log           io.Writer
+}
+
+func (s *conflictSink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	generated := func() ([]byte, error) { return content, nil }
+	if err := s.resolve(path, mode, generated); err != nil {
+		return err
+	}
+	return s.OutputSink.WriteFile(path, mode, content)
+}
+
+// CopyFile copies src, which is only read into memory when an existing file
+// has to be compared with it
+func (s *conflictSink) CopyFile(src, path string, mode fs.FileMode) error {
+	generated := func() ([]byte, error) { return os.ReadFile(src) }
+	if err := s.resolve(path, mode, generated); err != nil {
+		return err
+	}
+	return copyFile(s.OutputSink, src, path, mode)
+}
+
+// resolve decides whether the file at path may be written, returning nil if
+// so, or why it is left alone. generated returns the new content.
+func (s *conflictSink) resolve(path string, mode fs.FileMode, generated func() ([]byte, error)) error {
+	overwrite := s.policy == "" || s.policy == OverwriteReplace
+	if overwrite && !s.skipUnchanged {
+		return nil
+	}
+
+	exists, err := s.existing.Exists(path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	// An identical file needs neither writing nor a decision about it. A
+	// file whose mode differs is rewritten, so it takes the new mode.
+	if s.skipUnchanged {
+		unchanged, err := s.unchanged(path, mode, generated)
+		if err != nil {
+			return err
+		}
+		if unchanged {
+			s.summary.Unchanged++
+			return errUnchanged
+		}
+	}
+
+	switch s.policy {
+	case OverwriteSkip:
+		return errKeptExisting
+	case OverwriteBackup:
+		if err := s.existing.Rename(path, path+BackupSuffix); err != nil {
+			return fmt.Errorf("failed to back up existing file: %w", err)
+		}
+		fmt.Fprintf(s.log, "💾 Backed up existing file to %s\n", path+BackupSuffix)
+	case OverwriteAsk:
+		if s.ask == nil {
+			return fmt.Errorf("file already exists: %s", path)
+		}
+		overwrite, err := s.ask(path)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return errKeptExisting
+		}
+	}
+
+	return nil
+}
+
+// unchanged reports whether the existing file at path already has the given
+// mode and generated content
+func (s *conflictSink) unchanged(path string, mode fs.FileMode, generated func() ([]byte, error)) (bool, error) {
+	current, err := s.existing.Mode(path)
+	if err != nil {
+		return false, err
+	}
+	if current.Perm() != mode.Perm() {
+		return false, nil
+	}
+
+	existing, err := s.existing.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	content, err := generated()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(existing, content), nil
+}
@@ -0,0 +1,238 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// conflictFixture creates a template with README.md and main.go, and an
+// output directory that already holds a README.md
+func conflictFixture(t *testing.T) (templateDir, outputDir string) {
+	t.Helper()
+
+	templateDir = t.TempDir()
+	for name, content := range map[string]string{"README.md": "# {{ name }}", "main.go": "package {{ name }}"} {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	outputDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "README.md"), []byte("my notes"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+	return templateDir, outputDir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+func TestGenerator_OverwritePolicy(t *testing.T) {
+	context := map[string]interface{}{"name": "demo"}
+
+	t.Run("overwrite", func(t *testing.T) {
+		templateDir, outputDir := conflictFixture(t)
+		gen := New(&Template{Path: templateDir}, &MockEngine{})
+		if err := gen.Generate(t.Context(), outputDir, context, Options{Log: &bytes.Buffer{}}); err != nil {
+			t.Fatalf("Generate() failed: %v", err)
+		}
+		if got := readFile(t, filepath.Join(outputDir, "README.md")); got != "# demo" {
+			t.Errorf("README.md = %q, want it overwritten", got)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		templateDir, outputDir := conflictFixture(t)
+		var log bytes.Buffer
+		gen := New(&Template{Path: templateDir}, &MockEngine{})
+		if err := gen.Generate(t.Context(), outputDir, context, Options{Overwrite: OverwriteSkip, Log: &log}); err != nil {
+			t.Fatalf("Generate() failed: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "README.md")); got != "my notes" {
+			t.Errorf("README.md = %q, want the existing file unchanged", got)
+		}
+		if got := readFile(t, filepath.Join(outputDir, "main.go")); got != "package demo" {
+			t.Errorf("main.go = %q, want it generated", got)
+		}
+		if !strings.Contains(log.String(), "Kept existing: README.md") {
+			t.Errorf("log does not report the kept file:\n%s", log.String())
+		}
+		if files := gen.Summary().Files; files != 1 {
+			t.Errorf("Summary().Files = %d, want 1", files)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		templateDir, outputDir := conflictFixture(t)
+		gen := New(&Template{Path: templateDir}, &MockEngine{})
+		if err := gen.Generate(t.Context(), outputDir, context, Options{Overwrite: OverwriteBackup, Log: &bytes.Buffer{}}); err != nil {
+			t.Fatalf("Generate() failed: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "README.md.bak")); got != "my notes" {
+			t.Errorf("README.md.bak = %q, want the previous content", got)
+		}
+		if got := readFile(t, filepath.Join(outputDir, "README.md")); got != "# demo" {
+			t.Errorf("README.md = %q, want it regenerated", got)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, "main.go.bak")); !os.IsNotExist(err) {
+			t.Error("a new file should not be backed up")
+		}
+	})
+
+	t.Run("ask", func(t *testing.T) {
+		templateDir, outputDir := conflictFixture(t)
+		var asked []string
+		opts := Options{
+			Overwrite: OverwriteAsk,
+			Log:       &bytes.Buffer{},
+			Ask: func(path string) (bool, error) {
+				asked = append(asked, filepath.Base(path))
+				return false, nil
+			},
+		}
+
+		gen := New(&Template{Path: templateDir}, &MockEngine{})
+		if err := gen.Generate(t.Context(), outputDir, context, opts); err != nil {
+			t.Fatalf("Generate() failed: %v", err)
+		}
+		if len(asked) != 1 || asked[0] != "README.md" {
+			t.Errorf("asked about %v, want only README.md", asked)
+		}
+		if got := readFile(t, filepath.Join(outputDir, "README.md")); got != "my notes" {
+			t.Errorf("README.md = %q, want it kept after answering no", got)
+		}
+	})
+}
+
+func TestParseOverwritePolicy(t *testing.T) {
+	if policy, err := ParseOverwritePolicy("backup"); err != nil || policy != OverwriteBackup {
+		t.Errorf("ParseOverwritePolicy(backup) = %q, %v", policy, err)
+	}
+	if _, err := ParseOverwritePolicy("merge"); err == nil {
+		t.Error("ParseOverwritePolicy(merge) should fail")
+	}
+}
+
+func TestGenerator_SkipUnchanged(t *testing.T) {
+	templateDir, outputDir := conflictFixture(t)
+	context := map[string]interface{}{"name": "demo"}
+	opts := Options{SkipUnchanged: true, Log: &bytes.Buffer{}}
+
+	gen := New(&Template{Path: templateDir}, &MockEngine{})
+	if err := gen.Generate(t.Context(), outputDir, context, opts); err != nil {
+		t.Fatalf("first Generate() failed: %v", err)
+	}
+
+	// Age the generated files so a rewrite would be visible
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"README.md", "main.go"} {
+		if err := os.Chtimes(filepath.Join(outputDir, name), old, old); err != nil {
+			t.Fatalf("Chtimes(%s) failed: %v", name, err)
+		}
+	}
+
+	// main.go changes in the template; README.md doesn't
+	if err := os.WriteFile(filepath.Join(templateDir, "main.go"), []byte("package {{ name }} // v2"), 0644); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+
+	var log bytes.Buffer
+	opts.Log = &log
+	if err := gen.Generate(t.Context(), outputDir, context, opts); err != nil {
+		t.Fatalf("second Generate() failed: %v", err)
+	}
+
+	if !strings.Contains(log.String(), "Unchanged: README.md") {
+		t.Errorf("log does not report README.md as unchanged:\n%s", log.String())
+	}
+	info, err := os.Stat(filepath.Join(outputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Stat(README.md) failed: %v", err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("README.md mtime = %v, want it preserved at %v", info.ModTime(), old)
+	}
+
+	if got := readFile(t, filepath.Join(outputDir, "main.go")); got != "package demo // v2" {
+		t.Errorf("main.go = %q, want the changed content written", got)
+	}
+
+	summary := gen.Summary()
+	if summary.Unchanged != 1 || summary.Files != 1 {
+		t.Errorf("Summary() Unchanged, Files = %d, %d; want 1, 1", summary.Unchanged, summary.Files)
+	}
+}
+
+func TestGenerator_SkipUnchangedMode(t *testing.T) {
+	templateDir, outputDir := conflictFixture(t)
+	context := map[string]interface{}{"name": "demo"}
+	opts := Options{SkipUnchanged: true, Log: &bytes.Buffer{}}
+
+	tmpl := &Template{Path: templateDir}
+	if err := New(tmpl, &MockEngine{}).Generate(t.Context(), outputDir, context, opts); err != nil {
+		t.Fatalf("first Generate() failed: %v", err)
+	}
+
+	// The content stays the same, but main.go is now meant to be executable
+	tmpl.Config = &template.Config{Executable: []string{"main.go"}}
+	gen := New(tmpl, &MockEngine{})
+	if err := gen.Generate(t.Context(), outputDir, context, opts); err != nil {
+		t.Fatalf("second Generate() failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(outputDir, "main.go"))
+	if err != nil {
+		t.Fatalf("Stat(main.go) failed: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("main.go mode = %v, want it updated to 0755", info.Mode().Perm())
+	}
+
+	summary := gen.Summary()
+	if summary.Unchanged != 1 || summary.Files != 1 {
+		t.Errorf("Summary() Unchanged, Files = %d, %d; want 1, 1", summary.Unchanged, summary.Files)
+	}
+}
+
+func TestGenerator_SkipUnchangedCopied(t *testing.T) {
+	templateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templateDir, "logo.png"), []byte("\x89PNG"), 0644); err != nil {
+		t.Fatalf("Failed to create logo.png: %v", err)
+	}
+	outputDir := t.TempDir()
+	opts := Options{SkipUnchanged: true, Log: &bytes.Buffer{}}
+
+	gen := New(&Template{Path: templateDir}, &MockEngine{})
+	if err := gen.Generate(t.Context(), outputDir, nil, opts); err != nil {
+		t.Fatalf("first Generate() failed: %v", err)
+	}
+	if summary := gen.Summary(); summary.Files != 1 || summary.Bytes != 4 {
+		t.Errorf("first Summary() Files, Bytes = %d, %d; want 1, 4", summary.Files, summary.Bytes)
+	}
+
+	var log bytes.Buffer
+	opts.Log = &log
+	if err := gen.Generate(t.Context(), outputDir, nil, opts); err != nil {
+		t.Fatalf("second Generate() failed: %v", err)
+	}
+	if !strings.Contains(log.String(), "Unchanged: logo.png") {
+		t.Errorf("log does not report logo.png as unchanged:\n%s", log.String())
+	}
+	if summary := gen.Summary(); summary.Unchanged != 1 || summary.Files != 0 {
+		t.Errorf("second Summary() Unchanged, Files = %d, %d; want 1, 0", summary.Unchanged, summary.Files)
+	}
+}
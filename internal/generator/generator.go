@@ -1,13 +1,21 @@
 package generator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/fsutil"
+	"github.com/madstone-tech/ason/internal/glob"
 	"github.com/madstone-tech/ason/internal/template"
 )
 
@@ -15,19 +23,109 @@ import (
 type Generator struct {
 	template *Template
 	engine   engine.Engine
+	sink     OutputSink
+	log      io.Writer
+	include  []string
+	exclude  []string
+	// ignore holds the current template's ignore patterns
+	ignore  []string
+	summary Summary
+	// engines caches the engines chosen by the config's engines map, by
+	// name; engineOptions configures them
+	engines       map[string]engine.Engine
+	engineOptions engine.Options
+	// written holds the output file paths generated so far, so base and
+	// included templates don't overwrite files their parents provide
+	written map[string]bool
+
+	// verbose also logs created directories and skipped paths
+	verbose bool
+
+	// pruneEmptyDirs only creates directories for the files written into
+	// them, and for directories empty in the template
+	pruneEmptyDirs bool
+
+	// jobs is how many files are read and rendered at once
+	jobs int
 }
 
 // Options for generation
 type Options struct {
+	// SkipHooks disables the template's hooks; templates have none yet
 	SkipHooks bool
 	DryRun    bool
 	Verbose   bool
+	// Include, when non-empty, limits generation to template-relative
+	// file paths matching one of these globs
+	Include []string
+	// Exclude skips template-relative paths matching one of these globs,
+	// in addition to the template's ignore patterns
+	Exclude []string
+	// Log receives progress messages. It defaults to stdout, or stderr
+	// when streaming.
+	Log io.Writer
+	// Sink, when set, receives the generated files instead of the
+	// filesystem. Paths are joined onto the output path, which may be empty.
+	Sink OutputSink
+	// Stream, when set, receives the generated files as a tar stream
+	// instead of writing them under the output path. Progress messages
+	// go to stderr so they don't corrupt the stream.
+	Stream io.Writer
+	// Overwrite decides what happens to files that already exist in the
+	// output; the zero value overwrites them. It only applies to sinks
+	// implementing ExistingFileSink.
+	Overwrite OverwritePolicy
+	// Ask is called for each existing file under OverwriteAsk and reports
+	// whether to overwrite it
+	Ask func(path string) (bool, error)
+	// SkipUnchanged leaves existing files alone when the generated content
+	// is identical, so their modification times don't change
+	SkipUnchanged bool
+	// EngineOptions configure the engines a template's engines map selects
+	// for individual files
+	EngineOptions engine.Options
+	// NoCleanup keeps the partial output of a failed generation. By
+	// default the files and directories it created on the filesystem are
+	// removed again.
+	NoCleanup bool
+	// PruneEmptyDirs leaves out directories that would end up empty because
+	// everything in them was ignored, excluded or skipped. Directories that
+	// are empty in the template itself are still created.
+	PruneEmptyDirs bool
+	// Jobs is how many files are read and rendered at once; 0 uses the
+	// number of CPUs. Files are still written, and logged, one at a time
+	// in template order.
+	Jobs int
+}
+
+// Summary describes what the last Generate call wrote
+type Summary struct {
+	Files       int
+	Directories int
+	Bytes       int64
+	Duration    time.Duration
+	// Unchanged counts existing files left alone by SkipUnchanged; they
+	// aren't included in Files
+	Unchanged int
 }
 
 // Template represents a template with its configuration
 type Template struct {
 	Path   string
 	Config *template.Config
+	// Base is the template this one extends, if any
+	Base *Template
+	// Includes are templates generated into subdirectories of this one
+	Includes []Inclusion
+}
+
+// Inclusion is a template generated into a subdirectory of another
+type Inclusion struct {
+	Template *Template
+	// Path is the subdirectory, relative to the including template's output
+	Path string
+	// Vars override the including template's variables
+	Vars map[string]interface{}
 }
 
 // New creates a new generator
@@ -35,61 +133,234 @@ func New(tmpl *Template, eng engine.Engine) *Generator {
 	return &Generator{
 		template: tmpl,
 		engine:   eng,
+		sink:     FilesystemSink{},
+		log:      os.Stdout,
 	}
 }
 
-// Generate generates a project from the template
-func (g *Generator) Generate(outputPath string, context map[string]interface{}, opts Options) error {
+// Generate generates a project from the template. It stops between files
+// once ctx is done, returning ctx's error. When generation to the filesystem
+// fails, the files and directories it created are removed unless
+// Options.NoCleanup is set.
+func (g *Generator) Generate(ctx context.Context, outputPath string, context map[string]interface{}, opts Options) error {
+	start := time.Now()
+	g.summary = Summary{}
+	defer func() { g.summary.Duration = time.Since(start) }()
+
+	sink := g.selectSink(opts)
+	var rollback *rollbackSink
+	if _, ok := sink.(FilesystemSink); ok && !opts.DryRun && !opts.NoCleanup {
+		rollback = &rollbackSink{}
+		sink = rollback
+	}
+	g.sink, g.log = &countingSink{OutputSink: sink, summary: &g.summary}, os.Stdout
+	g.include, g.exclude = opts.Include, opts.Exclude
+	g.written = make(map[string]bool)
+	g.verbose = opts.Verbose
+	g.pruneEmptyDirs = opts.PruneEmptyDirs
+	g.jobs = opts.Jobs
+	if g.jobs < 1 {
+		g.jobs = runtime.NumCPU()
+	}
+	g.engines, g.engineOptions = make(map[string]engine.Engine), opts.EngineOptions
+
+	var tarSink *TarSink
+	if opts.Stream != nil {
+		// Tar entries are named relative to the project root
+		outputPath = ""
+		g.log = os.Stderr
+		if ts, ok := sink.(*TarSink); ok && opts.Sink == nil {
+			tarSink = ts
+		}
+	}
+	if opts.Log != nil {
+		g.log = opts.Log
+	}
+	if existing, ok := sink.(ExistingFileSink); ok {
+		g.sink = &conflictSink{
+			OutputSink:    g.sink,
+			existing:      existing,
+			policy:        opts.Overwrite,
+			ask:           opts.Ask,
+			skipUnchanged: opts.SkipUnchanged,
+			summary:       &g.summary,
+			log:           g.log,
+		}
+	}
+
 	if opts.DryRun {
-		fmt.Printf("DRY RUN: Would generate project at %s\n", outputPath)
-		if err := g.walkTemplateFiles(g.template.Path, outputPath, context, true); err != nil {
-			return err
+		if opts.Stream != nil {
+			fmt.Fprintln(g.log, "DRY RUN: Would write project as a tar stream")
+		} else {
+			fmt.Fprintf(g.log, "DRY RUN: Would generate project at %s\n", outputPath)
 		}
-		return nil
+		return g.walkTemplates(ctx, g.template, outputPath, context, true)
 	}
 
-	// Create output directory
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	// Create output directory; it isn't counted in the summary
+	if outputPath != "" {
+		if err := sink.Mkdir(outputPath, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		fmt.Fprintf(g.log, "※ Generating project at %s...\n", outputPath)
 	}
 
-	fmt.Printf("※ Generating project at %s...\n", outputPath)
+	// Process all template files, removing what was created if that fails
+	if err := g.walkTemplates(ctx, g.template, outputPath, context, false); err != nil {
+		err = fmt.Errorf("failed to process template: %w", err)
+		if rollback != nil {
+			if rbErr := rollback.Rollback(); rbErr != nil {
+				return fmt.Errorf("%w (removing partial output failed: %v)", err, rbErr)
+			}
+			fmt.Fprintln(g.log, "🧹 Removed the partially generated files")
+		}
+		return err
+	}
 
-	// Process all template files
-	if err := g.walkTemplateFiles(g.template.Path, outputPath, context, false); err != nil {
-		return fmt.Errorf("failed to process template: %w", err)
+	if tarSink != nil {
+		if err := tarSink.Close(); err != nil {
+			return fmt.Errorf("failed to finish tar stream: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// walkTemplateFiles recursively processes all files in the template
-func (g *Generator) walkTemplateFiles(templatePath, outputPath string, context map[string]interface{}, dryRun bool) error {
-	return filepath.Walk(templatePath, func(srcPath string, info os.FileInfo, err error) error {
+// Summary returns what the last Generate call wrote. Dry runs write nothing.
+func (g *Generator) Summary() Summary {
+	return g.summary
+}
+
+// selectSink picks the output sink for a run: an explicit Sink, a tar
+// stream, or the filesystem
+func (g *Generator) selectSink(opts Options) OutputSink {
+	switch {
+	case opts.Sink != nil:
+		return opts.Sink
+	case opts.Stream != nil:
+		return NewTarSink(opts.Stream)
+	default:
+		return FilesystemSink{}
+	}
+}
+
+// walkTemplates processes a template, then the template it extends, then
+// the templates it includes. Files already generated earlier are skipped, so
+// a child wins conflicts with its base without any file being written twice.
+func (g *Generator) walkTemplates(ctx context.Context, tmpl *Template, outputPath string, context map[string]interface{}, dryRun bool) error {
+	// Each template is walked with its own config but shares the sink,
+	// summary and written paths
+	tg := *g
+	tg.template = tmpl
+	ignore, err := tmpl.Config.IgnorePatterns(tmpl.Path)
+	if err != nil {
+		return err
+	}
+	tg.ignore = ignore
+	if err := tg.walkTemplateFiles(ctx, tmpl.Path, outputPath, context, dryRun); err != nil {
+		return err
+	}
+
+	if tmpl.Base != nil {
+		if err := g.walkTemplates(ctx, tmpl.Base, outputPath, context, dryRun); err != nil {
+			return fmt.Errorf("base template %s: %w", tmpl.Base.Path, err)
+		}
+	}
+
+	for _, inc := range tmpl.Includes {
+		incContext, err := g.includeContext(inc, context)
 		if err != nil {
-			return err
+			return fmt.Errorf("included template %s: %w", inc.Template.Path, err)
 		}
 
-		// Calculate relative path from template root
-		relPath, err := filepath.Rel(templatePath, srcPath)
+		incPath, err := g.processString(inc.Path, context)
 		if err != nil {
-			return fmt.Errorf("failed to calculate relative path: %w", err)
+			return fmt.Errorf("failed to process include path %s: %w", inc.Path, err)
 		}
 
-		// Skip the template root directory
-		if relPath == "." {
-			return nil
+		// The path is only checked unrendered when the template is resolved,
+		// so a variable could still make it climb out of the output directory
+		if !filepath.IsLocal(incPath) {
+			return fmt.Errorf("include path %s renders to %q, which is not a path inside the output directory", inc.Path, incPath)
+		}
+
+		if err := g.walkTemplates(ctx, inc.Template, filepath.Join(outputPath, incPath), incContext, dryRun); err != nil {
+			return fmt.Errorf("included template %s: %w", inc.Template.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// includeContext returns the variables for an included template: the
+// parent's, overridden by the inclusion's Vars rendered against them
+func (g *Generator) includeContext(inc Inclusion, context map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(context)+len(inc.Vars))
+	for k, v := range context {
+		merged[k] = v
+	}
+
+	for k, v := range inc.Vars {
+		if str, ok := v.(string); ok {
+			rendered, err := g.processString(str, context)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process variable %s: %w", k, err)
+			}
+			v = rendered
+		}
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+// walkTemplateFiles recursively processes all files in the template
+func (g *Generator) walkTemplateFiles(ctx context.Context, templatePath, outputPath string, context map[string]interface{}, dryRun bool) error {
+	// Skip hidden files and directories the template doesn't keep, and
+	// paths matching its ignore patterns
+	policy := fsutil.Policy{
+		KeepHidden: g.config().KeepHidden,
+		Ignore:     g.ignore,
+		OnIgnore:   func(relPath string, _ fs.FileInfo) { g.logExcluded(relPath) },
+	}
+
+	// Files are collected while walking and generated afterwards, so they
+	// can be read and rendered concurrently
+	var tasks []*fileTask
+	skippedHidden, err := fsutil.Walk(templatePath, policy, func(srcPath, relPath string, info fs.FileInfo) error {
+		// Stop between files once the caller gives up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Partials are only rendered through the files including them
+		if info.IsDir() && containsPath(g.config().PartialsDirs(), relPath) {
+			slog.Debug("skipped partials directory", "path", relPath)
+			if g.verbose {
+				fmt.Fprintf(g.log, "⏭️  Partials: %s\n", relPath)
+			}
+			return filepath.SkipDir
 		}
 
-		// Skip hidden files except .gitignore and .env.example
-		if strings.HasPrefix(filepath.Base(srcPath), ".") &&
-			filepath.Base(srcPath) != ".gitignore" &&
-			filepath.Base(srcPath) != ".env.example" {
+		// Skip paths excluded by the caller
+		if glob.MatchAny(g.exclude, relPath) {
+			g.logExcluded(relPath)
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		if !info.IsDir() && !g.isIncluded(relPath) {
+			slog.Debug("skipped path not matching --include", "path", relPath)
+			return nil
+		}
+
+		// With an include allowlist, directories are only created for the
+		// files written into them. Pruning does the same, but keeps
+		// directories the template deliberately leaves empty.
+		if info.IsDir() && (len(g.include) > 0 || g.pruneEmptyDirs && !template.IsKeptEmpty(srcPath)) {
+			return nil
+		}
 
 		// Process template variables in the path
 		destRelPath, err := g.processString(relPath, context)
@@ -97,75 +368,150 @@ func (g *Generator) walkTemplateFiles(templatePath, outputPath string, context m
 			return fmt.Errorf("failed to process path %s: %w", relPath, err)
 		}
 
+		// Turn dot_ prefixes into dots, so dot_gitignore becomes .gitignore
+		destRelPath = g.applyDotPrefix(destRelPath)
+
+		// Drop render suffixes such as .tmpl from file names, then apply rename rules
+		if !info.IsDir() {
+			destRelPath, _ = g.stripRenderSuffix(destRelPath)
+			destRelPath, err = g.applyRenameRules(destRelPath, context)
+			if err != nil {
+				return fmt.Errorf("failed to rename %s: %w", relPath, err)
+			}
+		}
+
 		destPath := filepath.Join(outputPath, destRelPath)
 
+		// A file already generated by an extending or including template wins
+		if !info.IsDir() {
+			if g.written[destPath] {
+				slog.Debug("skipped file already generated", "path", relPath, "output", destRelPath)
+				if g.verbose {
+					fmt.Fprintf(g.log, "⏭️  Skipped %s: already generated\n", relPath)
+				}
+				return nil
+			}
+			g.written[destPath] = true
+		}
+
 		if dryRun {
 			if info.IsDir() {
-				fmt.Printf("[DRY RUN] Would create directory: %s\n", destPath)
+				fmt.Fprintf(g.log, "[DRY RUN] Would create directory: %s\n", destPath)
 			} else {
-				fmt.Printf("[DRY RUN] Would process file: %s → %s\n", srcPath, destPath)
+				fmt.Fprintf(g.log, "[DRY RUN] Would process file: %s → %s\n", srcPath, destPath)
 			}
 			return nil
 		}
 
 		if info.IsDir() {
 			// Create directory
-			if err := os.MkdirAll(destPath, info.Mode()); err != nil {
+			if err := g.sink.Mkdir(destPath, info.Mode().Perm()); err != nil {
 				return fmt.Errorf("failed to create directory %s: %w", destPath, err)
 			}
-			if opts.Verbose {
-				fmt.Printf("📁 Created directory: %s\n", destRelPath)
+			if g.verbose {
+				fmt.Fprintf(g.log, "📁 Created directory: %s\n", destRelPath)
 			}
 		} else {
-			// Process file
-			if err := g.processFile(srcPath, destPath, context); err != nil {
-				return fmt.Errorf("failed to process file %s: %w", srcPath, err)
+			task := &fileTask{
+				srcPath:     srcPath,
+				destPath:    destPath,
+				relPath:     relPath,
+				destRelPath: destRelPath,
+				render:      !g.isRaw(relPath) && g.shouldProcessAsTemplate(srcPath),
+				mode:        g.fileMode(destRelPath, info.Mode().Perm()),
+				done:        make(chan struct{}),
 			}
-			fmt.Printf("💫 Transformed: %s\n", destRelPath)
+			// Engines are chosen here, since engineFor caches them
+			if task.render {
+				if task.engine, err = g.engineFor(relPath); err != nil {
+					return fmt.Errorf("failed to process file %s: %w", srcPath, err)
+				}
+				task.engineName = engine.Name(task.engine)
+			}
+			tasks = append(tasks, task)
 		}
 
 		return nil
 	})
+	for _, relPath := range skippedHidden {
+		slog.Debug("skipped hidden path", "path", relPath)
+	}
+	if len(skippedHidden) > 0 {
+		fmt.Fprintf(g.log, "⚠️  Skipped hidden paths (set include_hidden or hidden_allow in the template config to keep them): %s\n",
+			strings.Join(skippedHidden, ", "))
+	}
+	if err != nil {
+		return err
+	}
+
+	return g.processFiles(ctx, tasks, context)
 }
 
-// processFile processes a single file through the template engine
-func (g *Generator) processFile(srcPath, destPath string, context map[string]interface{}) error {
-	// Create destination directory if it doesn't exist
-	destDir := filepath.Dir(destPath)
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+// logExcluded reports a path left out by ignore or exclude patterns
+func (g *Generator) logExcluded(relPath string) {
+	slog.Debug("skipped excluded path", "path", relPath)
+	if g.verbose {
+		fmt.Fprintf(g.log, "⏭️  Excluded: %s\n", relPath)
 	}
+}
 
-	// Check if file should be processed as a template
-	if g.shouldProcessAsTemplate(srcPath) {
-		// Read source file
-		srcContent, err := os.ReadFile(srcPath)
-		if err != nil {
-			return fmt.Errorf("failed to read source file: %w", err)
-		}
-
-		// Process through template engine
-		processedContent, err := g.engine.Render(string(srcContent), context)
-		if err != nil {
-			return fmt.Errorf("failed to process template: %w", err)
-		}
+// writeFile writes a generated file to the sink and logs the outcome
+func (g *Generator) writeFile(task *fileTask) error {
+	g.logFileDecision(task)
+	if task.err != nil {
+		return fmt.Errorf("failed to process file %s: %w", task.srcPath, task.err)
+	}
 
-		// Write processed content
-		if err := os.WriteFile(destPath, []byte(processedContent), 0644); err != nil {
-			return fmt.Errorf("failed to write processed file: %w", err)
-		}
+	var err error
+	if task.render {
+		err = g.sink.WriteFile(task.destPath, task.mode, task.content)
 	} else {
-		// Copy binary files as-is
-		if err := g.copyFile(srcPath, destPath); err != nil {
-			return fmt.Errorf("failed to copy file: %w", err)
-		}
+		err = copyFile(g.sink, task.srcPath, task.destPath, task.mode)
 	}
-
+	switch {
+	case errors.Is(err, errKeptExisting):
+		fmt.Fprintf(g.log, "⏭️  Kept existing: %s\n", task.destRelPath)
+		return nil
+	case errors.Is(err, errUnchanged):
+		fmt.Fprintf(g.log, "✔️  Unchanged: %s\n", task.destRelPath)
+		return nil
+	case err != nil && task.render:
+		return fmt.Errorf("failed to process file %s: failed to write processed file: %w", task.srcPath, err)
+	case err != nil:
+		return fmt.Errorf("failed to process file %s: failed to copy file: %w", task.srcPath, err)
+	}
+	fmt.Fprintf(g.log, "💫 Transformed: %s\n", task.destRelPath)
 	return nil
 }
 
+// renderFile reads a template file and passes it through its engine. Raw
+// and binary files are left to writeFile, which copies them without reading
+// them into memory. It is safe to call from several goroutines.
+func renderFile(task *fileTask, context map[string]interface{}) ([]byte, error) {
+	if !task.render {
+		return nil, nil
+	}
+
+	srcContent, err := os.ReadFile(task.srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	// Engine errors already name the file and position
+	processedContent, err := task.engine.RenderNamed(task.relPath, string(srcContent), context)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(processedContent), nil
+}
+
 // shouldProcessAsTemplate determines if a file should be processed as a template
 func (g *Generator) shouldProcessAsTemplate(filePath string) bool {
+	// Files carrying a render suffix are always rendered
+	if _, ok := g.stripRenderSuffix(filePath); ok {
+		return true
+	}
+
 	// Skip binary file extensions
 	ext := strings.ToLower(filepath.Ext(filePath))
 	binaryExts := []string{
@@ -183,22 +529,162 @@ func (g *Generator) shouldProcessAsTemplate(filePath string) bool {
 	return true
 }
 
-// copyFile copies a file from src to dst
-func (g *Generator) copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+// logFileDecision logs at debug level how a file is generated: copied as
+// raw or binary, or rendered and by which engine
+func (g *Generator) logFileDecision(task *fileTask) {
+	switch {
+	case g.isRaw(task.relPath):
+		slog.Debug("copying file", "path", task.relPath, "output", task.destRelPath, "reason", "raw_patterns")
+	case !task.render:
+		slog.Debug("copying file", "path", task.relPath, "output", task.destRelPath, "reason", "binary extension")
+	default:
+		slog.Debug("rendering file", "path", task.relPath, "output", task.destRelPath, "engine", task.engineName)
 	}
-	defer srcFile.Close()
+}
 
-	dstFile, err := os.Create(dst)
+// engineFor returns the engine that renders a template-relative file: the
+// one the config's engines map gives for the longest matching suffix, or
+// the template's engine
+func (g *Generator) engineFor(relPath string) (engine.Engine, error) {
+	suffix := g.engineSuffix(relPath)
+	if suffix == "" {
+		return g.engine, nil
+	}
+
+	name := g.config().Engines[suffix]
+	if eng, ok := g.engines[name]; ok {
+		return eng, nil
+	}
+	eng, err := engine.New(name, g.engineOptions)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("engine for %s files: %w", suffix, err)
+	}
+	if g.engines != nil {
+		g.engines[name] = eng
+	}
+	return eng, nil
+}
+
+// engineSuffix returns the longest suffix in the config's engines map that
+// path ends with, or "" if there is none
+func (g *Generator) engineSuffix(path string) string {
+	cfg := g.config()
+	if cfg == nil {
+		return ""
+	}
+
+	var longest string
+	for suffix := range cfg.Engines {
+		if strings.HasSuffix(path, suffix) && filepath.Base(path) != suffix && len(suffix) > len(longest) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+// config returns the template config, or nil if there is none
+func (g *Generator) config() *template.Config {
+	if g.template == nil {
+		return nil
+	}
+	return g.template.Config
+}
+
+// isRaw reports whether a template-relative path matches the config's
+// raw_patterns and must be copied verbatim
+func (g *Generator) isRaw(relPath string) bool {
+	cfg := g.config()
+	return cfg != nil && glob.MatchAny(cfg.RawPatterns, relPath)
+}
+
+// fileMode returns the mode of a generated file: the mode of its template
+// file, made executable when its output path matches the config's
+// executable patterns. Execute bits are only added where the file is
+// readable, so a private 0600 file becomes 0700 rather than world-readable.
+func (g *Generator) fileMode(destRelPath string, srcMode fs.FileMode) fs.FileMode {
+	if cfg := g.config(); cfg != nil && glob.MatchAny(cfg.Executable, destRelPath) {
+		return srcMode | (srcMode&0444)>>2
+	}
+	return srcMode
+}
+
+// isIncluded reports whether a template-relative file path passes the
+// caller's include allowlist; every path passes when there is none
+func (g *Generator) isIncluded(relPath string) bool {
+	return len(g.include) == 0 || glob.MatchAny(g.include, relPath)
+}
+
+// applyRenameRules applies the first matching rename rule to an output path.
+// Rules rendering to a path outside the output directory are refused.
+func (g *Generator) applyRenameRules(relPath string, context map[string]interface{}) (string, error) {
+	cfg := g.config()
+	if cfg == nil {
+		return relPath, nil
+	}
+
+	for _, rule := range cfg.Rename {
+		if !glob.Match(rule.From, relPath) {
+			continue
+		}
+
+		to, err := g.processString(rule.To, context)
+		if err != nil {
+			return "", err
+		}
+
+		dest := filepath.Join(filepath.Dir(relPath), to)
+		if strings.Contains(filepath.ToSlash(to), "/") {
+			dest = filepath.FromSlash(to)
+		}
+
+		// A rendered name must not climb out of the output directory
+		if !filepath.IsLocal(to) || !filepath.IsLocal(dest) {
+			return "", fmt.Errorf("rename rule for %s renders to %q, which is not a path inside the output directory", rule.From, to)
+		}
+		return dest, nil
+	}
+
+	return relPath, nil
+}
+
+// applyDotPrefix replaces the config's dotfile prefix with a dot at the
+// start of each path segment that carries it
+func (g *Generator) applyDotPrefix(relPath string) string {
+	prefix := g.config().DotPrefix()
+	if prefix == "" {
+		return relPath
+	}
+
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i, segment := range segments {
+		if len(segment) > len(prefix) && strings.HasPrefix(segment, prefix) {
+			segments[i] = "." + strings.TrimPrefix(segment, prefix)
+		}
+	}
+	return filepath.FromSlash(strings.Join(segments, "/"))
+}
+
+// stripRenderSuffix removes a render suffix (see template.DefaultRenderSuffixes)
+// or a suffix from the config's engines map from a file path, reporting
+// whether one was found
+func (g *Generator) stripRenderSuffix(path string) (string, bool) {
+	suffixes := template.DefaultRenderSuffixes
+	if cfg := g.config(); cfg != nil && len(cfg.RenderSuffixes) > 0 {
+		suffixes = cfg.RenderSuffixes
+	}
+
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(path, suffix) && filepath.Base(path) != suffix {
+			return strings.TrimSuffix(path, suffix), true
+		}
 	}
-	defer dstFile.Close()
 
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	// Suffixes mapped to an engine mark template files too
+	if suffix := g.engineSuffix(path); suffix != "" {
+		return strings.TrimSuffix(path, suffix), true
+	}
+
+	return path, false
 }
 
 // processString processes a string through the template engine
@@ -211,4 +697,12 @@ func (g *Generator) processString(input string, context map[string]interface{})
 	return g.engine.Render(input, context)
 }
 
-var opts Options // Make opts available to the package
+// containsPath reports whether paths holds relPath
+func containsPath(paths []string, relPath string) bool {
+	for _, p := range paths {
+		if filepath.Clean(p) == relPath {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,20 @@
 package generator
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/madstone-tech/ason/internal/engine"
 	"github.com/madstone-tech/ason/internal/template"
@@ -31,6 +41,10 @@ func (m *MockEngine) Render(tmpl string, context map[string]interface{}) (string
 	return result, nil
 }
 
+func (m *MockEngine) RenderNamed(name, tmpl string, context map[string]interface{}) (string, error) {
+	return m.Render(tmpl, context)
+}
+
 func (m *MockEngine) RenderFile(filepath string, context map[string]interface{}) (string, error) {
 	if m.renderFileFunc != nil {
 		return m.renderFileFunc(filepath, context)
@@ -134,7 +148,7 @@ func TestGenerator_Generate_DryRun(t *testing.T) {
 		DryRun: true,
 	}
 
-	err = generator.Generate("/tmp/test-output", context, opts)
+	err = generator.Generate(t.Context(), "/tmp/test-output", context, opts)
 	if err != nil {
 		t.Errorf("Generate() with dry run failed: %v", err)
 	}
@@ -187,7 +201,7 @@ func TestGenerator_Generate_RealRun(t *testing.T) {
 		DryRun: false,
 	}
 
-	err = generator.Generate(outputPath, context, opts)
+	err = generator.Generate(t.Context(), outputPath, context, opts)
 	if err != nil {
 		t.Errorf("Generate() failed: %v", err)
 	}
@@ -241,7 +255,7 @@ func TestGenerator_Generate_DirectoryCreationError(t *testing.T) {
 		DryRun: false,
 	}
 
-	err = generator.Generate(outputPath, context, opts)
+	err = generator.Generate(t.Context(), outputPath, context, opts)
 	if err == nil {
 		t.Error("Expected error when creating directory in invalid location, got nil")
 	}
@@ -289,7 +303,7 @@ func TestGenerator_WithRealEngine(t *testing.T) {
 		DryRun: false,
 	}
 
-	err = generator.Generate(tmpOutputDir, context, opts)
+	err = generator.Generate(t.Context(), tmpOutputDir, context, opts)
 	if err != nil {
 		t.Errorf("Generate() with real engine failed: %v", err)
 	}
@@ -348,7 +362,7 @@ func TestGenerator_BinaryFileHandling(t *testing.T) {
 		DryRun: false,
 	}
 
-	err = generator.Generate(tmpOutputDir, context, opts)
+	err = generator.Generate(t.Context(), tmpOutputDir, context, opts)
 	if err != nil {
 		t.Errorf("Generate() failed: %v", err)
 	}
@@ -418,7 +432,7 @@ func TestGenerator_NestedDirectories(t *testing.T) {
 		DryRun: false,
 	}
 
-	err = generator.Generate(tmpOutputDir, context, opts)
+	err = generator.Generate(t.Context(), tmpOutputDir, context, opts)
 	if err != nil {
 		t.Errorf("Generate() failed: %v", err)
 	}
@@ -482,3 +496,1317 @@ func TestGenerator_shouldProcessAsTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerator_StripRenderSuffix(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	// Files with a render suffix are rendered and written without it
+	files := map[string]string{
+		"main.go.tmpl":           "package {{ package_name }}",
+		"cmd/app.go.ason":        "// {{ package_name }}",
+		"notes.txt":              "{{ package_name }}",
+		"assets/banner.svg.ason": "<text>{{ package_name }}</text>",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{Path: tmpTemplateDir}, &MockEngine{})
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"package_name": "main"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"main.go":           "package main",
+		"cmd/app.go":        "// main",
+		"notes.txt":         "main",
+		"assets/banner.svg": "<text>main</text>",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s content = %q, want %q", name, got, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "main.go.tmpl")); !os.IsNotExist(err) {
+		t.Error("main.go.tmpl should be written without its suffix")
+	}
+}
+
+func TestGenerator_EnginesBySuffix(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	// Pongo2 filters and Go template actions in the same template
+	files := map[string]string{
+		"README.md.tmpl":    "# {{ project_name|upper }}",
+		"main.go.gotmpl":    "package {{ .project_name }}",
+		"notes.txt":         "{{ project_name }}",
+		"cmd/app.go.gotmpl": `// {{ printf "%s-app" .project_name }}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Engines: map[string]string{".gotmpl": engine.Go}},
+	}, engine.NewPongo2Engine())
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"project_name": "demo"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"README.md":  "# DEMO",
+		"main.go":    "package demo",
+		"notes.txt":  "demo",
+		"cmd/app.go": "// demo-app",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s content = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestGenerator_Partials(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	files := map[string]string{
+		"README.md":              `{% include "_includes/header.txt" %}Welcome`,
+		"_includes/header.txt":   "# {{ project_name }}\n",
+		"docs/_includes/note.md": "kept: only the top-level partials directory is skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	eng := engine.NewPongo2EngineWithOptions(engine.Options{Root: tmpTemplateDir})
+	generator := New(&Template{Path: tmpTemplateDir}, eng)
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"project_name": "demo"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(tmpOutputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("README.md was not created: %v", err)
+	}
+	if string(got) != "# demo\nWelcome" {
+		t.Errorf("README.md content = %q, want the partial included", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "_includes")); !os.IsNotExist(err) {
+		t.Error("the partials directory should not be generated")
+	}
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "docs", "_includes", "note.md")); err != nil {
+		t.Errorf("nested _includes directories should be generated: %v", err)
+	}
+}
+
+func TestGenerator_PartialsConvention(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	files := map[string]string{
+		"main.go.tmpl":            "{% include \"license.txt\" %}package {{ project_name }}",
+		"docs/index.md":           "{% include \"license.txt\" %}# Docs",
+		"_partials/license.txt":   "// MIT\n",
+		"_partials/unused.txt":    "never generated",
+		"_partials/nested/a.txt":  "never generated",
+		"src/_partials/local.txt": "generated: not at the template root",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	eng := engine.NewPongo2EngineWithOptions(engine.Options{
+		Root:        tmpTemplateDir,
+		IncludeDirs: []string{filepath.Join(tmpTemplateDir, "_partials")},
+	})
+	generator := New(&Template{Path: tmpTemplateDir}, eng)
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"project_name": "demo"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"main.go":                 "// MIT\npackage demo",
+		"docs/index.md":           "// MIT\n# Docs",
+		"src/_partials/local.txt": "generated: not at the template root",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s content = %q, want %q", name, got, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "_partials")); !os.IsNotExist(err) {
+		t.Error("_partials/ should not be generated")
+	}
+}
+
+func TestGenerator_PartialsDirConfigured(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for _, name := range []string{"partials/header.txt", "_includes/kept.txt"} {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("text"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{PartialsDir: "partials"},
+	}, &MockEngine{})
+	tmpOutputDir := t.TempDir()
+
+	if err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{}, Options{}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "partials")); !os.IsNotExist(err) {
+		t.Error("the configured partials directory should not be generated")
+	}
+	if _, err := os.Stat(filepath.Join(tmpOutputDir, "_includes", "kept.txt")); err != nil {
+		t.Errorf("_includes should be generated when another partials directory is configured: %v", err)
+	}
+}
+
+func TestGenerator_EnginesBySuffix_Unknown(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpTemplateDir, "page.html.j2"), []byte("{{ title }}"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	generator := New(&Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Engines: map[string]string{".j2": "jinja"}},
+	}, &MockEngine{})
+
+	err := generator.Generate(t.Context(), t.TempDir(), map[string]interface{}{"title": "Home"}, Options{})
+	if err == nil || !strings.Contains(err.Error(), `unknown engine "jinja"`) {
+		t.Errorf("Generate() error = %v, want an unknown engine error", err)
+	}
+}
+
+func TestGenerator_StripRenderSuffix_Configured(t *testing.T) {
+	generator := New(&Template{
+		Config: &template.Config{RenderSuffixes: []string{".j2"}},
+	}, &MockEngine{})
+
+	if got, ok := generator.stripRenderSuffix("nginx.conf.j2"); !ok || got != "nginx.conf" {
+		t.Errorf("stripRenderSuffix(nginx.conf.j2) = %q, %v, want nginx.conf, true", got, ok)
+	}
+
+	// Configured suffixes replace the defaults
+	if got, ok := generator.stripRenderSuffix("main.go.tmpl"); ok || got != "main.go.tmpl" {
+		t.Errorf("stripRenderSuffix(main.go.tmpl) = %q, %v, want unchanged", got, ok)
+	}
+}
+
+func TestGenerator_RawPatterns(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	workflow := "steps:\n  - run: echo ${{ github.sha }}\n"
+	files := map[string]string{
+		"deploy/workflow.yml": workflow,
+		"values.yml":          "image: {{ .Values.image }}\n",
+		"README.md":           "# {{ name }}",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{RawPatterns: []string{"**/*.yml"}},
+	}
+	generator := New(tmpl, engine.NewPongo2Engine())
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"name": "raw"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	for _, name := range []string{"deploy/workflow.yml", "values.yml"} {
+		got, err := os.ReadFile(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if string(got) != files[name] {
+			t.Errorf("%s content = %q, want verbatim %q", name, got, files[name])
+		}
+	}
+
+	readme, err := os.ReadFile(filepath.Join(tmpOutputDir, "README.md"))
+	if err != nil {
+		t.Fatalf("README.md was not created: %v", err)
+	}
+	if string(readme) != "# raw" {
+		t.Errorf("README.md content = %q, want %q", readme, "# raw")
+	}
+}
+
+func TestGenerator_Executable(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	// Scripts that lost their executable bit, as after editing on a system
+	// that drops modes, next to one that kept it without a pattern
+	files := map[string]os.FileMode{
+		"scripts/{{ name }}.sh": 0644,
+		"bin/run":               0644,
+		"tools/lint.sh":         0755,
+		"bin/secret.sh":         0600,
+		"README.md":             0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("# {{ name }}"), mode); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("Failed to set the mode of %s: %v", name, err)
+		}
+	}
+
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Executable: []string{"scripts/*.sh", "bin/run", "bin/secret.sh"}},
+	}
+	tmpOutputDir := t.TempDir()
+
+	// Existing files take the generated file's mode when they are replaced
+	existing := map[string]os.FileMode{"bin/run": 0644, "README.md": 0755}
+	for name, mode := range existing {
+		path := filepath.Join(tmpOutputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("old"), mode); err != nil {
+			t.Fatalf("Failed to create existing %s: %v", name, err)
+		}
+	}
+
+	generator := New(tmpl, engine.NewPongo2Engine())
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"name": "build"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]os.FileMode{
+		"scripts/build.sh": 0755,
+		"bin/run":          0755,
+		"tools/lint.sh":    0755,
+		"bin/secret.sh":    0700,
+		"README.md":        0644,
+	}
+	for name, mode := range want {
+		info, err := os.Stat(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("%s mode = %v, want %v", name, info.Mode().Perm(), mode)
+		}
+	}
+}
+
+func TestGenerator_RenameRules(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	files := map[string]string{
+		".env.example":      "PORT=8080",
+		"gitignore":         "*.log",
+		"docs/NAME.md":      "# {{ name }}",
+		"config/app.sample": "debug: true",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	tmpl := &Template{
+		Path: tmpTemplateDir,
+		Config: &template.Config{
+			Rename: []template.RenameRule{
+				{From: ".env.example", To: ".env"},
+				{From: "gitignore", To: ".gitignore"},
+				{From: "docs/NAME.md", To: "{{ name }}.md"},
+				{From: "config/*.sample", To: "settings/app.yaml"},
+			},
+		},
+	}
+	generator := New(tmpl, &MockEngine{})
+	tmpOutputDir := t.TempDir()
+
+	err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"name": "guide"}, Options{})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		".env":              "PORT=8080",
+		".gitignore":        "*.log",
+		"docs/guide.md":     "# guide",
+		"settings/app.yaml": "debug: true",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(tmpOutputDir, name))
+		if err != nil {
+			t.Errorf("%s was not created: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s content = %q, want %q", name, got, content)
+		}
+	}
+
+	for _, name := range []string{".env.example", "gitignore", "docs/NAME.md"} {
+		if _, err := os.Stat(filepath.Join(tmpOutputDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should have been renamed", name)
+		}
+	}
+}
+
+func TestGenerator_RenameRulesEscape(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpTemplateDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create README.md: %v", err)
+	}
+
+	tests := map[string]string{
+		"parent directory":   "../../{{ name }}.md",
+		"bare parent":        "..",
+		"absolute path":      "{{ dir }}/evil.md",
+		"nested parent":      "docs/../../evil.md",
+		"empty after render": "{{ missing }}",
+	}
+
+	for name, to := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			tmpl := &Template{
+				Path:   tmpTemplateDir,
+				Config: &template.Config{Rename: []template.RenameRule{{From: "README.md", To: to}}},
+			}
+			generator := New(tmpl, engine.NewPongo2Engine())
+
+			vars := map[string]interface{}{"name": "evil", "dir": root}
+			err := generator.Generate(t.Context(), filepath.Join(root, "a", "out"), vars, Options{})
+			if err == nil || !strings.Contains(err.Error(), "not a path inside the output directory") {
+				t.Errorf("Generate() error = %v, want the rename refused", err)
+			}
+
+			var written []string
+			filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					written = append(written, path)
+				}
+				return nil
+			})
+			if len(written) != 0 {
+				t.Errorf("Generate() wrote %v", written)
+			}
+		})
+	}
+}
+
+func TestGenerator_Timeout(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(tmpTemplateDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(name, []byte("{{ name }}"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	// A slow engine stands in for long-running work
+	slow := &MockEngine{renderFunc: func(tmpl string, context map[string]interface{}) (string, error) {
+		time.Sleep(20 * time.Millisecond)
+		return tmpl, nil
+	}}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Millisecond)
+	defer cancel()
+
+	generator := New(&Template{Path: tmpTemplateDir}, slow)
+	err := generator.Generate(ctx, t.TempDir(), map[string]interface{}{}, Options{Log: io.Discard})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Generate() error = %v, want a deadline error", err)
+	}
+	if files := generator.Summary().Files; files == 0 || files >= 5 {
+		t.Errorf("Summary().Files = %d, want generation stopped part-way", files)
+	}
+}
+
+func TestGenerator_TimeoutCleanup(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for i, content := range []string{"first", "second", "block", "never"} {
+		name := filepath.Join(tmpTemplateDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	// The third file renders only once the earlier ones are on disk, then
+	// hangs until the deadline, like a stuck render step. Generation stops
+	// before the file after it.
+	outputDir := filepath.Join(t.TempDir(), "out")
+	var sawWritten bool
+	eng := &MockEngine{renderFunc: func(tmpl string, context map[string]interface{}) (string, error) {
+		if tmpl != "block" {
+			return tmpl, nil
+		}
+		for ctx.Err() == nil && !sawWritten {
+			_, err1 := os.Stat(filepath.Join(outputDir, "file0.txt"))
+			_, err2 := os.Stat(filepath.Join(outputDir, "file1.txt"))
+			sawWritten = err1 == nil && err2 == nil
+			time.Sleep(time.Millisecond)
+		}
+		<-ctx.Done()
+		return tmpl, nil
+	}}
+
+	generator := New(&Template{Path: tmpTemplateDir}, eng)
+	err := generator.Generate(ctx, outputDir, map[string]interface{}{}, Options{Log: io.Discard, Jobs: 1})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Generate() error = %v, want a deadline error", err)
+	}
+	if !sawWritten {
+		t.Fatal("The files before the hanging one should be written before the deadline")
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("The partially generated output should be removed, stat error = %v", err)
+	}
+}
+
+func TestGenerator_Cancel(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		name := filepath.Join(tmpTemplateDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(name, []byte("{{ name }}"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	// Cancel while the first file is rendered, as Ctrl-C would
+	eng := &MockEngine{renderFunc: func(tmpl string, context map[string]interface{}) (string, error) {
+		cancel()
+		return tmpl, nil
+	}}
+
+	tmpOutputDir := t.TempDir()
+	generator := New(&Template{Path: tmpTemplateDir}, eng)
+	err := generator.Generate(ctx, tmpOutputDir, map[string]interface{}{}, Options{Log: io.Discard})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Generate() error = %v, want a cancellation error", err)
+	}
+	if files := generator.Summary().Files; files != 1 {
+		t.Errorf("Summary().Files = %d, want only the first file written", files)
+	}
+	for _, name := range []string{"file1.txt", "file2.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpOutputDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be generated after cancellation", name)
+		}
+	}
+}
+
+func TestGenerator_DebugLog(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for name, content := range map[string]string{
+		".secrets":     "token",
+		"README.md":    "{{ name }}",
+		"main.go.tmpl": "package {{ name }}",
+		"Makefile.got": "# {{ .name }}",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	var logs bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(original)
+
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Engines: map[string]string{".got": "go"}},
+	}
+	generator := New(tmpl, engine.NewPongo2Engine())
+	err := generator.Generate(t.Context(), t.TempDir(), map[string]interface{}{"name": "demo"}, Options{Log: io.Discard})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`level=DEBUG msg="skipped hidden path" path=.secrets`,
+		`msg="rendering file" path=main.go.tmpl output=main.go engine=pongo2`,
+		`msg="rendering file" path=Makefile.got output=Makefile engine=go`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("debug log missing %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestGenerator_DotfilePrefix(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	files := map[string]string{
+		"dot_env":                      "NAME={{ name }}",
+		"dot_gitignore":                "bin/",
+		"dot_github/workflows/ci.yml":  "name: {{ name }}",
+		"config/dot_editorconfig.tmpl": "root = true",
+		"dot_":                         "prefix only",
+		"docs/dot_product.md":          "kept with a custom prefix",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config *template.Config
+		want   []string
+	}{
+		{
+			name: "default prefix",
+			want: []string{".env", ".gitignore", ".github/workflows/ci.yml", "config/.editorconfig", "dot_", "docs/.product.md"},
+		},
+		{
+			name:   "custom prefix",
+			config: &template.Config{DotfilePrefix: stringPtr("_dot.")},
+			want:   []string{"dot_env", "dot_gitignore", "dot_github/workflows/ci.yml", "docs/dot_product.md"},
+		},
+		{
+			name:   "disabled",
+			config: &template.Config{DotfilePrefix: stringPtr("")},
+			want:   []string{"dot_env", "config/dot_editorconfig"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpOutputDir := t.TempDir()
+			generator := New(&Template{Path: tmpTemplateDir, Config: tt.config}, &MockEngine{})
+			if err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{"name": "demo"}, Options{Log: io.Discard}); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+			for _, name := range tt.want {
+				if _, err := os.Stat(filepath.Join(tmpOutputDir, name)); err != nil {
+					t.Errorf("%s was not created: %v", name, err)
+				}
+			}
+		})
+	}
+
+	// dot_env renders like any other file
+	tmpOutputDir := t.TempDir()
+	if err := New(&Template{Path: tmpTemplateDir}, &MockEngine{}).Generate(t.Context(), tmpOutputDir, map[string]interface{}{"name": "demo"}, Options{Log: io.Discard}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tmpOutputDir, ".env")); string(content) != "NAME=demo" {
+		t.Errorf(".env = %q, want %q", content, "NAME=demo")
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestGenerator_HiddenFiles(t *testing.T) {
+	files := map[string]string{
+		".github/workflows/ci.yml": "name: ci",
+		".editorconfig":            "root = true",
+		".secrets/token":           "hunter2",
+		".env":                     "TOKEN=x",
+		".git/HEAD":                "ref: refs/heads/main",
+		"README.md":                "# readme",
+	}
+
+	tests := []struct {
+		name    string
+		config  *template.Config
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "defaults keep common dotfiles",
+			config:  nil,
+			present: []string{".github/workflows/ci.yml", ".editorconfig", "README.md"},
+			absent:  []string{".secrets/token", ".env", ".git/HEAD"},
+		},
+		{
+			name:    "include_hidden keeps everything but .git",
+			config:  &template.Config{IncludeHidden: true},
+			present: []string{".github/workflows/ci.yml", ".secrets/token", ".env"},
+			absent:  []string{".git/HEAD"},
+		},
+		{
+			name:    "hidden_allow adds names",
+			config:  &template.Config{HiddenAllow: []string{".env"}},
+			present: []string{".env", ".github/workflows/ci.yml"},
+			absent:  []string{".secrets/token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpTemplateDir := t.TempDir()
+			for name, content := range files {
+				path := filepath.Join(tmpTemplateDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			generator := New(&Template{Path: tmpTemplateDir, Config: tt.config}, &MockEngine{})
+			tmpOutputDir := t.TempDir()
+			if err := generator.Generate(t.Context(), tmpOutputDir, map[string]interface{}{}, Options{}); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			for _, name := range tt.present {
+				if _, err := os.Stat(filepath.Join(tmpOutputDir, name)); err != nil {
+					t.Errorf("%s should be generated: %v", name, err)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, err := os.Stat(filepath.Join(tmpOutputDir, name)); !os.IsNotExist(err) {
+					t.Errorf("%s should be skipped", name)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_Generate_TarStream(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create template dir: %v", err)
+	}
+	files := map[string]string{
+		"README.md":   "# {{ name }}",
+		"src/main.go": "package {{ name }}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{Path: tmpTemplateDir}, &MockEngine{})
+
+	// The output path is ignored when streaming
+	outputPath := filepath.Join(t.TempDir(), "unused")
+
+	var buf bytes.Buffer
+	err := generator.Generate(t.Context(), outputPath, map[string]interface{}{"name": "demo"}, Options{Stream: &buf})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("Generate() with a stream should not touch the output path")
+	}
+
+	got := make(map[string]string)
+	dirs := make(map[string]bool)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar stream: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			dirs[hdr.Name] = true
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", hdr.Name, err)
+		}
+		got[hdr.Name] = string(content)
+	}
+
+	want := map[string]string{
+		"README.md":   "# demo",
+		"src/main.go": "package demo",
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("tar entry %s = %q, want %q", name, got[name], content)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("tar stream has %d files, want %d: %v", len(got), len(want), got)
+	}
+	if !dirs["src/"] {
+		t.Error("tar stream is missing the src/ directory entry")
+	}
+}
+
+func TestGenerator_IncludeExclude(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	for _, dir := range []string{"docs", "src", "build"} {
+		if err := os.MkdirAll(filepath.Join(tmpTemplateDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	for _, name := range []string{"README.md", "docs/guide.md", "docs/draft.md", "src/main.go", "build/out.md"} {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		ignore []string
+		opts   Options
+		want   []string
+	}{
+		{
+			name: "no filters",
+			want: []string{"README.md", "build/out.md", "docs/draft.md", "docs/guide.md", "src/main.go"},
+		},
+		{
+			name: "include markdown",
+			opts: Options{Include: []string{"*.md"}},
+			want: []string{"README.md", "build/out.md", "docs/draft.md", "docs/guide.md"},
+		},
+		{
+			name: "exclude directory",
+			opts: Options{Exclude: []string{"docs"}},
+			want: []string{"README.md", "build/out.md", "src/main.go"},
+		},
+		{
+			name: "include with exclude",
+			opts: Options{Include: []string{"docs/**"}, Exclude: []string{"draft.md"}},
+			want: []string{"docs/guide.md"},
+		},
+		{
+			name:   "include composes with template ignore",
+			ignore: []string{"build/**"},
+			opts:   Options{Include: []string{"*.md"}},
+			want:   []string{"README.md", "docs/draft.md", "docs/guide.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := &Template{
+				Path:   tmpTemplateDir,
+				Config: &template.Config{Ignore: tt.ignore},
+			}
+			sink := NewInMemorySink()
+			tt.opts.Sink = sink
+
+			if err := New(tmpl, &MockEngine{}).Generate(t.Context(), "", map[string]interface{}{}, tt.opts); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			if got := sink.Paths(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generated %v, want %v", got, tt.want)
+			}
+
+			// Directories are only created for included files
+			if len(tt.opts.Include) > 0 && len(sink.Dirs) > 0 {
+				t.Errorf("include run created directories %v", sink.Dirs)
+			}
+		})
+	}
+}
+
+func TestGenerator_IgnorePatterns(t *testing.T) {
+	files := []string{
+		"README.md", "app.log", "logs/2024/debug.log", "src/main.go",
+		"node_modules/pkg/index.js", "config.yml", "config.yaml", "config.json",
+	}
+
+	tests := []struct {
+		name       string
+		ignore     []string
+		ignoreFile string
+		want       []string
+	}{
+		{
+			name:   "recursive glob matches nested files",
+			ignore: []string{"**/*.log"},
+			want:   []string{"README.md", "config.json", "config.yaml", "config.yml", "node_modules/pkg/index.js", "src/main.go"},
+		},
+		{
+			name:   "trailing ** excludes a subtree",
+			ignore: []string{"node_modules/**"},
+			want:   []string{"README.md", "app.log", "config.json", "config.yaml", "config.yml", "logs/2024/debug.log", "src/main.go"},
+		},
+		{
+			name:   "brace set",
+			ignore: []string{"config.{yml,yaml}"},
+			want:   []string{"README.md", "app.log", "config.json", "logs/2024/debug.log", "node_modules/pkg/index.js", "src/main.go"},
+		},
+		{
+			name:       "ignore file adds to the config",
+			ignore:     []string{"**/*.log"},
+			ignoreFile: "# dependencies\nnode_modules/**\n\n*.{yml,yaml}\n",
+			want:       []string{"README.md", "config.json", "src/main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpTemplateDir := t.TempDir()
+			for _, name := range files {
+				path := filepath.Join(tmpTemplateDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+			if tt.ignoreFile != "" {
+				if err := os.WriteFile(filepath.Join(tmpTemplateDir, template.IgnoreFileName), []byte(tt.ignoreFile), 0644); err != nil {
+					t.Fatalf("Failed to create ignore file: %v", err)
+				}
+			}
+
+			tmpl := &Template{
+				Path:   tmpTemplateDir,
+				Config: &template.Config{Ignore: tt.ignore},
+			}
+			sink := NewInMemorySink()
+
+			if err := New(tmpl, &MockEngine{}).Generate(t.Context(), "", map[string]interface{}{}, Options{Sink: sink}); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			if got := sink.Paths(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generated %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_PruneEmptyDirs(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	for _, name := range []string{"src/main.go", "docs/drafts/notes.md", "scratch/todo.md"} {
+		path := filepath.Join(tmpTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tmpTemplateDir, "logs"), 0755); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Ignore: []string{"*.md"}},
+	}
+
+	tests := []struct {
+		name  string
+		prune bool
+		dirs  []string
+		gone  []string
+	}{
+		{
+			name: "without pruning",
+			dirs: []string{"src", "docs/drafts", "scratch", "logs"},
+		},
+		{
+			name:  "pruned",
+			prune: true,
+			dirs:  []string{"src", "logs"},
+			gone:  []string{"docs", "scratch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			opts := Options{Log: io.Discard, PruneEmptyDirs: tt.prune}
+			if err := New(tmpl, &MockEngine{}).Generate(t.Context(), outputDir, map[string]interface{}{}, opts); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			for _, dir := range tt.dirs {
+				if info, err := os.Stat(filepath.Join(outputDir, dir)); err != nil || !info.IsDir() {
+					t.Errorf("%s should be a directory: %v", dir, err)
+				}
+			}
+			for _, dir := range tt.gone {
+				if _, err := os.Stat(filepath.Join(outputDir, dir)); !os.IsNotExist(err) {
+					t.Errorf("%s should have been pruned", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_EmptyDirectories(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "logs"), 0755); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "cache"), 0755); err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpTemplateDir, "cache", ".gitkeep"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create .gitkeep: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		marker bool
+	}{
+		{name: "default", marker: true},
+		{name: "pruned", opts: Options{PruneEmptyDirs: true}, marker: true},
+		{name: "pruned without the marker", opts: Options{PruneEmptyDirs: true, Exclude: []string{".gitkeep"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			tt.opts.Log = io.Discard
+			tmpl := &Template{Path: tmpTemplateDir}
+			if err := New(tmpl, &MockEngine{}).Generate(t.Context(), outputDir, map[string]interface{}{}, tt.opts); err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			for _, dir := range []string{"logs", "cache"} {
+				if info, err := os.Stat(filepath.Join(outputDir, dir)); err != nil || !info.IsDir() {
+					t.Errorf("%s should be created: %v", dir, err)
+				}
+			}
+			_, err := os.Stat(filepath.Join(outputDir, "cache", ".gitkeep"))
+			if tt.marker && err != nil {
+				t.Errorf("cache/.gitkeep should be generated: %v", err)
+			}
+			if !tt.marker && !os.IsNotExist(err) {
+				t.Error("excluded cache/.gitkeep should not be generated")
+			}
+		})
+	}
+}
+
+func TestGenerator_Summary(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "src", "pkg"), 0755); err != nil {
+		t.Fatalf("Failed to create template dirs: %v", err)
+	}
+	files := map[string]string{
+		"README.md":       "# {{ name }}",
+		"src/pkg/file.go": "package pkg",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{Path: tmpTemplateDir}, &MockEngine{})
+	outputPath := filepath.Join(t.TempDir(), "out")
+
+	if err := generator.Generate(t.Context(), outputPath, map[string]interface{}{"name": "demo"}, Options{}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	summary := generator.Summary()
+	if summary.Files != 2 {
+		t.Errorf("Summary().Files = %d, want 2", summary.Files)
+	}
+	// The output root itself isn't counted
+	if summary.Directories != 2 {
+		t.Errorf("Summary().Directories = %d, want 2", summary.Directories)
+	}
+	if want := int64(len("# demo") + len("package pkg")); summary.Bytes != want {
+		t.Errorf("Summary().Bytes = %d, want %d", summary.Bytes, want)
+	}
+	if summary.Duration <= 0 {
+		t.Errorf("Summary().Duration = %v, want > 0", summary.Duration)
+	}
+
+	// A dry run writes nothing
+	if err := generator.Generate(t.Context(), outputPath, map[string]interface{}{}, Options{DryRun: true}); err != nil {
+		t.Fatalf("Generate() dry run failed: %v", err)
+	}
+	if summary := generator.Summary(); summary.Files != 0 || summary.Directories != 0 || summary.Bytes != 0 {
+		t.Errorf("Summary() after dry run = %+v, want no writes", summary)
+	}
+}
+
+func TestGenerator_Extends(t *testing.T) {
+	baseDir := t.TempDir()
+	childDir := t.TempDir()
+
+	write := func(dir, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	write(baseDir, "LICENSE", "MIT {{ name }}")
+	write(baseDir, "README.md", "base readme")
+	write(baseDir, "ci/build.yml", "base ci")
+	write(baseDir, "skip.txt", "ignored by base")
+	write(childDir, "README.md.tmpl", "child readme for {{ name }}")
+	write(childDir, "main.go", "package main")
+
+	tmpl := &Template{
+		Path: childDir,
+		Base: &Template{
+			Path:   baseDir,
+			Config: &template.Config{Ignore: []string{"skip.txt"}},
+		},
+	}
+	sink := NewInMemorySink()
+
+	err := New(tmpl, &MockEngine{}).Generate(t.Context(), "", map[string]interface{}{"name": "demo"}, Options{Sink: sink})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"LICENSE":      "MIT demo",
+		"README.md":    "child readme for demo",
+		"ci/build.yml": "base ci",
+		"main.go":      "package main",
+	}
+	if got := sink.Paths(); len(got) != len(want) {
+		t.Errorf("generated %v, want %d files", got, len(want))
+	}
+	for path, content := range want {
+		if got := string(sink.Files[path].Content); got != content {
+			t.Errorf("Files[%q] = %q, want %q", path, got, content)
+		}
+	}
+}
+
+func TestGenerator_Includes(t *testing.T) {
+	parentDir := t.TempDir()
+	serviceDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(parentDir, "README.md"), []byte("# {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create parent file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(serviceDir, "main.go"), []byte("// {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create service file: %v", err)
+	}
+
+	tmpl := &Template{
+		Path: parentDir,
+		Includes: []Inclusion{{
+			Template: &Template{Path: serviceDir},
+			Path:     "services/{{ service }}",
+			Vars:     map[string]interface{}{"name": "{{ service }}-svc"},
+		}},
+	}
+	sink := NewInMemorySink()
+	context := map[string]interface{}{"name": "mono", "service": "api"}
+
+	if err := New(tmpl, &MockEngine{}).Generate(t.Context(), "out", context, Options{Sink: sink}); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"out/README.md":            "# mono",
+		"out/services/api/main.go": "// api-svc",
+	}
+	if got := sink.Paths(); len(got) != len(want) {
+		t.Errorf("generated %v, want %d files", got, len(want))
+	}
+	for path, content := range want {
+		if got := string(sink.Files[path].Content); got != content {
+			t.Errorf("Files[%q] = %q, want %q", path, got, content)
+		}
+	}
+
+	// The parent's variables are not changed by the inclusion
+	if context["name"] != "mono" {
+		t.Errorf("context[name] = %v, want it unchanged", context["name"])
+	}
+}
+
+func TestGenerator_IncludePathEscape(t *testing.T) {
+	parentDir := t.TempDir()
+	serviceDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(serviceDir, "main.go"), []byte("// {{ name }}"), 0644); err != nil {
+		t.Fatalf("Failed to create service file: %v", err)
+	}
+
+	tmpl := &Template{
+		Path: parentDir,
+		Includes: []Inclusion{{
+			Template: &Template{Path: serviceDir},
+			Path:     "{{ dir }}",
+		}},
+	}
+
+	for _, dir := range []string{"../../x", "/tmp/x", ""} {
+		t.Run(dir, func(t *testing.T) {
+			sink := NewInMemorySink()
+			vars := map[string]interface{}{"name": "mono", "dir": dir}
+
+			err := New(tmpl, &MockEngine{}).Generate(t.Context(), "out", vars, Options{Sink: sink})
+			if err == nil || !strings.Contains(err.Error(), "not a path inside the output directory") {
+				t.Errorf("Generate() error = %v, want the include path refused", err)
+			}
+			if paths := sink.Paths(); len(paths) != 0 {
+				t.Errorf("generated %v, want nothing written outside the output directory", paths)
+			}
+		})
+	}
+}
+
+func TestGenerator_RenderErrorNamesFile(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "cmd"), 0755); err != nil {
+		t.Fatalf("Failed to create template dir: %v", err)
+	}
+	broken := "package main\n\nfunc main() { {{ name }\n"
+	if err := os.WriteFile(filepath.Join(tmpTemplateDir, "cmd", "main.go"), []byte(broken), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	generator := New(&Template{Path: tmpTemplateDir}, engine.NewPongo2Engine())
+	err := generator.Generate(t.Context(), "", map[string]interface{}{"name": "x"}, Options{Sink: NewInMemorySink()})
+	if err == nil {
+		t.Fatal("Expected error for broken template, got nil")
+	}
+
+	want := filepath.Join("cmd", "main.go") + ":3:"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Generate() error = %q, want it to contain %q", err.Error(), want)
+	}
+}
+
+func TestGenerator_Verbose(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create template dirs: %v", err)
+	}
+	for _, name := range []string{"src/main.go", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	for _, verbose := range []bool{false, true} {
+		var log bytes.Buffer
+		generator := New(&Template{Path: tmpTemplateDir}, &MockEngine{})
+		opts := Options{Verbose: verbose, Exclude: []string{"notes.txt"}, Log: &log}
+
+		if err := generator.Generate(t.Context(), filepath.Join(t.TempDir(), "out"), map[string]interface{}{}, opts); err != nil {
+			t.Fatalf("Generate() failed: %v", err)
+		}
+
+		output := log.String()
+		for _, want := range []string{"📁 Created directory: src", "Excluded: notes.txt"} {
+			if got := strings.Contains(output, want); got != verbose {
+				t.Errorf("Verbose=%v: output contains %q = %v, want %v\n%s", verbose, want, got, verbose, output)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+
+	"github.com/madstone-tech/ason/internal/engine"
+)
+
+// fileTask is a template file to generate. Its content is read and rendered
+// by a worker, then written by processFiles once done is closed.
+type fileTask struct {
+	srcPath     string
+	destPath    string
+	relPath     string
+	destRelPath string
+	render      bool
+	mode        fs.FileMode
+	engine      engine.Engine
+	engineName  string
+
+	content []byte
+	err     error
+	done    chan struct{}
+}
+
+// processFiles renders tasks on up to g.jobs workers and writes them to the
+// sink in order, so output, logging, conflict prompts and the first error
+// reported don't depend on scheduling. Workers only run a bounded distance
+// ahead of the writer to limit the rendered content held in memory.
+func (g *Generator) processFiles(ctx context.Context, tasks []*fileTask, vars map[string]interface{}) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	jobs := make(chan *fileTask)
+	ahead := make(chan struct{}, 2*g.jobs)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range min(g.jobs, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range jobs {
+				task.content, task.err = renderFile(task, vars)
+				close(task.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, task := range tasks {
+			select {
+			case ahead <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- task:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	for _, task := range tasks {
+		// Stop between files once the caller gives up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		<-task.done
+		if err := g.writeFile(task); err != nil {
+			return err
+		}
+		task.content = nil
+		<-ahead
+	}
+	return nil
+}
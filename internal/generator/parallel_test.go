@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// writeLargeTemplate creates a template with many small files spread over
+// several directories, mixing rendered, Go-rendered and binary files
+func writeLargeTemplate(t *testing.T, files int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i := range files {
+		name := filepath.Join(fmt.Sprintf("dir%d", i%10), fmt.Sprintf("file%03d.txt", i))
+		content := fmt.Sprintf("{{ name }} %d\n", i)
+		switch i % 3 {
+		case 1:
+			name = strings.TrimSuffix(name, ".txt") + ".gotmpl"
+			content = fmt.Sprintf("{{ .name }} %d\n", i)
+		case 2:
+			name = strings.TrimSuffix(name, ".txt") + ".png"
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestGenerator_Jobs(t *testing.T) {
+	tmpTemplateDir := writeLargeTemplate(t, 300)
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Engines: map[string]string{".gotmpl": engine.Go}},
+	}
+	context := map[string]interface{}{"name": "demo"}
+
+	generate := func(jobs int) (*InMemorySink, string) {
+		t.Helper()
+		sink := NewInMemorySink()
+		var log bytes.Buffer
+		opts := Options{Sink: sink, Log: &log, Jobs: jobs}
+		if err := New(tmpl, engine.NewPongo2Engine()).Generate(t.Context(), "", context, opts); err != nil {
+			t.Fatalf("Generate() with %d jobs failed: %v", jobs, err)
+		}
+		return sink, log.String()
+	}
+
+	sequential, sequentialLog := generate(1)
+	if len(sequential.Files) != 300 {
+		t.Fatalf("generated %d files, want 300", len(sequential.Files))
+	}
+	if got := string(sequential.Files["dir1/file001"].Content); got != "demo 1\n" {
+		t.Errorf("dir1/file001 = %q, want the Go-rendered content", got)
+	}
+	if got := string(sequential.Files["dir0/file000.txt"].Content); got != "demo 0\n" {
+		t.Errorf("dir0/file000.txt = %q, want the Pongo2-rendered content", got)
+	}
+
+	// Run with: go test -race
+	parallel, parallelLog := generate(8)
+	if !reflect.DeepEqual(parallel.Files, sequential.Files) {
+		t.Error("concurrent generation produced different files")
+	}
+	if parallelLog != sequentialLog {
+		t.Error("concurrent generation logged in a different order")
+	}
+}
+
+func TestGenerator_Jobs_FirstErrorInOrder(t *testing.T) {
+	tmpTemplateDir := writeLargeTemplate(t, 50)
+	for _, name := range []string{"dir3/file013.txt", "dir7/file027.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte("{% if %}"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	tmpl := &Template{
+		Path:   tmpTemplateDir,
+		Config: &template.Config{Engines: map[string]string{".gotmpl": engine.Go}},
+	}
+
+	for range 5 {
+		sink := NewInMemorySink()
+		opts := Options{Sink: sink, Log: &bytes.Buffer{}, Jobs: 8}
+		err := New(tmpl, engine.NewPongo2Engine()).Generate(t.Context(), "", map[string]interface{}{"name": "demo"}, opts)
+		if err == nil || !strings.Contains(err.Error(), "file013.txt") {
+			t.Fatalf("Generate() error = %v, want the first failing file in template order", err)
+		}
+		var renderErr *engine.RenderError
+		if !errors.As(err, &renderErr) {
+			t.Errorf("Generate() error = %v, want a RenderError", err)
+		}
+		if _, ok := sink.Files["dir7/file027.txt"]; ok {
+			t.Error("files after the failing one should not be written")
+		}
+	}
+}
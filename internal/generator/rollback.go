@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// undoStep reverses one filesystem change: removing a path ason created,
+// or moving a renamed file back
+type undoStep struct {
+	path string
+	// from is set for renames and is where path is moved back to
+	from string
+}
+
+// rollbackSink writes to the filesystem and records the files and
+// directories it creates, so a failed generation can remove them again.
+// Existing files it overwrites keep their new content.
+type rollbackSink struct {
+	FilesystemSink
+	undo []undoStep
+}
+
+func (s *rollbackSink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	s.recordMissingDirs(filepath.Dir(path))
+	_, statErr := os.Lstat(path)
+	if err := s.FilesystemSink.WriteFile(path, mode, content); err != nil {
+		return err
+	}
+	if errors.Is(statErr, fs.ErrNotExist) {
+		s.undo = append(s.undo, undoStep{path: path})
+	}
+	return nil
+}
+
+func (s *rollbackSink) CopyFile(src, path string, mode fs.FileMode) error {
+	s.recordMissingDirs(filepath.Dir(path))
+	_, statErr := os.Lstat(path)
+	if err := s.FilesystemSink.CopyFile(src, path, mode); err != nil {
+		return err
+	}
+	if errors.Is(statErr, fs.ErrNotExist) {
+		s.undo = append(s.undo, undoStep{path: path})
+	}
+	return nil
+}
+
+func (s *rollbackSink) Mkdir(path string, mode fs.FileMode) error {
+	s.recordMissingDirs(path)
+	return s.FilesystemSink.Mkdir(path, mode)
+}
+
+func (s *rollbackSink) Rename(oldPath, newPath string) error {
+	if err := s.FilesystemSink.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	s.undo = append(s.undo, undoStep{path: newPath, from: oldPath})
+	return nil
+}
+
+// recordMissingDirs records dir and those of its parents that don't exist
+// yet, outermost first, as they are about to be created
+func (s *rollbackSink) recordMissingDirs(dir string) {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		s.undo = append(s.undo, undoStep{path: missing[i]})
+	}
+}
+
+// Rollback undoes the recorded changes, newest first. Directories are only
+// removed once empty, so files ason didn't create are never deleted.
+func (s *rollbackSink) Rollback() error {
+	var errs []error
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		step := s.undo[i]
+		var err error
+		if step.from != "" {
+			err = os.Rename(step.path, step.from)
+		} else {
+			err = os.Remove(step.path)
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	s.undo = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingTemplate returns a template whose last file fails to render, after
+// a file and a directory have been generated
+func failingTemplate(t *testing.T) (*Template, *MockEngine) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":    "first",
+		"b/c.txt":  "second",
+		"z.txt":    "fail",
+		"keep.txt": "third",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	eng := &MockEngine{renderFunc: func(tmpl string, context map[string]interface{}) (string, error) {
+		if tmpl == "fail" {
+			return "", errors.New("render failed")
+		}
+		return tmpl, nil
+	}}
+	return &Template{Path: dir}, eng
+}
+
+func TestGenerator_RollbackNewOutput(t *testing.T) {
+	tmpl, eng := failingTemplate(t)
+	parent := filepath.Join(t.TempDir(), "projects")
+	outputDir := filepath.Join(parent, "demo")
+
+	err := New(tmpl, eng).Generate(t.Context(), outputDir, map[string]interface{}{}, Options{Log: io.Discard})
+	if err == nil {
+		t.Fatal("Generate() should fail")
+	}
+
+	// Both the output directory and the parent created for it are gone
+	if _, err := os.Stat(parent); !os.IsNotExist(err) {
+		t.Errorf("%s should be removed after a failed generation", parent)
+	}
+}
+
+func TestGenerator_RollbackExistingOutput(t *testing.T) {
+	tmpl, eng := failingTemplate(t)
+	outputDir := t.TempDir()
+
+	// The user's own files, including one the template overwrites
+	existing := map[string]string{
+		"notes.md": "mine",
+		"b/old.md": "mine too",
+		"a.txt":    "overwritten",
+	}
+	for name, content := range existing {
+		path := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	err := New(tmpl, eng).Generate(t.Context(), outputDir, map[string]interface{}{}, Options{Log: io.Discard})
+	if err == nil {
+		t.Fatal("Generate() should fail")
+	}
+
+	for _, name := range []string{"notes.md", "b/old.md", "a.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("existing %s should be kept: %v", name, err)
+		}
+	}
+	for _, name := range []string{"b/c.txt", "keep.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); !os.IsNotExist(err) {
+			t.Errorf("generated %s should be removed", name)
+		}
+	}
+}
+
+func TestGenerator_NoCleanup(t *testing.T) {
+	tmpl, eng := failingTemplate(t)
+	outputDir := filepath.Join(t.TempDir(), "demo")
+
+	opts := Options{Log: io.Discard, NoCleanup: true}
+	if err := New(tmpl, eng).Generate(t.Context(), outputDir, map[string]interface{}{}, opts); err == nil {
+		t.Fatal("Generate() should fail")
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "a.txt")); err != nil {
+		t.Errorf("partial output should be kept with NoCleanup: %v", err)
+	}
+}
+
+func TestGenerator_RollbackRestoresBackups(t *testing.T) {
+	tmpl, eng := failingTemplate(t)
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "a.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatalf("Failed to create a.txt: %v", err)
+	}
+
+	opts := Options{Log: io.Discard, Overwrite: OverwriteBackup}
+	if err := New(tmpl, eng).Generate(t.Context(), outputDir, map[string]interface{}{}, opts); err == nil {
+		t.Fatal("Generate() should fail")
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(outputDir, "a.txt")); string(content) != "mine" {
+		t.Errorf("a.txt = %q, want the backed-up file restored", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "a.txt"+BackupSuffix)); !os.IsNotExist(err) {
+		t.Error("the backup should be moved back")
+	}
+}
@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/madstone-tech/ason/internal/fsutil"
+)
+
+// OutputSink is the destination generated files are written to
+type OutputSink interface {
+	WriteFile(path string, mode fs.FileMode, content []byte) error
+	Mkdir(path string, mode fs.FileMode) error
+}
+
+// FileCopier is implemented by sinks that can copy a file that needs no
+// rendering straight from the template, without holding it in memory
+type FileCopier interface {
+	CopyFile(src, path string, mode fs.FileMode) error
+}
+
+// copyFile copies src to path in sink, streaming it when the sink supports
+// that and reading it whole otherwise
+func copyFile(sink OutputSink, src, path string, mode fs.FileMode) error {
+	if copier, ok := sink.(FileCopier); ok {
+		return copier.CopyFile(src, path, mode)
+	}
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return sink.WriteFile(path, mode, content)
+}
+
+// FilesystemSink writes generated files to the local filesystem
+type FilesystemSink struct{}
+
+// WriteFile writes a file, creating its parent directories as needed. An
+// existing file replaced takes the new mode too.
+func (FilesystemSink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// CopyFile copies src to path, creating its parent directories as needed
+func (FilesystemSink) CopyFile(src, path string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsutil.CopyFile(src, path, mode)
+}
+
+// Mkdir creates a directory and any missing parents
+func (FilesystemSink) Mkdir(path string, mode fs.FileMode) error {
+	return os.MkdirAll(path, mode)
+}
+
+// MemoryFile is a file held by an InMemorySink
+type MemoryFile struct {
+	Mode    fs.FileMode
+	Content []byte
+}
+
+// InMemorySink collects generated files in memory, keyed by slash-separated path
+type InMemorySink struct {
+	Files map[string]MemoryFile
+	Dirs  map[string]fs.FileMode
+}
+
+// NewInMemorySink creates an empty in-memory sink
+func NewInMemorySink() *InMemorySink {
+	return &InMemorySink{
+		Files: make(map[string]MemoryFile),
+		Dirs:  make(map[string]fs.FileMode),
+	}
+}
+
+// WriteFile stores a copy of the file content
+func (s *InMemorySink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	s.Files[sinkKey(path)] = MemoryFile{
+		Mode:    mode,
+		Content: append([]byte(nil), content...),
+	}
+	return nil
+}
+
+// Mkdir records a directory
+func (s *InMemorySink) Mkdir(path string, mode fs.FileMode) error {
+	s.Dirs[sinkKey(path)] = mode
+	return nil
+}
+
+// Paths returns the paths of all stored files in sorted order
+func (s *InMemorySink) Paths() []string {
+	paths := make([]string, 0, len(s.Files))
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// TarSink writes generated files as entries of a tar stream. Close must be
+// called to finish the stream.
+type TarSink struct {
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+// NewTarSink creates a sink writing a tar stream to w
+func NewTarSink(w io.Writer) *TarSink {
+	return &TarSink{
+		tw:      tar.NewWriter(w),
+		modTime: time.Now(),
+	}
+}
+
+// WriteFile adds a regular file entry
+func (s *TarSink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	if err := s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     sinkKey(path),
+		Mode:     int64(mode.Perm()),
+		Size:     int64(len(content)),
+		ModTime:  s.modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := s.tw.Write(content)
+	return err
+}
+
+// CopyFile adds a regular file entry read from src
+func (s *TarSink) CopyFile(src, path string, mode fs.FileMode) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     sinkKey(path),
+		Mode:     int64(mode.Perm()),
+		Size:     info.Size(),
+		ModTime:  s.modTime,
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(s.tw, file)
+	return err
+}
+
+// Mkdir adds a directory entry
+func (s *TarSink) Mkdir(path string, mode fs.FileMode) error {
+	return s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     sinkKey(path) + "/",
+		Mode:     int64(mode.Perm()),
+		ModTime:  s.modTime,
+	})
+}
+
+// Close writes the tar footer
+func (s *TarSink) Close() error {
+	return s.tw.Close()
+}
+
+// countingSink wraps a sink and tallies what is written through it
+type countingSink struct {
+	OutputSink
+	summary *Summary
+}
+
+func (s *countingSink) WriteFile(path string, mode fs.FileMode, content []byte) error {
+	if err := s.OutputSink.WriteFile(path, mode, content); err != nil {
+		return err
+	}
+	s.summary.Files++
+	s.summary.Bytes += int64(len(content))
+	return nil
+}
+
+func (s *countingSink) CopyFile(src, path string, mode fs.FileMode) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := copyFile(s.OutputSink, src, path, mode); err != nil {
+		return err
+	}
+	s.summary.Files++
+	s.summary.Bytes += info.Size()
+	return nil
+}
+
+func (s *countingSink) Mkdir(path string, mode fs.FileMode) error {
+	if err := s.OutputSink.Mkdir(path, mode); err != nil {
+		return err
+	}
+	s.summary.Directories++
+	return nil
+}
+
+// sinkKey normalizes a path for sinks that don't write to the filesystem
+func sinkKey(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerator_Generate_InMemorySink(t *testing.T) {
+	tmpTemplateDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(tmpTemplateDir, "cmd", "{{ name }}"), 0755); err != nil {
+		t.Fatalf("Failed to create template dir: %v", err)
+	}
+	files := map[string]string{
+		"README.md":                   "# {{ name }}",
+		"cmd/{{ name }}/main.go.tmpl": "package main // {{ name }}",
+		"logo.png":                    "{{ not rendered }}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpTemplateDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	generator := New(&Template{Path: tmpTemplateDir}, &MockEngine{})
+	sink := NewInMemorySink()
+
+	err := generator.Generate(t.Context(), "out", map[string]interface{}{"name": "demo"}, Options{Sink: sink})
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	if _, err := os.Stat("out"); !os.IsNotExist(err) {
+		t.Error("Generate() with an in-memory sink should not touch the filesystem")
+	}
+
+	wantPaths := []string{"out/README.md", "out/cmd/demo/main.go", "out/logo.png"}
+	if got := sink.Paths(); !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("Paths() = %v, want %v", got, wantPaths)
+	}
+
+	wantContent := map[string]string{
+		"out/README.md":        "# demo",
+		"out/cmd/demo/main.go": "package main // demo",
+		"out/logo.png":         "{{ not rendered }}",
+	}
+	for path, want := range wantContent {
+		if got := string(sink.Files[path].Content); got != want {
+			t.Errorf("Files[%q] = %q, want %q", path, got, want)
+		}
+	}
+
+	for _, dir := range []string{"out", "out/cmd", "out/cmd/demo"} {
+		if _, ok := sink.Dirs[dir]; !ok {
+			t.Errorf("Dirs is missing %q: %v", dir, sink.Dirs)
+		}
+	}
+}
+
+func TestInMemorySink_CopiesContent(t *testing.T) {
+	sink := NewInMemorySink()
+	content := []byte("original")
+
+	if err := sink.WriteFile("./a/../file.txt", 0600, content); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	copy(content, "modified")
+
+	file, ok := sink.Files["file.txt"]
+	if !ok {
+		t.Fatalf("Files = %v, want a cleaned file.txt key", sink.Files)
+	}
+	if string(file.Content) != "original" {
+		t.Errorf("Content = %q, want %q", file.Content, "original")
+	}
+	if file.Mode != 0600 {
+		t.Errorf("Mode = %v, want %v", file.Mode, os.FileMode(0600))
+	}
+}
+
+func TestFilesystemSink(t *testing.T) {
+	tmpDir := t.TempDir()
+	sink := FilesystemSink{}
+
+	path := filepath.Join(tmpDir, "nested", "dir", "file.txt")
+	if err := sink.WriteFile(path, 0644, []byte("hello")); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read written file: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("File content = %q, want %q", got, "hello")
+	}
+
+	dir := filepath.Join(tmpDir, "empty")
+	if err := sink.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Mkdir() failed: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Mkdir() did not create %s", dir)
+	}
+}
+
+func TestTarSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewTarSink(&buf)
+
+	if err := sink.Mkdir("src", 0755); err != nil {
+		t.Fatalf("Mkdir() failed: %v", err)
+	}
+	if err := sink.WriteFile(filepath.Join("src", "main.go"), 0644, []byte("package main")); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	tr := tar.NewReader(&buf)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Failed to read directory entry: %v", err)
+	}
+	if hdr.Name != "src/" || hdr.Typeflag != tar.TypeDir {
+		t.Errorf("First entry = %q (type %c), want directory src/", hdr.Name, hdr.Typeflag)
+	}
+
+	hdr, err = tr.Next()
+	if err != nil {
+		t.Fatalf("Failed to read file entry: %v", err)
+	}
+	if hdr.Name != "src/main.go" || hdr.Mode != 0644 {
+		t.Errorf("Second entry = %q (mode %o), want src/main.go (mode 644)", hdr.Name, hdr.Mode)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("Failed to read file content: %v", err)
+	}
+	if string(content) != "package main" {
+		t.Errorf("File content = %q, want %q", content, "package main")
+	}
+}
+
+func TestFilesystemSink_CopyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	sink := FilesystemSink{}
+
+	src := filepath.Join(tmpDir, "logo.png")
+	if err := os.WriteFile(src, []byte("\x89PNG"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "nested", "dir", "logo.png")
+	if err := sink.CopyFile(src, path, 0755); err != nil {
+		t.Fatalf("CopyFile() failed: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read copied file: %v", err)
+	}
+	if string(got) != "\x89PNG" {
+		t.Errorf("File content = %q, want %q", got, "\x89PNG")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("Stat() = %v, %v; want mode 0755", info.Mode(), err)
+	}
+}
+
+func TestTarSink_CopyFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(src, []byte("\x89PNG"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	sink := NewTarSink(&buf)
+	if err := sink.CopyFile(src, filepath.Join("assets", "logo.png"), 0644); err != nil {
+		t.Fatalf("CopyFile() failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	tr := tar.NewReader(&buf)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Failed to read file entry: %v", err)
+	}
+	if hdr.Name != "assets/logo.png" || hdr.Size != 4 {
+		t.Errorf("Entry = %q (size %d), want assets/logo.png (size 4)", hdr.Name, hdr.Size)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("Failed to read file content: %v", err)
+	}
+	if string(content) != "\x89PNG" {
+		t.Errorf("File content = %q, want %q", content, "\x89PNG")
+	}
+}
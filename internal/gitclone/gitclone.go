@@ -0,0 +1,94 @@
+package gitclone
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Source is a Git repository and the ref to check out
+type Source struct {
+	// URL is what git clones
+	URL string
+
+	// Ref is a branch, tag or commit; empty means the default branch
+	Ref string
+}
+
+// String returns the source the way Parse reads it
+func (s Source) String() string {
+	if s.Ref == "" {
+		return s.URL
+	}
+	return s.URL + "#" + s.Ref
+}
+
+// urlSchemes are the URL prefixes Parse accepts
+var urlSchemes = []string{"https://", "http://", "ssh://", "git://", "file://"}
+
+// scpLike matches scp-style addresses such as git@github.com:owner/repo
+var scpLike = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// Parse reads a Git URL with an optional #ref suffix, such as
+// https://github.com/owner/repo.git#v1.2.0 or git@github.com:owner/repo. It
+// reports false for anything else, such as paths and template names.
+func Parse(arg string) (Source, bool) {
+	url, ref, _ := strings.Cut(arg, "#")
+
+	for _, scheme := range urlSchemes {
+		if strings.HasPrefix(url, scheme) && len(url) > len(scheme) {
+			return Source{URL: url, Ref: ref}, true
+		}
+	}
+	if scpLike.MatchString(url) {
+		return Source{URL: url, Ref: ref}, true
+	}
+	return Source{}, false
+}
+
+// Clone clones the source into dst, which must not exist or be empty, and
+// checks out its ref. Without a ref only the latest commit is fetched. Git
+// never prompts for credentials, so a private repository fails rather than
+// hangs.
+func Clone(ctx context.Context, src Source, dst string) error {
+	if src.Ref == "" {
+		if err := git(ctx, "", "clone", "--quiet", "--depth", "1", "--", src.URL, dst); err != nil {
+			return fmt.Errorf("failed to clone %s: %w", src.URL, err)
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(src.Ref, "-") {
+		return fmt.Errorf("invalid ref %q", src.Ref)
+	}
+
+	// Any ref, including a commit, can be checked out from a full clone
+	if err := git(ctx, "", "clone", "--quiet", "--no-checkout", "--", src.URL, dst); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", src.URL, err)
+	}
+	if err := git(ctx, dst, "checkout", "--quiet", src.Ref, "--"); err != nil {
+		return fmt.Errorf("failed to check out %s of %s: %w", src.Ref, src.URL, err)
+	}
+	return nil
+}
+
+// git runs a git command in dir, returning its error output on failure
+func git(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package gitclone
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want Source
+		ok   bool
+	}{
+		{"https://github.com/owner/repo.git", Source{URL: "https://github.com/owner/repo.git"}, true},
+		{"https://github.com/owner/repo#v1.2.0", Source{URL: "https://github.com/owner/repo", Ref: "v1.2.0"}, true},
+		{"git@github.com:owner/repo.git#main", Source{URL: "git@github.com:owner/repo.git", Ref: "main"}, true},
+		{"ssh://git@example.com/repo.git", Source{URL: "ssh://git@example.com/repo.git"}, true},
+		{"file:///srv/git/repo.git", Source{URL: "file:///srv/git/repo.git"}, true},
+		{"web-app", Source{}, false},
+		{"./templates/web-app", Source{}, false},
+		{"/srv/templates/web-app", Source{}, false},
+		{"https://", Source{}, false},
+		{"gh:owner/repo", Source{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.arg)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", tt.arg, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// newTestRemote creates a bare repository with two commits, the first tagged
+// v1, and returns its file:// URL
+func newTestRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "init.defaultBranch=main"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	work := t.TempDir()
+	run(work, "init", "--quiet")
+	for _, version := range []string{"v1", "v2"} {
+		if err := os.WriteFile(filepath.Join(work, "VERSION"), []byte(version), 0644); err != nil {
+			t.Fatalf("Failed to write VERSION: %v", err)
+		}
+		run(work, "add", "VERSION")
+		run(work, "commit", "--quiet", "-m", version)
+		if version == "v1" {
+			run(work, "tag", "v1")
+		}
+	}
+
+	bare := filepath.Join(t.TempDir(), "remote.git")
+	run(work, "clone", "--quiet", "--bare", work, bare)
+	return "file://" + bare
+}
+
+func TestClone(t *testing.T) {
+	url := newTestRemote(t)
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"default branch", "", "v2"},
+		{"tag", "v1", "v1"},
+		{"branch", "main", "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "clone")
+			if err := Clone(t.Context(), Source{URL: url, Ref: tt.ref}, dst); err != nil {
+				t.Fatalf("Clone() failed: %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(dst, "VERSION"))
+			if err != nil {
+				t.Fatalf("VERSION was not checked out: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("VERSION = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClone_Errors(t *testing.T) {
+	url := newTestRemote(t)
+
+	tests := []struct {
+		name string
+		src  Source
+	}{
+		{"missing repository", Source{URL: "file://" + filepath.Join(t.TempDir(), "missing.git")}},
+		{"unknown ref", Source{URL: url, Ref: "no-such-ref"}},
+		{"option as ref", Source{URL: url, Ref: "--orphan=x"}},
+		{"path as ref", Source{URL: url, Ref: "VERSION"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Clone(t.Context(), tt.src, filepath.Join(t.TempDir(), "clone")); err == nil {
+				t.Error("Clone() should fail")
+			}
+		})
+	}
+}
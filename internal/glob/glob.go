@@ -0,0 +1,124 @@
+package glob
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Match reports whether a slash-separated relative path matches pattern.
+//
+// Patterns use path.Match syntax per segment, plus `**` which matches any
+// number of path segments (including none) and `{a,b}` brace sets, which
+// match any of their comma-separated alternatives. A pattern without a slash
+// is matched against the last path segment only, so `*.md` matches
+// `docs/guide.md` as well as `README.md`.
+func Match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+
+	for _, expanded := range expandBraces(pattern) {
+		if matchPattern(expanded, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a slash-separated path against a pattern without
+// brace sets
+func matchPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAny reports whether name matches any of the patterns
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandBraces returns the patterns a pattern's `{a,b}` brace sets stand
+// for, expanding nested sets as well. An unclosed brace is kept literally.
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	// Find the matching close brace and the top-level commas between them
+	depth := 0
+	commas := []int{}
+	closing := -1
+	for i := open; i < len(pattern) && closing < 0; i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+	if closing < 0 {
+		return []string{pattern}
+	}
+
+	prefix, suffix := pattern[:open], pattern[closing+1:]
+	var alternatives []string
+	start := open + 1
+	for _, comma := range append(commas, closing) {
+		alternatives = append(alternatives, pattern[start:comma])
+		start = comma + 1
+	}
+
+	var expanded []string
+	for _, alt := range alternatives {
+		expanded = append(expanded, expandBraces(prefix+alt+suffix)...)
+	}
+	return expanded
+}
+
+// matchSegments matches path segments against pattern segments, expanding `**`
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive ** and try every possible split point
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,59 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide.md", true},
+		{"*.md", "main.go", false},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/guide.md", false},
+		{"**/*.yml", "ci.yml", true},
+		{"**/*.yml", ".github/workflows/ci.yml", true},
+		{"**/*.yml", ".github/workflows/ci.yaml", false},
+		{".github/**", ".github/workflows/ci.yml", true},
+		{".github/**", "src/main.go", false},
+		{"src/**/test_*.go", "src/a/b/test_x.go", true},
+		{"src/**/test_*.go", "src/test_x.go", true},
+		{"./build/*", "build/out", true},
+		{"**/*.log", "app.log", true},
+		{"**/*.log", "logs/2024/app.log", true},
+		{"node_modules/**", "node_modules", true},
+		{"node_modules/**", "node_modules/pkg/index.js", true},
+		{"node_modules/**", "src/node_modules.go", false},
+		{"*.{yml,yaml}", "ci.yaml", true},
+		{"*.{yml,yaml}", "ci.json", false},
+		{"{docs,examples}/**/*.md", "examples/basic/README.md", true},
+		{"{docs,examples}/**/*.md", "src/README.md", false},
+		{"src/*.{go,{ts,tsx}}", "src/app.tsx", true},
+		{"{unclosed", "{unclosed", true},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"*.log", "tmp/**"}
+
+	if !MatchAny(patterns, "logs/app.log") {
+		t.Error("MatchAny() should match *.log")
+	}
+	if !MatchAny(patterns, "tmp/cache/file") {
+		t.Error("MatchAny() should match tmp/**")
+	}
+	if MatchAny(patterns, "src/main.go") {
+		t.Error("MatchAny() should not match src/main.go")
+	}
+	if MatchAny(nil, "anything") {
+		t.Error("MatchAny() with no patterns should not match")
+	}
+}
@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ConfirmPrompt asks a yes/no question. y and n answer it; Enter takes the
+// default.
+type ConfirmPrompt struct {
+	question string
+	Default  bool
+	Answer   bool
+	done     bool
+}
+
+func NewConfirmPrompt(question string, defaultAnswer bool) ConfirmPrompt {
+	return ConfirmPrompt{question: question, Default: defaultAnswer}
+}
+
+// Done reports whether the question was answered, rather than abandoned
+// with Ctrl-C or Esc
+func (m ConfirmPrompt) Done() bool {
+	return m.done
+}
+
+func (m ConfirmPrompt) Init() tea.Cmd {
+	return nil
+}
+
+func (m ConfirmPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.Answer, m.done = m.Default, true
+		return m, tea.Quit
+	}
+
+	switch key.String() {
+	case "y", "Y":
+		m.Answer, m.done = true, true
+		return m, tea.Quit
+	case "n", "N":
+		m.Answer, m.done = false, true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m ConfirmPrompt) View() string {
+	if m.done {
+		return ""
+	}
+
+	choices := "y/N"
+	if m.Default {
+		choices = "Y/n"
+	}
+	return fmt.Sprintf("%s [%s]: ", m.question, choices)
+}
@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirmPrompt(t *testing.T) {
+	tests := []struct {
+		name       string
+		defaultYes bool
+		key        tea.KeyMsg
+		wantDone   bool
+		wantAnswer bool
+	}{
+		{"yes", false, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, true, true},
+		{"no", true, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")}, true, false},
+		{"enter takes default no", false, tea.KeyMsg{Type: tea.KeyEnter}, true, false},
+		{"enter takes default yes", true, tea.KeyMsg{Type: tea.KeyEnter}, true, true},
+		{"ctrl-c abandons", true, tea.KeyMsg{Type: tea.KeyCtrlC}, false, false},
+		{"other keys are ignored", false, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewConfirmPrompt("Overwrite README.md?", tt.defaultYes).Update(tt.key)
+			p := m.(ConfirmPrompt)
+			if p.Done() != tt.wantDone || p.Answer != tt.wantAnswer {
+				t.Errorf("Done, Answer = %v, %v; want %v, %v", p.Done(), p.Answer, tt.wantDone, tt.wantAnswer)
+			}
+		})
+	}
+}
+
+func TestConfirmPrompt_View(t *testing.T) {
+	if view := NewConfirmPrompt("Overwrite?", false).View(); view != "Overwrite? [y/N]: " {
+		t.Errorf("View() = %q", view)
+	}
+	if view := NewConfirmPrompt("Overwrite?", true).View(); view != "Overwrite? [Y/n]: " {
+		t.Errorf("View() = %q", view)
+	}
+}
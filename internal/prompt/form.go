@@ -0,0 +1,177 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// FormPrompt asks for several variables at once as a form. Tab and Down move
+// to the next field, Shift-Tab and Up to the previous one, and Enter moves
+// on or, on the last field, submits once every value fits its variable's
+// type, choices and validation pattern.
+// Each field is edited like a TextPrompt: defaults are hints used for fields
+// left empty, → copies the focused field's default in to edit, and ←, →,
+// Home and End move the cursor.
+type FormPrompt struct {
+	fields []formField
+	focus  int
+	err    string
+	done   bool
+}
+
+type formField struct {
+	variable template.Variable
+	input    TextPrompt
+}
+
+// NewFormPrompt creates a form for variables
+func NewFormPrompt(variables []template.Variable) FormPrompt {
+	fields := make([]formField, len(variables))
+	for i, v := range variables {
+		fields[i] = formField{variable: v, input: NewTextPrompt(v.PromptText(), v.Default)}
+	}
+	return FormPrompt{fields: fields}
+}
+
+// answer returns the field's value, or its default when left empty
+func (f formField) answer() string {
+	if f.input.Value == "" {
+		return formatDefault(f.variable.Default)
+	}
+	return f.input.Value
+}
+
+// formatDefault renders a default value as it would be typed, with list
+// items separated by commas
+func formatDefault(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprintf("%v", item)
+		}
+		return strings.Join(items, ",")
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Values returns the value of each variable by name, using the default for
+// fields left empty
+func (m FormPrompt) Values() map[string]string {
+	values := make(map[string]string, len(m.fields))
+	for _, f := range m.fields {
+		values[f.variable.Name] = f.answer()
+	}
+	return values
+}
+
+// Done reports whether the form was submitted, rather than abandoned with
+// Ctrl-C or Esc
+func (m FormPrompt) Done() bool {
+	return m.done
+}
+
+func (m FormPrompt) Init() tea.Cmd {
+	return nil
+}
+
+func (m FormPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.fields) == 0 {
+		return m, nil
+	}
+
+	switch key.Type {
+	case tea.KeyTab, tea.KeyDown:
+		m.focus = (m.focus + 1) % len(m.fields)
+	case tea.KeyShiftTab, tea.KeyUp:
+		m.focus = (m.focus + len(m.fields) - 1) % len(m.fields)
+	case tea.KeyEnter:
+		if m.focus < len(m.fields)-1 {
+			m.focus++
+			return m, nil
+		}
+		return m.submit()
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	default:
+		// Everything else edits the focused field
+		input, _ := m.fields[m.focus].input.Update(key)
+		m.fields[m.focus].input = input.(TextPrompt)
+	}
+	return m, nil
+}
+
+// submit finishes the form, or focuses the first field whose value
+// CheckAnswer refuses
+func (m FormPrompt) submit() (tea.Model, tea.Cmd) {
+	for i, f := range m.fields {
+		if err := CheckAnswer(f.variable, f.answer()); err != nil {
+			m.focus = i
+			m.err = err.Error()
+			return m, nil
+		}
+	}
+
+	m.err = ""
+	m.done = true
+	return m, tea.Quit
+}
+
+func (m FormPrompt) View() string {
+	if m.done {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, f := range m.fields {
+		cursor := "  "
+		if i == m.focus {
+			cursor = "> "
+		}
+
+		var hints []string
+		if t := f.variable.Type; t != "" && t != "string" && t != "password" {
+			hints = append(hints, t)
+		}
+		if def := formatDefault(f.variable.Default); def != "" && !f.variable.IsSecret() {
+			hints = append(hints, "default: "+def)
+		}
+		hint := ""
+		if len(hints) > 0 {
+			hint = " (" + strings.Join(hints, ", ") + ")"
+		}
+
+		value := f.input.Value
+		if f.variable.IsSecret() {
+			value = maskValue(value)
+		}
+		if i == m.focus {
+			value = renderInput([]rune(value), f.input.cursor())
+		}
+
+		fmt.Fprintf(&b, "%s%s%s: %s\n", cursor, f.variable.PromptText(), hint, value)
+	}
+
+	// Help is shown for the focused field only, to keep the form compact
+	if len(m.fields) > 0 {
+		if help := variableHelp(m.fields[m.focus].variable); len(help) > 0 {
+			fmt.Fprintf(&b, "%s\n", strings.TrimPrefix(renderHelp(help), "\n"))
+		}
+	}
+
+	if m.err != "" {
+		fmt.Fprintf(&b, "\n%s\n", m.err)
+	}
+	b.WriteString("\ntab: next field • shift+tab: previous • →: edit default • enter: submit\n")
+
+	return b.String()
+}
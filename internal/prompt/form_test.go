@@ -0,0 +1,204 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+func testFormVariables() []template.Variable {
+	return []template.Variable{
+		{Name: "project_name", Prompt: "Project name"},
+		{Name: "port", Type: "integer", Default: int64(8080)},
+		{Name: "regions", Type: "list", Default: []interface{}{"us-east-1", "us-west-2"}},
+	}
+}
+
+func typeInto(m tea.Model, text string) tea.Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return m
+}
+
+func press(m tea.Model, key tea.KeyType) (tea.Model, tea.Cmd) {
+	return m.Update(tea.KeyMsg{Type: key})
+}
+
+func TestNewFormPrompt_Defaults(t *testing.T) {
+	form := NewFormPrompt(testFormVariables())
+
+	values := form.Values()
+	want := map[string]string{"project_name": "", "port": "8080", "regions": "us-east-1,us-west-2"}
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("Values()[%s] = %q, want %q", name, values[name], value)
+		}
+	}
+	if form.Done() {
+		t.Error("Done() should be false initially")
+	}
+}
+
+func TestFormPrompt_Navigation(t *testing.T) {
+	var m tea.Model = NewFormPrompt(testFormVariables())
+
+	m, _ = press(m, tea.KeyTab)
+	if focus := m.(FormPrompt).focus; focus != 1 {
+		t.Errorf("After Tab, focus = %d, want 1", focus)
+	}
+
+	m, _ = press(m, tea.KeyShiftTab)
+	m, _ = press(m, tea.KeyShiftTab)
+	if focus := m.(FormPrompt).focus; focus != 2 {
+		t.Errorf("Shift-Tab from the first field should wrap to the last, focus = %d", focus)
+	}
+
+	m, _ = press(m, tea.KeyDown)
+	if focus := m.(FormPrompt).focus; focus != 0 {
+		t.Errorf("Down from the last field should wrap to the first, focus = %d", focus)
+	}
+
+	// Typing goes to the focused field only; → brings in a default to edit
+	m = typeInto(m, "demo")
+	m, _ = press(m, tea.KeyEnter)
+	m, _ = press(m, tea.KeyRight)
+	m, _ = press(m, tea.KeyBackspace)
+	if values := m.(FormPrompt).Values(); values["project_name"] != "demo" || values["port"] != "808" {
+		t.Errorf("Values() = %v, want project_name=demo and port=808", values)
+	}
+
+	if view := m.View(); !strings.Contains(view, "> port (integer, default: 8080): 808") {
+		t.Errorf("View() should mark the focused field, got:\n%s", view)
+	}
+}
+
+func TestFormPrompt_Cursor(t *testing.T) {
+	var m tea.Model = NewFormPrompt(testFormVariables())
+	m = typeInto(m, "héé")
+
+	// Cursor keys move within the value rather than typing their names
+	m, _ = press(m, tea.KeyLeft)
+	m, _ = press(m, tea.KeyBackspace)
+	if value := m.(FormPrompt).Values()["project_name"]; value != "hé" {
+		t.Errorf("After ← and Backspace, value = %q, want %q", value, "hé")
+	}
+
+	m, _ = press(m, tea.KeyHome)
+	m, _ = press(m, tea.KeyDelete)
+	m, _ = press(m, tea.KeyCtrlB)
+	m = typeInto(m, "ç")
+	m, _ = press(m, tea.KeyEnd)
+	m = typeInto(m, "!")
+	if value := m.(FormPrompt).Values()["project_name"]; value != "çé!" {
+		t.Errorf("After Home, Delete and typing, value = %q, want %q", value, "çé!")
+	}
+}
+
+func TestFormPrompt_TypeOverDefault(t *testing.T) {
+	var m tea.Model = NewFormPrompt(testFormVariables())
+	m, _ = press(m, tea.KeyTab)
+	m = typeInto(m, "9")
+
+	if port := m.(FormPrompt).Values()["port"]; port != "9" {
+		t.Errorf("Values()[port] = %q, want typing to replace the default", port)
+	}
+}
+
+func TestFormPrompt_Submit(t *testing.T) {
+	var m tea.Model = NewFormPrompt(testFormVariables())
+	m = typeInto(m, "demo")
+
+	// Enter moves through the fields, then submits on the last
+	m, cmd := press(m, tea.KeyEnter)
+	if cmd != nil {
+		t.Fatal("Enter before the last field should not submit")
+	}
+	m, _ = press(m, tea.KeyEnter)
+	m, cmd = press(m, tea.KeyEnter)
+	if cmd == nil || !m.(FormPrompt).Done() {
+		t.Fatal("Enter on the last field should submit the form")
+	}
+
+	want := map[string]string{"project_name": "demo", "port": "8080", "regions": "us-east-1,us-west-2"}
+	values := m.(FormPrompt).Values()
+	for name, value := range want {
+		if values[name] != value {
+			t.Errorf("Values()[%s] = %q, want %q", name, values[name], value)
+		}
+	}
+	if m.View() != "" {
+		t.Error("View() should be empty once submitted")
+	}
+}
+
+func TestFormPrompt_SubmitInvalid(t *testing.T) {
+	var m tea.Model = NewFormPrompt(testFormVariables())
+
+	m, _ = press(m, tea.KeyTab)
+	m = typeInto(m, "x")
+	m, _ = press(m, tea.KeyTab)
+	m, cmd := press(m, tea.KeyEnter)
+
+	form := m.(FormPrompt)
+	if cmd != nil || form.Done() {
+		t.Fatal("Form with a malformed integer should not submit")
+	}
+	if form.focus != 1 {
+		t.Errorf("Focus = %d, want the invalid field 1", form.focus)
+	}
+	if !strings.Contains(form.View(), "not an integer") {
+		t.Errorf("View() should show the validation error, got:\n%s", form.View())
+	}
+}
+
+func TestFormPrompt_SubmitOutsideChoices(t *testing.T) {
+	var m tea.Model = NewFormPrompt([]template.Variable{
+		{Name: "env", Choices: []string{"dev", "prod"}},
+		{Name: "slug", Validation: "^[a-z-]+$"},
+	})
+
+	m = typeInto(m, "dev")
+	m, _ = press(m, tea.KeyTab)
+	m = typeInto(m, "My App")
+	m, cmd := press(m, tea.KeyEnter)
+
+	form := m.(FormPrompt)
+	if cmd != nil || form.Done() {
+		t.Fatal("Form with a value not matching the pattern should not submit")
+	}
+	if !strings.Contains(form.View(), `"My App" does not match`) {
+		t.Errorf("View() should show the pattern error, got:\n%s", form.View())
+	}
+}
+
+func TestFormPrompt_Cancel(t *testing.T) {
+	m, cmd := press(NewFormPrompt(testFormVariables()), tea.KeyEsc)
+	if cmd == nil {
+		t.Error("Esc should return tea.Quit command, got nil")
+	}
+	if m.(FormPrompt).Done() {
+		t.Error("Esc should not mark the form as done")
+	}
+}
+
+func TestFormPrompt_Help(t *testing.T) {
+	var m tea.Model = NewFormPrompt([]template.Variable{
+		{Name: "project_name", Description: "Name of the project", Example: "billing-api"},
+		{Name: "port", Type: "integer", Description: "Port the service listens on"},
+	})
+
+	view := m.View()
+	if !strings.Contains(view, "  Example: billing-api") {
+		t.Errorf("View() = %q, want the focused field's example", view)
+	}
+	if strings.Contains(view, "Port the service listens on\n") {
+		t.Errorf("View() = %q, want no help for unfocused fields", view)
+	}
+
+	m, _ = press(m, tea.KeyTab)
+	view = m.View()
+	if strings.Contains(view, "billing-api") {
+		t.Errorf("View() = %q, want the first field's help gone", view)
+	}
+}
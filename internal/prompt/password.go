@@ -0,0 +1,56 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+// mask replaces each character of a secret when it is displayed
+const mask = "•"
+
+// PasswordPrompt is a text prompt for secrets. Typed characters are shown
+// as • and a default is used, without being displayed, when Enter is pressed
+// on an empty value.
+type PasswordPrompt struct {
+	TextPrompt
+}
+
+func NewPasswordPrompt(prompt string, defaultValue interface{}) PasswordPrompt {
+	return PasswordPrompt{TextPrompt: NewTextPrompt(prompt, defaultValue)}
+}
+
+// NewSecretPrompt creates a password prompt for a template variable, with
+// its description and example as help
+func NewSecretPrompt(v template.Variable) PasswordPrompt {
+	p := NewPasswordPrompt(v.PromptText(), v.Default)
+	p.help = variableHelp(v)
+	return p
+}
+
+func (m PasswordPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.TextPrompt.Update(msg)
+	m.TextPrompt = model.(TextPrompt)
+	return m, cmd
+}
+
+func (m PasswordPrompt) View() string {
+	if m.done {
+		return ""
+	}
+
+	defaultHint := ""
+	if m.Default != nil && m.Default != "" {
+		defaultHint = " (leave empty for the default)"
+	}
+
+	return fmt.Sprintf("%s%s: %s%s%s", m.prompt, defaultHint, renderInput([]rune(maskValue(m.Value)), m.cursor()), renderError(m.Error), renderHelp(m.help))
+}
+
+// maskValue hides a secret, keeping only its length
+func maskValue(value string) string {
+	return strings.Repeat(mask, utf8.RuneCountInString(value))
+}
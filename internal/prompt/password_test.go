@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
+)
+
+func TestPasswordPrompt_MasksValue(t *testing.T) {
+	var m tea.Model = NewSecretPrompt(template.Variable{Name: "api_key", Prompt: "API key", Type: "password"})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s3cr3t")})
+	view := m.View()
+	if strings.Contains(view, "s3cr3t") {
+		t.Errorf("View() shows the secret: %q", view)
+	}
+	if want := "API key: ••••••\x1b[7m \x1b[0m"; view != want {
+		t.Errorf("View() = %q, want %q", view, want)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Error("Enter should return tea.Quit command, got nil")
+	}
+
+	p := m.(PasswordPrompt)
+	if !p.Done() || p.Value != "s3cr3t" {
+		t.Errorf("Value = %q, done = %v; want the typed secret, done", p.Value, p.Done())
+	}
+}
+
+func TestPasswordPrompt_Default(t *testing.T) {
+	var m tea.Model = NewPasswordPrompt("Password", "hunter2")
+
+	if view := m.View(); strings.Contains(view, "hunter2") || strings.Contains(view, "•") {
+		t.Errorf("View() should not reveal the default, got %q", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if p := m.(PasswordPrompt); p.Value != "hunter2" {
+		t.Errorf("Enter on an empty value should use the default, got %q", p.Value)
+	}
+}
+
+func TestFormPrompt_MasksSecrets(t *testing.T) {
+	var m tea.Model = NewFormPrompt([]template.Variable{
+		{Name: "db_password", Secret: true},
+		{Name: "db_user"},
+	})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("pa55")})
+
+	if view := m.View(); strings.Contains(view, "pa55") || !strings.Contains(view, "db_password: ••••") {
+		t.Errorf("View() should mask the secret field, got:\n%s", view)
+	}
+	if values := m.(FormPrompt).Values(); values["db_password"] != "pa55" {
+		t.Errorf("Values()[db_password] = %q, want %q", values["db_password"], "pa55")
+	}
+}
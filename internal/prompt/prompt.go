@@ -1,30 +1,104 @@
 package prompt
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
 )
 
-// TextPrompt is a simple text input prompt
+// ErrCancelled reports that the user abandoned a prompt with Ctrl-C or Esc
+// instead of answering it
+var ErrCancelled = errors.New("cancelled")
+
+// TextPrompt is a simple text input prompt. The default is shown as a hint
+// rather than filled in, so typing replaces it and Enter on an empty value
+// accepts it; Tab or → copies it into the input to edit. ←, →, Home and End
+// move the cursor, and typing and Backspace edit at the cursor.
 type TextPrompt struct {
 	prompt  string
 	Value   string
 	Default interface{}
 	done    bool
+
+	// Error explains why the previous answer was refused; it is shown
+	// beneath the input until the prompt is answered again
+	Error string
+
+	// help lines are shown beneath the input
+	help []string
+
+	// fromEnd is the cursor position, counted in runes back from the end of
+	// Value, so the cursor stays at the end when Value is set directly
+	fromEnd int
 }
 
 func NewTextPrompt(prompt string, defaultValue interface{}) TextPrompt {
-	defaultStr := ""
-	if defaultValue != nil {
-		defaultStr = fmt.Sprintf("%v", defaultValue)
-	}
 	return TextPrompt{
 		prompt:  prompt,
-		Value:   defaultStr,
 		Default: defaultValue,
 	}
 }
 
+// NewVariablePrompt creates a text prompt for a template variable, with
+// its description and example as help
+func NewVariablePrompt(v template.Variable) TextPrompt {
+	p := NewTextPrompt(v.PromptText(), v.Default)
+	p.help = variableHelp(v)
+	return p
+}
+
+// variableHelp returns the help lines for a variable: its description,
+// unless it is already the prompt text, and its example
+func variableHelp(v template.Variable) []string {
+	var help []string
+	if v.Description != "" && v.Description != v.PromptText() {
+		help = append(help, v.Description)
+	}
+	if v.Example != "" {
+		help = append(help, "Example: "+v.Example)
+	}
+	return help
+}
+
+// CheckAnswer checks a value typed for a variable against its type, choices
+// and validation pattern. Empty values pass: they stand for the default, and
+// required variables left without a value are reported after prompting.
+func CheckAnswer(v template.Variable, value string) error {
+	if value == "" {
+		return nil
+	}
+	if errs := template.CheckValues([]template.Variable{v}, map[string]interface{}{v.Name: value}); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// renderError shows why the previous answer was refused beneath an input
+func renderError(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return "\n  ⚠️  " + msg
+}
+
+// renderHelp indents help lines to show beneath an input
+func renderHelp(help []string) string {
+	var b strings.Builder
+	for _, line := range help {
+		b.WriteString("\n  " + line)
+	}
+	return b.String()
+}
+
+// Done reports whether the prompt was answered with Enter, rather than
+// abandoned with Ctrl-C or Esc
+func (m TextPrompt) Done() bool {
+	return m.done
+}
+
 func (m TextPrompt) Init() tea.Cmd {
 	return nil
 }
@@ -32,26 +106,61 @@ func (m TextPrompt) Init() tea.Cmd {
 func (m TextPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		value := []rune(m.Value)
+		pos := m.cursor()
+
 		switch msg.Type {
 		case tea.KeyEnter:
-			if m.Value == "" && m.Default != nil {
-				m.Value = fmt.Sprintf("%v", m.Default)
+			if m.Value == "" {
+				m.Value = formatDefault(m.Default)
 			}
 			m.done = true
 			return m, tea.Quit
 		case tea.KeyCtrlC, tea.KeyEsc:
 			return m, tea.Quit
+		case tea.KeyTab:
+			if m.Value == "" {
+				m.Value = formatDefault(m.Default)
+			}
+		case tea.KeyRight:
+			if m.Value == "" {
+				m.Value = formatDefault(m.Default)
+			} else if m.fromEnd > 0 {
+				m.fromEnd--
+			}
+		case tea.KeyLeft:
+			if pos > 0 {
+				m.fromEnd++
+			}
+		case tea.KeyHome, tea.KeyCtrlA:
+			m.fromEnd = len(value)
+		case tea.KeyEnd, tea.KeyCtrlE:
+			m.fromEnd = 0
 		case tea.KeyBackspace:
-			if len(m.Value) > 0 {
-				m.Value = m.Value[:len(m.Value)-1]
+			if pos > 0 {
+				m.Value = string(value[:pos-1]) + string(value[pos:])
+			}
+		case tea.KeyDelete:
+			if pos < len(value) {
+				m.Value = string(value[:pos]) + string(value[pos+1:])
+				m.fromEnd--
 			}
-		default:
-			m.Value += msg.String()
+		case tea.KeyRunes, tea.KeySpace:
+			m.Value = string(value[:pos]) + msg.String() + string(value[pos:])
 		}
 	}
 	return m, nil
 }
 
+// cursor returns the cursor's rune index in Value
+func (m TextPrompt) cursor() int {
+	n := len([]rune(m.Value))
+	if m.fromEnd > n {
+		return 0
+	}
+	return n - m.fromEnd
+}
+
 func (m TextPrompt) View() string {
 	if m.done {
 		return ""
@@ -62,5 +171,17 @@ func (m TextPrompt) View() string {
 		defaultHint = fmt.Sprintf(" (default: %v)", m.Default)
 	}
 
-	return fmt.Sprintf("%s%s: %s", m.prompt, defaultHint, m.Value)
+	return fmt.Sprintf("%s%s: %s%s%s", m.prompt, defaultHint, renderInput([]rune(m.Value), m.cursor()), renderError(m.Error), renderHelp(m.help))
+}
+
+// renderInput shows the cursor in an input value by reversing the character
+// under it, or a space past the end
+func renderInput(value []rune, cursor int) string {
+	under := " "
+	after := ""
+	if cursor < len(value) {
+		under = string(value[cursor])
+		after = string(value[cursor+1:])
+	}
+	return string(value[:cursor]) + "\x1b[7m" + under + "\x1b[0m" + after
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/madstone-tech/ason/internal/template"
 )
 
 func TestNewTextPrompt(t *testing.T) {
@@ -18,7 +19,7 @@ func TestNewTextPrompt(t *testing.T) {
 			name:         "with string default",
 			prompt:       "Enter name:",
 			defaultValue: "test",
-			wantValue:    "test",
+			wantValue:    "",
 		},
 		{
 			name:         "with nil default",
@@ -30,13 +31,13 @@ func TestNewTextPrompt(t *testing.T) {
 			name:         "with integer default",
 			prompt:       "Enter number:",
 			defaultValue: 42,
-			wantValue:    "42",
+			wantValue:    "",
 		},
 		{
 			name:         "with boolean default",
 			prompt:       "Enable feature:",
 			defaultValue: true,
-			wantValue:    "true",
+			wantValue:    "",
 		},
 	}
 
@@ -63,6 +64,190 @@ func TestNewTextPrompt(t *testing.T) {
 	}
 }
 
+func TestNewVariablePrompt(t *testing.T) {
+	v := template.Variable{
+		Name:        "license",
+		Description: "License to use",
+		Default:     "MIT",
+	}
+
+	prompt := NewVariablePrompt(v)
+	if prompt.prompt != "License to use" {
+		t.Errorf("TextPrompt.prompt = %v, want %v", prompt.prompt, "License to use")
+	}
+	if prompt.Value != "" || prompt.Default != "MIT" {
+		t.Errorf("TextPrompt.Value = %q, Default = %v; want an empty value with default MIT", prompt.Value, prompt.Default)
+	}
+
+	v.Prompt = "Which license?"
+	if prompt := NewVariablePrompt(v); prompt.prompt != "Which license?" {
+		t.Errorf("TextPrompt.prompt = %v, want %v", prompt.prompt, "Which license?")
+	}
+}
+
+func TestVariablePrompt_Help(t *testing.T) {
+	v := template.Variable{
+		Name:        "module",
+		Prompt:      "Go module path",
+		Description: "Import path of the generated module",
+		Example:     "github.com/acme/billing",
+	}
+
+	view := NewVariablePrompt(v).View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "Go module path: ") {
+		t.Fatalf("View() = %q, want the prompt followed by two help lines", view)
+	}
+	if lines[1] != "  Import path of the generated module" {
+		t.Errorf("description line = %q", lines[1])
+	}
+	if lines[2] != "  Example: github.com/acme/billing" {
+		t.Errorf("example line = %q", lines[2])
+	}
+
+	secret := v
+	secret.Secret = true
+	if view := NewSecretPrompt(secret).View(); !strings.Contains(view, "\n  Import path of the generated module\n  Example: github.com/acme/billing") {
+		t.Errorf("secret View() = %q, want the help lines", view)
+	}
+
+	// A description already used as the prompt text isn't repeated
+	v.Prompt = ""
+	if view := NewVariablePrompt(v).View(); strings.Count(view, "Import path of the generated module") != 1 {
+		t.Errorf("View() = %q, want the description once", view)
+	}
+
+	// Variables without help keep a single line
+	if view := NewVariablePrompt(template.Variable{Name: "name"}).View(); strings.Contains(view, "\n") {
+		t.Errorf("View() = %q, want a single line", view)
+	}
+}
+
+func TestCheckAnswer(t *testing.T) {
+	tests := []struct {
+		variable template.Variable
+		value    string
+		want     string
+	}{
+		{template.Variable{Name: "port", Type: "integer"}, "8080", ""},
+		{template.Variable{Name: "port", Type: "integer"}, "eighty", `variable port: "eighty" is not an integer`},
+		{template.Variable{Name: "env", Choices: []string{"dev", "prod"}}, "test", `variable env: "test" is not one of dev, prod`},
+		{template.Variable{Name: "slug", Validation: "^[a-z-]+$"}, "My App", `variable slug: "My App" does not match ^[a-z-]+$`},
+		{template.Variable{Name: "token", Secret: true, Validation: "^tok_"}, "s3cret", "variable token: value does not match ^tok_"},
+
+		// Empty answers stand for the default, even for required variables
+		{template.Variable{Name: "name", Required: true}, "", ""},
+	}
+
+	for _, tt := range tests {
+		err := CheckAnswer(tt.variable, tt.value)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("CheckAnswer(%s, %q) = %q, want %q", tt.variable.Name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTextPrompt_Error(t *testing.T) {
+	p := NewVariablePrompt(template.Variable{Name: "port", Example: "8080"})
+	p.Value, p.Error = "eighty", "not an integer"
+
+	view := p.View()
+	if !strings.Contains(view, "eighty") || !strings.Contains(view, "\n  ⚠️  not an integer\n  Example: 8080") {
+		t.Errorf("View() = %q, want the refused value, then the error above the help", view)
+	}
+
+	// Answering again hides the prompt, error included
+	model, _ := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); view != "" {
+		t.Errorf("View() after Enter = %q, want nothing", view)
+	}
+}
+
+func TestTextPrompt_DefaultHint(t *testing.T) {
+	t.Run("typing replaces the default", func(t *testing.T) {
+		var model tea.Model = NewTextPrompt("License", "MIT")
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Apache-2.0")})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		if got := model.(TextPrompt).Value; got != "Apache-2.0" {
+			t.Errorf("Value = %q, want %q", got, "Apache-2.0")
+		}
+	})
+
+	t.Run("enter keeps the default", func(t *testing.T) {
+		var model tea.Model = NewTextPrompt("License", "MIT")
+		if view := model.View(); view != "License (default: MIT): \x1b[7m \x1b[0m" {
+			t.Errorf("View() = %q, want the default as a hint only", view)
+		}
+
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if got := model.(TextPrompt).Value; got != "MIT" {
+			t.Errorf("Value = %q, want %q", got, "MIT")
+		}
+	})
+
+	t.Run("tab edits the default", func(t *testing.T) {
+		var model tea.Model = NewTextPrompt("Version", "1.0")
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".1")})
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		if got := model.(TextPrompt).Value; got != "1.0.1" {
+			t.Errorf("Value = %q, want %q", got, "1.0.1")
+		}
+	})
+}
+
+func TestTextPrompt_Cursor(t *testing.T) {
+	key := func(m tea.Model, k tea.KeyType) tea.Model {
+		m, _ = m.Update(tea.KeyMsg{Type: k})
+		return m
+	}
+
+	var model tea.Model = NewTextPrompt("Name", nil)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("helo")})
+
+	// Fix the typo in the middle: move left past "lo" and insert
+	model = key(model, tea.KeyLeft)
+	model = key(model, tea.KeyLeft)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if got := model.(TextPrompt).Value; got != "hello" {
+		t.Fatalf("Value = %q, want %q", got, "hello")
+	}
+	if view := model.View(); view != "Name: hel\x1b[7ml\x1b[0mo" {
+		t.Errorf("View() = %q, want the cursor on the second l", view)
+	}
+
+	// Backspace deletes before the cursor, Delete at it
+	model = key(model, tea.KeyBackspace)
+	model = key(model, tea.KeyDelete)
+	if got := model.(TextPrompt).Value; got != "heo" {
+		t.Errorf("After Backspace and Delete, Value = %q, want %q", got, "heo")
+	}
+
+	model = key(model, tea.KeyHome)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	model = key(model, tea.KeyEnd)
+	model = key(model, tea.KeyRight)
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	if got := model.(TextPrompt).Value; got != ">heo!" {
+		t.Errorf("After Home and End edits, Value = %q, want %q", got, ">heo!")
+	}
+
+	// The cursor can't move past either end
+	for i := 0; i < 10; i++ {
+		model = key(model, tea.KeyLeft)
+	}
+	model = key(model, tea.KeyBackspace)
+	if got := model.(TextPrompt).Value; got != ">heo!" {
+		t.Errorf("Backspace at the start should do nothing, Value = %q", got)
+	}
+}
+
 func TestTextPrompt_Init(t *testing.T) {
 	prompt := NewTextPrompt("Test:", "default")
 	cmd := prompt.Init()
@@ -144,6 +329,18 @@ func TestTextPrompt_Update_CtrlC(t *testing.T) {
 	}
 }
 
+func TestTextPrompt_Done(t *testing.T) {
+	prompt := NewTextPrompt("Test:", "default")
+	if prompt.Done() {
+		t.Error("Done() should be false before Enter")
+	}
+
+	model, _ := prompt.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !model.(TextPrompt).Done() {
+		t.Error("Done() should be true after Enter")
+	}
+}
+
 func TestTextPrompt_Update_Esc(t *testing.T) {
 	prompt := NewTextPrompt("Test:", "default")
 
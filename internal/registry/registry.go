@@ -1,22 +1,60 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/madstone-tech/ason/internal/fsutil"
+	"github.com/madstone-tech/ason/internal/template"
 	"github.com/madstone-tech/ason/internal/xdg"
 )
 
 // Registry manages local templates
 type Registry struct {
 	path string
+
+	// systemPaths are read-only registries shared by all users, most
+	// preferred first. Templates in path shadow them by name.
+	systemPaths []string
+
+	// readOnly is set when path is on a filesystem that can't be written,
+	// such as templates baked into a container image
+	readOnly bool
 }
 
+// ErrTemplateNotFound is returned when no registry holds a template by the
+// requested name
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ErrAmbiguousTemplate is returned when a name matches no template exactly
+// but several ignoring case
+var ErrAmbiguousTemplate = errors.New("ambiguous template name")
+
+// ErrReadOnly is returned by operations that would change a registry on a
+// read-only filesystem
+var ErrReadOnly = errors.New("registry is read-only")
+
+// Template origins reported in TemplateEntry.Origin
+const (
+	OriginUser   = "user"
+	OriginSystem = "system"
+)
+
+// now returns the time recorded for registry changes; tests pin it
+var now = time.Now
+
+// mkdirAll creates registry directories; tests replace it to simulate
+// read-only filesystems, which file permissions can't do for root
+var mkdirAll = os.MkdirAll
+
 // TemplateEntry represents a template in the registry
 type TemplateEntry struct {
 	Name        string    `json:"name" toml:"name"`
@@ -24,73 +62,184 @@ type TemplateEntry struct {
 	Description string    `json:"description" toml:"description"`
 	Source      string    `json:"source" toml:"source"`
 	Type        string    `json:"type" toml:"type"`
+	Engine      string    `json:"engine,omitempty" toml:"engine,omitempty"`
+	Version     string    `json:"version,omitempty" toml:"version,omitempty"`
+	Tags        []string  `json:"tags,omitempty" toml:"tags,omitempty"`
 	Size        int64     `json:"size" toml:"size"`
 	Files       int       `json:"files" toml:"files"`
 	Added       time.Time `json:"added" toml:"added"`
-	Variables   []string  `json:"variables,omitempty" toml:"variables,omitempty"`
-}
 
-// TemplateConfig represents the ason.toml configuration
-type TemplateConfig struct {
-	Name        string             `toml:"name,omitempty"`
-	Description string             `toml:"description,omitempty"`
-	Version     string             `toml:"version,omitempty"`
-	Author      string             `toml:"author,omitempty"`
-	Type        string             `toml:"type,omitempty"`
-	Variables   []TemplateVariable `toml:"variables,omitempty"`
-	Ignore      []string           `toml:"ignore,omitempty"`
-	Tags        []string           `toml:"tags,omitempty"`
+	// Variables are the variable names in declaration order, which is
+	// the order they are prompted for
+	Variables []string `json:"variables,omitempty" toml:"variables,omitempty"`
+
+	// SkippedHidden lists hidden paths left out when the template was copied
+	SkippedHidden []string `json:"skipped_hidden,omitempty" toml:"skipped_hidden,omitempty"`
+
+	// IncludeHidden records that the template was registered with all its
+	// hidden files, which are then generated too
+	IncludeHidden bool `json:"include_hidden,omitempty" toml:"include_hidden,omitempty"`
+
+	// Origin tells whether the template comes from the user's registry or a
+	// read-only system registry. It is set on load and never stored.
+	Origin string `json:"origin,omitempty" toml:"-"`
+
+	// Outdated is set by `ason list --outdated` when the source has changed
+	// since registration (see IsOutdated). It is never stored.
+	Outdated bool `json:"outdated,omitempty" toml:"-"`
 }
 
-// TemplateVariable represents a template variable definition
-type TemplateVariable struct {
-	Name        string      `toml:"name"`
-	Description string      `toml:"description,omitempty"`
-	Required    bool        `toml:"required,omitempty"`
-	Default     interface{} `toml:"default,omitempty"`
-	Type        string      `toml:"type,omitempty"`
-	Options     []string    `toml:"options,omitempty"`
-	Example     string      `toml:"example,omitempty"`
+// TemplateConfig is the template configuration as seen by the registry
+type TemplateConfig = template.Config
+
+// TemplateVariable is a template variable definition as seen by the registry
+type TemplateVariable = template.Variable
+
+// MetadataVersion is the current registry metadata format version
+const MetadataVersion = 1
+
+// RegistryStats summarises a registry
+type RegistryStats struct {
+	Path            string    `json:"path"`
+	Templates       int       `json:"templates"`
+	SystemTemplates int       `json:"system_templates"`
+	TotalSize       int64     `json:"total_size"`
+	Updated         time.Time `json:"updated"`
+	Version         int       `json:"version"`
 }
 
 // RegistryMetadata stores registry information
 type RegistryMetadata struct {
+	// Version is the metadata format version; files written before
+	// versioning was introduced load as version 0
+	Version   int                      `json:"version" toml:"version"`
 	Templates map[string]TemplateEntry `json:"templates" toml:"templates"`
 	Updated   time.Time                `json:"updated" toml:"updated"`
 }
 
-// NewRegistry creates a new template registry
+// migrations upgrade metadata by one format version, keyed by the version
+// they upgrade from
+var migrations = map[int]func(meta *RegistryMetadata) error{
+	// Version 1 only adds the version field itself
+	0: func(meta *RegistryMetadata) error { return nil },
+}
+
+// HomeEnv is the environment variable that overrides the registry directory
+const HomeEnv = "ASON_HOME"
+
+// NewRegistry creates a new template registry in $ASON_HOME, or in the XDG
+// data directory when it is unset. System registries under XDG_DATA_DIRS are
+// included read-only.
 func NewRegistry() (*Registry, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		reg, err := NewRegistryAt(home)
+		if err != nil {
+			return nil, err
+		}
+		return reg.WithSystemDirs(xdg.DataDirs()), nil
+	}
+
 	registryPath, err := xdg.DataHome()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data directory: %w", err)
 	}
 
+	reg, err := NewRegistryAt(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.WithSystemDirs(xdg.DataDirs()), nil
+}
+
+// NewRegistryAt creates a template registry rooted at the given directory,
+// creating it and its templates subdirectory if needed. On a read-only
+// filesystem the registry is opened read-only instead: templates can be
+// listed and used, but adding or removing them fails with ErrReadOnly.
+func NewRegistryAt(registryPath string) (*Registry, error) {
+	registryPath, err := filepath.Abs(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registry directory: %w", err)
+	}
+	reg := &Registry{path: registryPath}
+
 	// Create registry directory if it doesn't exist
-	if err := os.MkdirAll(registryPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	if err := mkdirAll(registryPath, 0755); err != nil {
+		if !isReadOnlyErr(err) {
+			return nil, fmt.Errorf("failed to create registry directory: %w", err)
+		}
+		reg.readOnly = true
+		return reg, nil
 	}
 
 	// Create templates subdirectory
 	templatesPath := filepath.Join(registryPath, "templates")
-	if err := os.MkdirAll(templatesPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create templates directory: %w", err)
+	if err := mkdirAll(templatesPath, 0755); err != nil {
+		if !isReadOnlyErr(err) {
+			return nil, fmt.Errorf("failed to create templates directory: %w", err)
+		}
+		reg.readOnly = true
 	}
 
-	return &Registry{
-		path: registryPath,
-	}, nil
+	return reg, nil
+}
+
+// isReadOnlyErr reports whether err comes from writing where the
+// filesystem or permissions don't allow it
+func isReadOnlyErr(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// ReadOnly reports whether the registry was opened read-only, or has been
+// found to be by a change that failed with ErrReadOnly
+func (r *Registry) ReadOnly() bool {
+	return r.readOnly
+}
+
+// checkWritable returns ErrReadOnly unless files can be created in the
+// registry. Registries whose directories already exist open without
+// writing anything, so this is where a read-only mount shows up.
+func (r *Registry) checkWritable() error {
+	if !r.readOnly {
+		probe, err := os.CreateTemp(r.path, ".write-check-*")
+		if err == nil {
+			probe.Close()
+			return os.Remove(probe.Name())
+		}
+		if !isReadOnlyErr(err) {
+			return fmt.Errorf("failed to write to registry: %w", err)
+		}
+		r.readOnly = true
+	}
+	return fmt.Errorf("%w: %s; point --registry-dir or %s at a writable directory", ErrReadOnly, r.path, HomeEnv)
+}
+
+// WithSystemDirs adds read-only system registries, most preferred first.
+// Missing directories are ignored.
+func (r *Registry) WithSystemDirs(dirs []string) *Registry {
+	for _, dir := range dirs {
+		if dir != r.path {
+			r.systemPaths = append(r.systemPaths, dir)
+		}
+	}
+	return r
+}
+
+// Path returns the registry's root directory
+func (r *Registry) Path() string {
+	return r.path
 }
 
-// List returns all templates in the registry
+// List returns all templates in the registry, including system templates not
+// shadowed by a user template of the same name
 func (r *Registry) List() ([]TemplateEntry, error) {
-	meta, err := r.loadMetadata()
+	entries, err := r.entries()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load registry metadata: %w", err)
+		return nil, err
 	}
 
 	var templates []TemplateEntry
-	for _, tmpl := range meta.Templates {
+	for _, tmpl := range entries {
 		templates = append(templates, tmpl)
 	}
 
@@ -99,20 +248,202 @@ func (r *Registry) List() ([]TemplateEntry, error) {
 
 // Get returns the path to a template
 func (r *Registry) Get(name string) (string, error) {
+	entry, err := r.Entry(name)
+	if err != nil {
+		return "", err
+	}
+
+	return entry.Path, nil
+}
+
+// Entry returns the registry entry for a template, looking in the user
+// registry before the system registries. A name with no exact match falls
+// back to the template whose name matches ignoring case; callers can tell
+// from the entry's Name. It returns ErrAmbiguousTemplate if several do.
+func (r *Registry) Entry(name string) (*TemplateEntry, error) {
+	entries, err := r.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	if tmpl, exists := entries[name]; exists {
+		return &tmpl, nil
+	}
+
+	var matches []string
+	for entryName := range entries {
+		if strings.EqualFold(entryName, name) {
+			matches = append(matches, entryName)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	case 1:
+		tmpl := entries[matches[0]]
+		tmpl.Name = matches[0]
+		return &tmpl, nil
+	default:
+		sort.Strings(matches)
+		return nil, fmt.Errorf("%w: %s matches %s; use the exact name", ErrAmbiguousTemplate, name, strings.Join(matches, ", "))
+	}
+}
+
+// entries merges the user and system registries by template name
+func (r *Registry) entries() (map[string]TemplateEntry, error) {
 	meta, err := r.loadMetadata()
 	if err != nil {
-		return "", fmt.Errorf("failed to load registry metadata: %w", err)
+		return nil, fmt.Errorf("failed to load registry metadata: %w", err)
+	}
+
+	entries := make(map[string]TemplateEntry, len(meta.Templates))
+	for name, tmpl := range meta.Templates {
+		tmpl.Origin = OriginUser
+		entries[name] = tmpl
 	}
 
-	if tmpl, exists := meta.Templates[name]; exists {
-		return tmpl.Path, nil
+	for _, systemPath := range r.systemPaths {
+		// System registries are read-only, so they are only migrated in memory
+		systemMeta, err := loadMetadataFrom(systemPath)
+		if err == nil {
+			_, err = migrateMetadata(systemMeta)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system registry %s: %w", systemPath, err)
+		}
+
+		for name, tmpl := range systemMeta.Templates {
+			if _, shadowed := entries[name]; shadowed {
+				continue
+			}
+			tmpl.Origin = OriginSystem
+			if tmpl.Path == "" {
+				tmpl.Path = filepath.Join(systemPath, "templates", name)
+			} else if !filepath.IsAbs(tmpl.Path) {
+				tmpl.Path = filepath.Join(systemPath, tmpl.Path)
+			}
+			entries[name] = tmpl
+		}
 	}
 
-	return "", fmt.Errorf("template %s not found", name)
+	return entries, nil
 }
 
-// Add adds a template to the registry
+// Stats aggregates the registry metadata. Counts and sizes cover the user
+// registry; system templates are counted separately.
+func (r *Registry) Stats() (RegistryStats, error) {
+	meta, err := r.loadMetadata()
+	if err != nil {
+		return RegistryStats{}, fmt.Errorf("failed to load registry metadata: %w", err)
+	}
+
+	stats := RegistryStats{
+		Path:      r.path,
+		Templates: len(meta.Templates),
+		Updated:   meta.Updated,
+		Version:   meta.Version,
+	}
+	for _, tmpl := range meta.Templates {
+		stats.TotalSize += tmpl.Size
+	}
+
+	// A registry that has never been written has no update time
+	if _, err := os.Stat(filepath.Join(r.path, "registry.toml")); os.IsNotExist(err) {
+		stats.Updated = time.Time{}
+	}
+
+	entries, err := r.entries()
+	if err != nil {
+		return RegistryStats{}, err
+	}
+	for _, tmpl := range entries {
+		if tmpl.Origin == OriginSystem {
+			stats.SystemTemplates++
+		}
+	}
+
+	return stats, nil
+}
+
+// AllTags counts how many registered templates, user and system, carry each
+// tag. A tag repeated within one template counts once.
+func (r *Registry) AllTags() (map[string]int, error) {
+	entries, err := r.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, tmpl := range entries {
+		seen := make(map[string]bool)
+		for _, tag := range tmpl.Tags {
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			counts[tag]++
+		}
+	}
+	return counts, nil
+}
+
+// Types counts how many registered templates, user and system, have each
+// type. Templates without a type aren't counted.
+func (r *Registry) Types() (map[string]int, error) {
+	entries, err := r.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, tmpl := range entries {
+		if tmpl.Type != "" {
+			counts[tmpl.Type]++
+		}
+	}
+	return counts, nil
+}
+
+// Config returns the configuration of a registered template, including the
+// full variable definitions (prompts, choices, defaults) needed to prompt for
+// values. Templates without a config yield an empty one.
+func (r *Registry) Config(name string) (*TemplateConfig, error) {
+	path, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := r.loadTemplateConfig(path)
+	if errors.Is(err, template.ErrNoConfig) {
+		return &TemplateConfig{}, nil
+	}
+	return config, err
+}
+
+// AddOptions are the optional settings of AddWithOptions and Preview
+type AddOptions struct {
+	// Source is recorded as where the template came from instead of the
+	// directory it is copied from, e.g. the URL of the repository a
+	// temporary clone was made from
+	Source string
+
+	// IncludeHidden copies every hidden file and directory, whatever the
+	// template config keeps, apart from ones such as .git
+	IncludeHidden bool
+}
+
+// Add adds a template to the registry, recording sourcePath as its source
 func (r *Registry) Add(name, sourcePath, description, templateType string) error {
+	return r.AddWithOptions(name, sourcePath, description, templateType, AddOptions{})
+}
+
+// AddWithOptions adds a template like Add, with the given options
+func (r *Registry) AddWithOptions(name, sourcePath, description, templateType string, opts AddOptions) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+
 	// Validate source path exists
 	info, err := os.Stat(sourcePath)
 	if err != nil {
@@ -134,28 +465,108 @@ func (r *Registry) Add(name, sourcePath, description, templateType string) error
 		return fmt.Errorf("template %s already exists", name)
 	}
 
+	// Load template config if exists, before anything is copied so that an
+	// invalid or ambiguous config leaves the registry untouched
+	config, err := r.loadTemplateConfig(sourcePath)
+	if errors.Is(err, template.ErrNoConfig) {
+		config = &TemplateConfig{}
+	} else if err != nil {
+		return fmt.Errorf("invalid template config: %w", err)
+	}
+
+	// Record an absolute source so it can be checked later from any directory
+	if abs, err := filepath.Abs(sourcePath); err == nil {
+		sourcePath = abs
+	}
+
 	// Calculate destination path
 	destPath := filepath.Join(r.path, "templates", name)
 
+	policy, err := templatePolicy(config, sourcePath, opts.IncludeHidden)
+	if err != nil {
+		return err
+	}
+
 	// Copy template to registry
-	if err := r.copyTemplate(sourcePath, destPath); err != nil {
+	skippedHidden, err := fsutil.CopyTree(sourcePath, destPath, policy)
+	if err != nil {
 		return fmt.Errorf("failed to copy template: %w", err)
 	}
 
 	// Analyze template
-	size, files, err := r.analyzeTemplate(destPath)
+	stats, err := fsutil.Analyze(destPath, fsutil.Policy{})
 	if err != nil {
 		return fmt.Errorf("failed to analyze template: %w", err)
 	}
 
-	// Load template config if exists
-	config, err := r.loadTemplateConfig(destPath)
+	tmpl := newEntry(name, sourcePath, destPath, description, templateType, config)
+	if opts.Source != "" {
+		tmpl.Source = opts.Source
+	}
+	tmpl.IncludeHidden = opts.IncludeHidden
+	tmpl.Size = stats.Size
+	tmpl.Files = stats.Files
+	tmpl.Added = now()
+	tmpl.SkippedHidden = skippedHidden
+
+	// Add to metadata
+	meta.Templates[name] = tmpl
+	meta.Updated = now()
+
+	// Save metadata
+	if err := r.saveMetadata(meta); err != nil {
+		return fmt.Errorf("failed to save registry metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Preview describes the entry Add would create for a template source without
+// copying anything or touching the registry metadata. Size and file count
+// cover the files Add would copy.
+func (r *Registry) Preview(name, sourcePath, description, templateType string, opts AddOptions) (TemplateEntry, error) {
+	info, err := os.Stat(sourcePath)
 	if err != nil {
-		// Not an error if config doesn't exist
+		return TemplateEntry{}, fmt.Errorf("source path does not exist: %s", sourcePath)
+	}
+
+	if !info.IsDir() {
+		return TemplateEntry{}, fmt.Errorf("source path must be a directory: %s", sourcePath)
+	}
+
+	config, err := r.loadTemplateConfig(sourcePath)
+	if errors.Is(err, template.ErrNoConfig) {
 		config = &TemplateConfig{}
+	} else if err != nil {
+		return TemplateEntry{}, fmt.Errorf("invalid template config: %w", err)
 	}
 
-	// Use config values if not provided
+	if abs, err := filepath.Abs(sourcePath); err == nil {
+		sourcePath = abs
+	}
+
+	policy, err := templatePolicy(config, sourcePath, opts.IncludeHidden)
+	if err != nil {
+		return TemplateEntry{}, err
+	}
+
+	stats, err := fsutil.Analyze(sourcePath, policy)
+	if err != nil {
+		return TemplateEntry{}, fmt.Errorf("failed to analyze template: %w", err)
+	}
+
+	tmpl := newEntry(name, sourcePath, filepath.Join(r.path, "templates", name), description, templateType, config)
+	tmpl.Size = stats.Size
+	tmpl.Files = stats.Files
+	tmpl.IncludeHidden = opts.IncludeHidden
+
+	return tmpl, nil
+}
+
+// newEntry builds a template entry, taking the description and type from the
+// config where they weren't given, along with its engine, version, tags and
+// variable names
+func newEntry(name, sourcePath, destPath, description, templateType string, config *TemplateConfig) TemplateEntry {
 	if description == "" && config.Description != "" {
 		description = config.Description
 	}
@@ -163,39 +574,69 @@ func (r *Registry) Add(name, sourcePath, description, templateType string) error
 		templateType = config.Type
 	}
 
-	// Extract variable names from config
 	var variables []string
 	for _, v := range config.Variables {
 		variables = append(variables, v.Name)
 	}
 
-	// Create template entry
-	tmpl := TemplateEntry{
+	return TemplateEntry{
 		Name:        name,
 		Path:        destPath,
 		Description: description,
 		Source:      sourcePath,
 		Type:        templateType,
-		Size:        size,
-		Files:       files,
-		Added:       time.Now(),
+		Engine:      config.Engine,
+		Version:     config.Version,
+		Tags:        config.Tags,
 		Variables:   variables,
 	}
+}
 
-	// Add to metadata
-	meta.Templates[name] = tmpl
-	meta.Updated = time.Now()
+// IsOutdated reports whether a template's source directory has changed since
+// it was registered: its size or file count differ, or a file in it was
+// modified afterwards. Templates without a source, or whose source no longer
+// exists, are never outdated.
+func (r *Registry) IsOutdated(entry TemplateEntry) (bool, error) {
+	if entry.Source == "" {
+		return false, nil
+	}
 
-	// Save metadata
-	if err := r.saveMetadata(meta); err != nil {
-		return fmt.Errorf("failed to save registry metadata: %w", err)
+	info, err := os.Stat(entry.Source)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
 	}
 
-	return nil
+	// Count the source the way Add copied it
+	config, err := r.loadTemplateConfig(entry.Source)
+	if err != nil && !errors.Is(err, template.ErrNoConfig) {
+		return false, fmt.Errorf("invalid template config in %s: %w", entry.Source, err)
+	}
+
+	policy, err := templatePolicy(config, entry.Source, entry.IncludeHidden)
+	if err != nil {
+		return false, err
+	}
+
+	stats, err := fsutil.Analyze(entry.Source, policy)
+	if err != nil {
+		return false, fmt.Errorf("failed to analyze template source: %w", err)
+	}
+
+	return stats.Size != entry.Size || stats.Files != entry.Files || stats.Modified.After(entry.Added), nil
 }
 
 // Remove removes a template from the registry
 func (r *Registry) Remove(name string, backup bool, backupDir string) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+
 	// Load existing metadata
 	meta, err := r.loadMetadata()
 	if err != nil {
@@ -205,7 +646,10 @@ func (r *Registry) Remove(name string, backup bool, backupDir string) error {
 	// Check if template exists
 	tmpl, exists := meta.Templates[name]
 	if !exists {
-		return fmt.Errorf("template %s not found", name)
+		if entry, err := r.Entry(name); err == nil && entry.Name == name && entry.Origin == OriginSystem {
+			return fmt.Errorf("template %s is provided by a read-only system registry and cannot be removed", name)
+		}
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
 	}
 
 	// Create backup if requested
@@ -222,7 +666,7 @@ func (r *Registry) Remove(name string, backup bool, backupDir string) error {
 
 	// Remove from metadata
 	delete(meta.Templates, name)
-	meta.Updated = time.Now()
+	meta.Updated = now()
 
 	// Save metadata
 	if err := r.saveMetadata(meta); err != nil {
@@ -232,15 +676,63 @@ func (r *Registry) Remove(name string, backup bool, backupDir string) error {
 	return nil
 }
 
-// loadMetadata loads the registry metadata
+// loadMetadata loads the registry metadata, saving it back if it had to be
+// migrated to the current format version
 func (r *Registry) loadMetadata() (*RegistryMetadata, error) {
-	metaPath := filepath.Join(r.path, "registry.toml")
+	meta, err := loadMetadataFrom(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, err := migrateMetadata(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read-only registries are only migrated in memory, like system ones
+	if migrated && !r.readOnly {
+		if err := r.saveMetadata(meta); err != nil {
+			return nil, fmt.Errorf("failed to save migrated metadata: %w", err)
+		}
+	}
+
+	return meta, nil
+}
+
+// migrateMetadata upgrades metadata to MetadataVersion in place, reporting
+// whether anything changed
+func migrateMetadata(meta *RegistryMetadata) (bool, error) {
+	if meta.Version > MetadataVersion {
+		return false, fmt.Errorf("registry format version %d is newer than supported version %d; please upgrade ason",
+			meta.Version, MetadataVersion)
+	}
+
+	migrated := false
+	for meta.Version < MetadataVersion {
+		migrate, ok := migrations[meta.Version]
+		if !ok {
+			return false, fmt.Errorf("no migration from registry format version %d", meta.Version)
+		}
+		if err := migrate(meta); err != nil {
+			return false, fmt.Errorf("failed to migrate registry from version %d: %w", meta.Version, err)
+		}
+		meta.Version++
+		migrated = true
+	}
+
+	return migrated, nil
+}
+
+// loadMetadataFrom loads the metadata of the registry rooted at dir
+func loadMetadataFrom(dir string) (*RegistryMetadata, error) {
+	metaPath := filepath.Join(dir, "registry.toml")
 
 	// If metadata doesn't exist, return empty metadata
 	if _, err := os.Stat(metaPath); os.IsNotExist(err) {
 		return &RegistryMetadata{
+			Version:   MetadataVersion,
 			Templates: make(map[string]TemplateEntry),
-			Updated:   time.Now(),
+			Updated:   now(),
 		}, nil
 	}
 
@@ -261,9 +753,12 @@ func (r *Registry) loadMetadata() (*RegistryMetadata, error) {
 	return &meta, nil
 }
 
-// saveMetadata saves the registry metadata
+// saveMetadata saves the registry metadata. The TOML encoder writes map
+// keys sorted, so templates are stored by name and the file only changes
+// where the registry did, keeping diffs of a versioned registry small.
 func (r *Registry) saveMetadata(meta *RegistryMetadata) error {
 	metaPath := filepath.Join(r.path, "registry.toml")
+	meta.Version = MetadataVersion
 
 	data, err := toml.Marshal(meta)
 	if err != nil {
@@ -277,90 +772,31 @@ func (r *Registry) saveMetadata(meta *RegistryMetadata) error {
 	return nil
 }
 
-// loadTemplateConfig loads the ason.toml config from a template
+// loadTemplateConfig loads the template config (ason.toml, ason.yaml, ason.yml
+// or ason.json) from a template
 func (r *Registry) loadTemplateConfig(templatePath string) (*TemplateConfig, error) {
-	tomlPath := filepath.Join(templatePath, "ason.toml")
-	if _, err := os.Stat(tomlPath); err != nil {
-		return nil, fmt.Errorf("no ason.toml found in template")
-	}
-
-	data, err := os.ReadFile(tomlPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read ason.toml: %w", err)
-	}
-
-	var config TemplateConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse ason.toml: %w", err)
-	}
-	return &config, nil
+	return template.Load(templatePath)
 }
 
-// copyTemplate recursively copies a template directory
-func (r *Registry) copyTemplate(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Calculate relative path
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip hidden files and directories (except .gitignore, .env.example)
-		if strings.HasPrefix(info.Name(), ".") && info.Name() != ".gitignore" && info.Name() != ".env.example" {
-			return nil
-		}
-
-		dstPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(dstPath, info.Mode())
-		}
-
-		return r.copyFile(path, dstPath)
-	})
-}
-
-// copyFile copies a single file
-func (r *Registry) copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
+// templatePolicy returns what registering a template leaves out: hidden
+// paths its config doesn't keep, unless includeHidden is set, and paths
+// matching its ignore patterns. The config file is always kept, since the
+// registry reads it.
+func templatePolicy(config *TemplateConfig, dir string, includeHidden bool) (fsutil.Policy, error) {
+	ignore, err := config.IgnorePatterns(dir)
 	if err != nil {
-		return err
+		return fsutil.Policy{}, err
 	}
-	defer srcFile.Close()
 
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return err
+	if includeHidden {
+		config = config.WithIncludeHidden()
 	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	return err
-}
-
-// analyzeTemplate analyzes a template directory
-func (r *Registry) analyzeTemplate(templatePath string) (int64, int, error) {
-	var totalSize int64
-	var fileCount int
-
-	err := filepath.Walk(templatePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() {
-			totalSize += info.Size()
-			fileCount++
-		}
 
-		return nil
-	})
-
-	return totalSize, fileCount, err
+	return fsutil.Policy{
+		KeepHidden: config.KeepHidden,
+		Ignore:     ignore,
+		Keep:       template.ConfigFileNames,
+	}, nil
 }
 
 // createBackup creates a backup of a template
@@ -375,8 +811,9 @@ func (r *Registry) createBackup(tmpl TemplateEntry, backupDir string) error {
 	}
 
 	// Create backup filename with timestamp
-	timestamp := time.Now().Format("2006-01-02-150405")
+	timestamp := now().Format("2006-01-02-150405")
 	// For now, just copy the directory (TODO: implement tar.gz compression)
 	backupDirPath := filepath.Join(backupDir, fmt.Sprintf("%s-%s", tmpl.Name, timestamp))
-	return r.copyTemplate(tmpl.Path, backupDirPath)
+	_, err := fsutil.CopyTree(tmpl.Path, backupDirPath, fsutil.Policy{})
+	return err
 }
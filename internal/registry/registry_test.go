@@ -1,10 +1,19 @@
 package registry
 
 import (
+	"bytes"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/madstone-tech/ason/internal/template"
 )
 
 func TestNewRegistry(t *testing.T) {
@@ -20,6 +29,7 @@ func TestNewRegistry(t *testing.T) {
 	defer os.RemoveAll(tmpHome)
 
 	os.Setenv("HOME", tmpHome)
+	t.Setenv(HomeEnv, "")
 
 	registry, err := NewRegistry()
 	if err != nil {
@@ -47,6 +57,71 @@ func TestNewRegistry(t *testing.T) {
 	}
 }
 
+// newTestRegistry creates a registry rooted at dir
+func newTestRegistry(t *testing.T, dir string) *Registry {
+	t.Helper()
+
+	registry, err := NewRegistryAt(dir)
+	if err != nil {
+		t.Fatalf("NewRegistryAt() failed: %v", err)
+	}
+	return registry
+}
+
+func TestNewRegistryAt(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "nested", "registry")
+
+	registry, err := NewRegistryAt(registryPath)
+	if err != nil {
+		t.Fatalf("NewRegistryAt() failed: %v", err)
+	}
+
+	if registry.Path() != registryPath {
+		t.Errorf("Registry path = %v, want %v", registry.Path(), registryPath)
+	}
+
+	if _, err := os.Stat(filepath.Join(registryPath, "templates")); err != nil {
+		t.Errorf("Templates directory was not created: %v", err)
+	}
+
+	// Relative paths are resolved to absolute ones
+	t.Chdir(t.TempDir())
+	registry, err = NewRegistryAt("relative")
+	if err != nil {
+		t.Fatalf("NewRegistryAt() failed: %v", err)
+	}
+	if !filepath.IsAbs(registry.Path()) {
+		t.Errorf("Registry path = %v, want an absolute path", registry.Path())
+	}
+
+	// A file in the way is an error
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := NewRegistryAt(blocker); err == nil {
+		t.Error("Expected error when registry path is a file, got nil")
+	}
+}
+
+func TestNewRegistry_AsonHome(t *testing.T) {
+	asonHome := filepath.Join(t.TempDir(), "team-registry")
+	t.Setenv(HomeEnv, asonHome)
+
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry() failed: %v", err)
+	}
+
+	if registry.Path() != asonHome {
+		t.Errorf("Registry path = %v, want %v", registry.Path(), asonHome)
+	}
+
+	if _, err := os.Stat(filepath.Join(asonHome, "templates")); err != nil {
+		t.Errorf("Templates directory was not created: %v", err)
+	}
+}
+
 func TestRegistry_List_Empty(t *testing.T) {
 	// Create temporary registry
 	tmpDir, err := os.MkdirTemp("", "ason_registry_test")
@@ -55,7 +130,7 @@ func TestRegistry_List_Empty(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	templates, err := registry.List()
 	if err != nil {
@@ -76,7 +151,7 @@ func TestRegistry_AddAndList(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	// Create a test template directory
 	testTemplateDir, err := os.MkdirTemp("", "test_template")
@@ -140,6 +215,890 @@ required = true
 	}
 }
 
+func TestRegistry_Preview(t *testing.T) {
+	reg := newTestRegistry(t, t.TempDir())
+
+	src := t.TempDir()
+	files := map[string]string{
+		"README.md":  "# {{ project_name }}",
+		"main.go":    "package main",
+		".env":       "SECRET=1",
+		".gitignore": "bin/",
+		"ason.toml": `description = "Service template"
+type = "backend"
+
+[[variables]]
+name = "project_name"
+
+[[variables]]
+name = "port"
+`,
+	}
+	var size int64
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if name != ".env" {
+			size += int64(len(content))
+		}
+	}
+
+	tmpl, err := reg.Preview("svc", src, "", "", AddOptions{})
+	if err != nil {
+		t.Fatalf("Preview() failed: %v", err)
+	}
+
+	// .env is skipped as a hidden file, as Add would skip it
+	if tmpl.Files != 4 {
+		t.Errorf("Files = %d, want 4", tmpl.Files)
+	}
+	if tmpl.Size != size {
+		t.Errorf("Size = %d, want %d", tmpl.Size, size)
+	}
+	if tmpl.Description != "Service template" || tmpl.Type != "backend" {
+		t.Errorf("Description, Type = %q, %q; want config values", tmpl.Description, tmpl.Type)
+	}
+	if strings.Join(tmpl.Variables, ",") != "project_name,port" {
+		t.Errorf("Variables = %v, want [project_name port]", tmpl.Variables)
+	}
+
+	if _, err := reg.Entry("svc"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Entry() after Preview() error = %v, want ErrTemplateNotFound", err)
+	}
+	if _, err := os.Stat(tmpl.Path); !os.IsNotExist(err) {
+		t.Errorf("Preview() created %s", tmpl.Path)
+	}
+}
+
+func TestRegistry_Add_YAMLConfig(t *testing.T) {
+	// Create temporary registry
+	tmpDir, err := os.MkdirTemp("", "ason_registry_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := newTestRegistry(t, tmpDir)
+
+	// Create a test template directory with only an ason.yaml config
+	testTemplateDir, err := os.MkdirTemp("", "test_template")
+	if err != nil {
+		t.Fatalf("Failed to create test template dir: %v", err)
+	}
+	defer os.RemoveAll(testTemplateDir)
+
+	err = os.WriteFile(filepath.Join(testTemplateDir, "ason.yaml"), []byte(`name: "YAML Template"
+description: "A YAML configured template"
+type: service
+variables:
+  - name: project_name
+    required: true
+  - name: author
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create ason.yaml: %v", err)
+	}
+
+	// Register without description or type so they come from the config
+	err = registry.Add("yaml-template", testTemplateDir, "", "")
+	if err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	templates, err := registry.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(templates) != 1 {
+		t.Fatalf("Expected 1 template, got %d", len(templates))
+	}
+
+	tmpl := templates[0]
+	if tmpl.Description != "A YAML configured template" {
+		t.Errorf("Template description = %v, want %v", tmpl.Description, "A YAML configured template")
+	}
+	if tmpl.Type != "service" {
+		t.Errorf("Template type = %v, want %v", tmpl.Type, "service")
+	}
+	if len(tmpl.Variables) != 2 || tmpl.Variables[0] != "project_name" || tmpl.Variables[1] != "author" {
+		t.Errorf("Template variables = %v, want [project_name author]", tmpl.Variables)
+	}
+}
+
+func TestRegistry_Add_ConflictingConfigs(t *testing.T) {
+	// Create temporary registry
+	tmpDir, err := os.MkdirTemp("", "ason_registry_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	registry := newTestRegistry(t, tmpDir)
+
+	// Create a test template directory with both ason.toml and ason.yaml
+	testTemplateDir, err := os.MkdirTemp("", "test_template")
+	if err != nil {
+		t.Fatalf("Failed to create test template dir: %v", err)
+	}
+	defer os.RemoveAll(testTemplateDir)
+
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "ason.toml"), []byte(`description = "toml"`), 0644); err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "ason.yaml"), []byte(`description: yaml`), 0644); err != nil {
+		t.Fatalf("Failed to create ason.yaml: %v", err)
+	}
+
+	err = registry.Add("conflict", testTemplateDir, "", "")
+	if err == nil {
+		t.Fatal("Expected error for conflicting config files, got nil")
+	}
+
+	// Nothing should have been copied into the registry
+	if _, err := os.Stat(filepath.Join(tmpDir, "templates", "conflict")); !os.IsNotExist(err) {
+		t.Error("Template directory should not be created when config is ambiguous")
+	}
+}
+
+func TestRegistry_LoadTemplateConfig_MatchesTemplateLoad(t *testing.T) {
+	testTemplateDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(testTemplateDir, "ason.toml"), []byte(`
+name = "Shared Template"
+description = "Loaded by registry and generator"
+version = "1.2.0"
+author = "Test Author"
+type = "service"
+engine = "pongo2"
+tags = ["go", "api"]
+ignore = ["*.log"]
+
+[[variables]]
+name = "project_name"
+prompt = "Project name"
+required = true
+choices = ["a", "b"]
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+
+	registry := newTestRegistry(t, t.TempDir())
+	regConfig, err := registry.loadTemplateConfig(testTemplateDir)
+	if err != nil {
+		t.Fatalf("loadTemplateConfig() failed: %v", err)
+	}
+
+	genConfig, err := template.Load(testTemplateDir)
+	if err != nil {
+		t.Fatalf("template.Load() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(regConfig, genConfig) {
+		t.Errorf("registry config %+v does not match template config %+v", regConfig, genConfig)
+	}
+	if !reflect.DeepEqual(regConfig.Tags, []string{"go", "api"}) {
+		t.Errorf("Tags = %v, want [go api]", regConfig.Tags)
+	}
+	if !reflect.DeepEqual(regConfig.Ignore, []string{"*.log"}) {
+		t.Errorf("Ignore = %v, want [*.log]", regConfig.Ignore)
+	}
+}
+
+func TestRegistry_Config_PreservesPromptsAndChoices(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(testTemplateDir, "ason.toml"), []byte(`
+[[variables]]
+name = "license"
+prompt = "Which license?"
+default = "MIT"
+choices = ["MIT", "Apache-2.0"]
+example = "MIT"
+
+[[variables]]
+name = "runtime"
+description = "Runtime to target"
+options = ["go", "node"]
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+
+	if err := registry.Add("prompted", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	config, err := registry.Config("prompted")
+	if err != nil {
+		t.Fatalf("Config() failed: %v", err)
+	}
+
+	if len(config.Variables) != 2 {
+		t.Fatalf("Expected 2 variables, got %d", len(config.Variables))
+	}
+
+	license := config.Variables[0]
+	if license.PromptText() != "Which license?" {
+		t.Errorf("PromptText() = %v, want %v", license.PromptText(), "Which license?")
+	}
+	if !reflect.DeepEqual(license.Choices, []string{"MIT", "Apache-2.0"}) {
+		t.Errorf("Choices = %v, want [MIT Apache-2.0]", license.Choices)
+	}
+	if license.Default != "MIT" || license.Example != "MIT" {
+		t.Errorf("Default/Example = %v/%v, want MIT/MIT", license.Default, license.Example)
+	}
+
+	runtime := config.Variables[1]
+	if runtime.PromptText() != "Runtime to target" {
+		t.Errorf("PromptText() = %v, want %v", runtime.PromptText(), "Runtime to target")
+	}
+	if !reflect.DeepEqual(runtime.Choices, []string{"go", "node"}) {
+		t.Errorf("Choices = %v, want options folded into choices", runtime.Choices)
+	}
+
+	if _, err := registry.Config("missing"); err == nil {
+		t.Error("Expected error for unknown template, got nil")
+	}
+}
+
+func TestRegistry_VariableOrder(t *testing.T) {
+	registryDir := t.TempDir()
+	registry := newTestRegistry(t, registryDir)
+
+	testTemplateDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(testTemplateDir, "ason.toml"), []byte(`
+[[variables]]
+name = "zone"
+
+[[variables]]
+name = "project_name"
+
+[[variables]]
+name = "author"
+
+[[variables]]
+name = "module"
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+
+	if err := registry.Add("ordered", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	// Read the metadata back from disk, as a later command would
+	reopened := newTestRegistry(t, registryDir)
+	want := []string{"zone", "project_name", "author", "module"}
+
+	entry, err := reopened.Entry("ordered")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if !reflect.DeepEqual(entry.Variables, want) {
+		t.Errorf("Variables = %v, want declaration order %v", entry.Variables, want)
+	}
+
+	config, err := reopened.Config("ordered")
+	if err != nil {
+		t.Fatalf("Config() failed: %v", err)
+	}
+	var names []string
+	for _, v := range config.Variables {
+		names = append(names, v.Name)
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Config() variables = %v, want declaration order %v", names, want)
+	}
+}
+
+func TestRegistry_MetadataIsStable(t *testing.T) {
+	fixed := time.Date(2025, 10, 22, 12, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return fixed }
+	defer func() { now = originalNow }()
+
+	sources := make(map[string]string)
+	for _, name := range []string{"alpha", "bravo", "charlie", "delta"} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create template file: %v", err)
+		}
+		sources[name] = dir
+	}
+
+	registryDir := t.TempDir()
+	register := func(order ...string) []byte {
+		t.Helper()
+		if err := os.RemoveAll(registryDir); err != nil {
+			t.Fatalf("Failed to reset registry: %v", err)
+		}
+		registry := newTestRegistry(t, registryDir)
+		for _, name := range order {
+			if err := registry.Add(name, sources[name], "", ""); err != nil {
+				t.Fatalf("Add(%s) failed: %v", name, err)
+			}
+		}
+		data, err := os.ReadFile(filepath.Join(registryDir, "registry.toml"))
+		if err != nil {
+			t.Fatalf("Failed to read metadata: %v", err)
+		}
+		return data
+	}
+
+	first := register("alpha", "bravo", "charlie", "delta")
+	second := register("delta", "bravo", "alpha", "charlie")
+	if !bytes.Equal(first, second) {
+		t.Errorf("metadata depends on registration order:\n%s\n---\n%s", first, second)
+	}
+}
+
+func TestRegistry_Add_HiddenFiles(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml": "name: ci",
+		".private/notes.txt":       "notes",
+		"README.md":                "# readme",
+	}
+	for name, content := range files {
+		path := filepath.Join(testTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	if err := registry.Add("hidden", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	entry, err := registry.Entry("hidden")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(entry.Path, ".github", "workflows", "ci.yml")); err != nil {
+		t.Errorf(".github/workflows/ci.yml should be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(entry.Path, ".private")); !os.IsNotExist(err) {
+		t.Error(".private should not be copied, including its contents")
+	}
+	if !reflect.DeepEqual(entry.SkippedHidden, []string{".private"}) {
+		t.Errorf("SkippedHidden = %v, want [.private]", entry.SkippedHidden)
+	}
+
+	// include_hidden keeps everything
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "ason.toml"), []byte("include_hidden = true"), 0644); err != nil {
+		t.Fatalf("Failed to create ason.toml: %v", err)
+	}
+	if err := registry.Add("all-hidden", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	path, _ := registry.Get("all-hidden")
+	if _, err := os.Stat(filepath.Join(path, ".private", "notes.txt")); err != nil {
+		t.Errorf(".private/notes.txt should be copied with include_hidden: %v", err)
+	}
+}
+
+func TestRegistry_AddWithOptions_IncludeHidden(t *testing.T) {
+	registryPath := t.TempDir()
+	registry := newTestRegistry(t, registryPath)
+
+	testTemplateDir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml": "name: ci",
+		".private/notes.txt":       "notes",
+		".git/HEAD":                "ref: refs/heads/main",
+		"README.md":                "# readme",
+	}
+	for name, content := range files {
+		path := filepath.Join(testTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	if err := registry.AddWithOptions("dotfiles", testTemplateDir, "", "", AddOptions{IncludeHidden: true}); err != nil {
+		t.Fatalf("AddWithOptions() failed: %v", err)
+	}
+
+	// Read the entry back from the stored metadata
+	entry, err := newTestRegistry(t, registryPath).Entry("dotfiles")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if !entry.IncludeHidden {
+		t.Error("IncludeHidden should be recorded in the entry")
+	}
+	if len(entry.SkippedHidden) != 0 {
+		t.Errorf("SkippedHidden = %v, want none", entry.SkippedHidden)
+	}
+
+	for _, name := range []string{".github/workflows/ci.yml", ".private/notes.txt"} {
+		if _, err := os.Stat(filepath.Join(entry.Path, name)); err != nil {
+			t.Errorf("%s should be copied: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(entry.Path, ".git")); !os.IsNotExist(err) {
+		t.Error(".git should never be copied")
+	}
+}
+
+func TestRegistry_Add_IgnorePatterns(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	files := map[string]string{
+		"ason.toml":                 "ignore = [\"**/*.log\", \"*.toml\"]",
+		template.IgnoreFileName:     "node_modules/**\n",
+		"README.md":                 "# readme",
+		"logs/2024/debug.log":       "debug",
+		"node_modules/pkg/index.js": "module.exports = {}",
+	}
+	for name, content := range files {
+		path := filepath.Join(testTemplateDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	preview, err := registry.Preview("ignoring", testTemplateDir, "", "", AddOptions{})
+	if err != nil {
+		t.Fatalf("Preview() failed: %v", err)
+	}
+
+	if err := registry.Add("ignoring", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	entry, err := registry.Entry("ignoring")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+
+	for _, name := range []string{"ason.toml", "README.md", "logs/2024"} {
+		if _, err := os.Stat(filepath.Join(entry.Path, name)); err != nil {
+			t.Errorf("%s should be copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{"logs/2024/debug.log", "node_modules", template.IgnoreFileName} {
+		if _, err := os.Stat(filepath.Join(entry.Path, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be copied", name)
+		}
+	}
+	if entry.Files != 2 || preview.Files != entry.Files {
+		t.Errorf("Files = %d (preview %d), want 2", entry.Files, preview.Files)
+	}
+	if outdated, err := registry.IsOutdated(*entry); err != nil || outdated {
+		t.Errorf("IsOutdated() = %v, %v, want false for an unchanged source", outdated, err)
+	}
+}
+
+func TestRegistry_Add_EmptyDirectories(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(testTemplateDir, "logs"), 0755); err != nil {
+		t.Fatalf("Failed to create logs: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "README.md"), []byte("# readme"), 0644); err != nil {
+		t.Fatalf("Failed to create README.md: %v", err)
+	}
+
+	if err := registry.Add("empty-dirs", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	path, _ := registry.Get("empty-dirs")
+	if info, err := os.Stat(filepath.Join(path, "logs")); err != nil || !info.IsDir() {
+		t.Errorf("empty logs directory should be copied: %v", err)
+	}
+}
+
+func TestRegistry_Add_PreservesMode(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "setup.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to create setup.sh: %v", err)
+	}
+
+	if err := registry.Add("scripts", testTemplateDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	path, _ := registry.Get("scripts")
+	info, err := os.Stat(filepath.Join(path, "setup.sh"))
+	if err != nil {
+		t.Fatalf("setup.sh should be copied: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("setup.sh mode = %v, want 0755", info.Mode().Perm())
+	}
+}
+
+func TestRegistry_SystemDirs(t *testing.T) {
+	// Fake system registry with a relative template path and a default one
+	systemDir := t.TempDir()
+	for _, name := range []string{"shared", "license"} {
+		dir := filepath.Join(systemDir, "templates", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create system template: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create system template file: %v", err)
+		}
+	}
+	err := os.WriteFile(filepath.Join(systemDir, "registry.toml"), []byte(`
+[templates.shared]
+name = "shared"
+path = "templates/shared"
+description = "System shared template"
+
+[templates.license]
+name = "license"
+description = "System license template"
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create system registry.toml: %v", err)
+	}
+
+	registry := newTestRegistry(t, t.TempDir()).WithSystemDirs([]string{systemDir, filepath.Join(t.TempDir(), "missing")})
+
+	// User template shadows the system one of the same name
+	userTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(userTemplateDir, "README.md"), []byte("user"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := registry.Add("license", userTemplateDir, "User license template", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	templates, err := registry.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(templates) != 2 {
+		t.Fatalf("Expected 2 templates, got %d", len(templates))
+	}
+
+	shared, err := registry.Entry("shared")
+	if err != nil {
+		t.Fatalf("Entry(shared) failed: %v", err)
+	}
+	if shared.Origin != OriginSystem {
+		t.Errorf("shared Origin = %v, want %v", shared.Origin, OriginSystem)
+	}
+	if shared.Path != filepath.Join(systemDir, "templates", "shared") {
+		t.Errorf("shared Path = %v, want it resolved inside the system registry", shared.Path)
+	}
+
+	license, err := registry.Entry("license")
+	if err != nil {
+		t.Fatalf("Entry(license) failed: %v", err)
+	}
+	if license.Origin != OriginUser || license.Description != "User license template" {
+		t.Errorf("license = %+v, want the user template", license)
+	}
+
+	// System templates are read-only
+	if err := registry.Remove("shared", false, ""); err == nil {
+		t.Error("Expected error removing a system template, got nil")
+	}
+	if _, err := os.Stat(shared.Path); err != nil {
+		t.Errorf("System template should be untouched: %v", err)
+	}
+
+	// Removing the user template reveals the system one again
+	if err := registry.Remove("license", false, ""); err != nil {
+		t.Fatalf("Remove(license) failed: %v", err)
+	}
+	license, err = registry.Entry("license")
+	if err != nil {
+		t.Fatalf("Entry(license) failed: %v", err)
+	}
+	if license.Origin != OriginSystem {
+		t.Errorf("license Origin = %v, want %v after removing the user copy", license.Origin, OriginSystem)
+	}
+}
+
+func TestRegistry_Stats(t *testing.T) {
+	tmpDir := t.TempDir()
+	registry := newTestRegistry(t, tmpDir)
+
+	stats, err := registry.Stats()
+	if err != nil {
+		t.Fatalf("Stats() failed: %v", err)
+	}
+	if stats.Templates != 0 || stats.TotalSize != 0 || !stats.Updated.IsZero() {
+		t.Errorf("Stats() on empty registry = %+v", stats)
+	}
+
+	testTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "a.txt"), []byte("12345"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	for _, name := range []string{"one", "two"} {
+		if err := registry.Add(name, testTemplateDir, "", ""); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+	}
+
+	stats, err = registry.Stats()
+	if err != nil {
+		t.Fatalf("Stats() failed: %v", err)
+	}
+	if stats.Path != tmpDir {
+		t.Errorf("Stats().Path = %v, want %v", stats.Path, tmpDir)
+	}
+	if stats.Templates != 2 {
+		t.Errorf("Stats().Templates = %d, want 2", stats.Templates)
+	}
+	if stats.TotalSize != 10 {
+		t.Errorf("Stats().TotalSize = %d, want 10", stats.TotalSize)
+	}
+	if stats.Updated.IsZero() {
+		t.Error("Stats().Updated should be set after adding templates")
+	}
+	if stats.Version != MetadataVersion {
+		t.Errorf("Stats().Version = %d, want %d", stats.Version, MetadataVersion)
+	}
+}
+
+func TestRegistry_AllTags(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	tags, err := registry.AllTags()
+	if err != nil {
+		t.Fatalf("AllTags() failed: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("AllTags() on empty registry = %v", tags)
+	}
+
+	for name, tagList := range map[string]string{
+		"api":      `["go", "api", "go"]`,
+		"cli":      `["go", "cli"]`,
+		"site":     `["web"]`,
+		"untagged": `[]`,
+	} {
+		templateDir := t.TempDir()
+		config := "description = \"Demo\"\ntags = " + tagList + "\n"
+		if err := os.WriteFile(filepath.Join(templateDir, "ason.toml"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to create ason.toml: %v", err)
+		}
+		if err := registry.Add(name, templateDir, "", ""); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+	}
+
+	tags, err = registry.AllTags()
+	if err != nil {
+		t.Fatalf("AllTags() failed: %v", err)
+	}
+	want := map[string]int{"go": 2, "api": 1, "cli": 1, "web": 1}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("AllTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestRegistry_Types(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	testTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	for name, templateType := range map[string]string{
+		"api":    "service",
+		"worker": "service",
+		"tool":   "cli",
+		"notes":  "",
+	} {
+		if err := registry.Add(name, testTemplateDir, "", templateType); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+	}
+
+	types, err := registry.Types()
+	if err != nil {
+		t.Fatalf("Types() failed: %v", err)
+	}
+	want := map[string]int{"service": 2, "cli": 1}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("Types() = %v, want %v", types, want)
+	}
+}
+
+func TestRegistry_ReadOnly(t *testing.T) {
+	registryPath := t.TempDir()
+	writable := newTestRegistry(t, registryPath)
+
+	testTemplateDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(testTemplateDir, "README.md"), []byte("# Demo"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := writable.Add("baked", testTemplateDir, "Baked in", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	// Reopen the registry as if its filesystem were mounted read-only
+	originalMkdirAll := mkdirAll
+	defer func() { mkdirAll = originalMkdirAll }()
+	mkdirAll = func(path string, perm os.FileMode) error {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: syscall.EROFS}
+	}
+
+	for _, dir := range []string{registryPath, filepath.Join(t.TempDir(), "missing")} {
+		if _, err := NewRegistryAt(dir); err != nil {
+			t.Fatalf("NewRegistryAt(%s) on a read-only filesystem failed: %v", dir, err)
+		}
+	}
+
+	registry := newTestRegistry(t, registryPath)
+	if !registry.ReadOnly() {
+		t.Fatal("ReadOnly() = false for a registry that can't be created")
+	}
+
+	templates, err := registry.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(templates) != 1 || templates[0].Name != "baked" {
+		t.Errorf("List() = %v, want the baked template", templates)
+	}
+	if _, err := registry.Get("baked"); err != nil {
+		t.Errorf("Get() failed: %v", err)
+	}
+
+	if err := registry.Add("another", testTemplateDir, "", ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Add() error = %v, want ErrReadOnly", err)
+	}
+	if err := registry.Remove("baked", false, ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Remove() error = %v, want ErrReadOnly", err)
+	}
+	if _, err := os.Stat(filepath.Join(registryPath, "templates", "another")); !os.IsNotExist(err) {
+		t.Errorf("Add() on a read-only registry should copy nothing, stat error = %v", err)
+	}
+
+	// Other failures to create the registry are still errors
+	mkdirAll = func(path string, perm os.FileMode) error {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: syscall.ENOTDIR}
+	}
+	if _, err := NewRegistryAt(t.TempDir()); err == nil {
+		t.Error("NewRegistryAt() should fail when the directory can't be created for other reasons")
+	}
+}
+
+func TestRegistry_AddWithOptions_Source(t *testing.T) {
+	registryPath := t.TempDir()
+	registry := newTestRegistry(t, registryPath)
+
+	cloneDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cloneDir, "README.md"), []byte("# Demo"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+
+	const source = "https://github.com/example/go-service.git"
+	if err := registry.AddWithOptions("go-service", cloneDir, "", "", AddOptions{Source: source}); err != nil {
+		t.Fatalf("AddWithOptions() failed: %v", err)
+	}
+	if err := registry.AddWithOptions("local", cloneDir, "", "", AddOptions{}); err != nil {
+		t.Fatalf("AddWithOptions() failed: %v", err)
+	}
+
+	// Read the entries back from the stored metadata
+	reopened := newTestRegistry(t, registryPath)
+	entry, err := reopened.Entry("go-service")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if entry.Source != source {
+		t.Errorf("Source = %q, want %q", entry.Source, source)
+	}
+	if entry.Path != filepath.Join(registryPath, "templates", "go-service") {
+		t.Errorf("Path = %q, want the copy in the registry", entry.Path)
+	}
+	if outdated, err := reopened.IsOutdated(*entry); err != nil || outdated {
+		t.Errorf("IsOutdated() = %v, %v for a URL source, want false", outdated, err)
+	}
+
+	local, err := reopened.Entry("local")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+	if local.Source != cloneDir {
+		t.Errorf("Source = %q, want the copied directory %q", local.Source, cloneDir)
+	}
+}
+
+func TestRegistry_MigratesUnversionedMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	metaPath := filepath.Join(tmpDir, "registry.toml")
+
+	// Metadata written before format versioning existed
+	err := os.WriteFile(metaPath, []byte(`updated = 2024-01-01T00:00:00Z
+
+[templates.legacy]
+name = "legacy"
+path = "/tmp/legacy"
+description = "Registered by an older ason"
+`), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write registry.toml: %v", err)
+	}
+
+	registry := newTestRegistry(t, tmpDir)
+
+	templates, err := registry.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(templates) != 1 || templates[0].Name != "legacy" {
+		t.Fatalf("List() = %+v, want the legacy template", templates)
+	}
+
+	// The migrated metadata was saved back with the current version
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		t.Fatalf("Failed to read registry.toml: %v", err)
+	}
+	var meta RegistryMetadata
+	if err := toml.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("Failed to parse registry.toml: %v", err)
+	}
+	if meta.Version != MetadataVersion {
+		t.Errorf("Saved metadata version = %d, want %d", meta.Version, MetadataVersion)
+	}
+	if _, ok := meta.Templates["legacy"]; !ok {
+		t.Error("Migrated metadata lost the legacy template")
+	}
+}
+
+func TestRegistry_RejectsNewerMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "registry.toml"), []byte("version = 99\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write registry.toml: %v", err)
+	}
+
+	registry := newTestRegistry(t, tmpDir)
+	if _, err := registry.List(); err == nil {
+		t.Error("Expected error for metadata from a newer ason, got nil")
+	}
+}
+
 func TestRegistry_Get(t *testing.T) {
 	// Create temporary registry
 	tmpDir, err := os.MkdirTemp("", "ason_registry_test")
@@ -148,7 +1107,7 @@ func TestRegistry_Get(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	// Create a test template directory
 	testTemplateDir, err := os.MkdirTemp("", "test_template")
@@ -186,6 +1145,45 @@ func TestRegistry_Get(t *testing.T) {
 	}
 }
 
+func TestRegistry_Entry_IgnoresCase(t *testing.T) {
+	reg := newTestRegistry(t, t.TempDir())
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	if err := reg.Add("golang", src, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	entry, err := reg.Entry("GoLang")
+	if err != nil {
+		t.Fatalf("Entry(GoLang) failed: %v", err)
+	}
+	if entry.Name != "golang" {
+		t.Errorf("Entry(GoLang).Name = %q, want the canonical golang", entry.Name)
+	}
+
+	if path, err := reg.Get("GOLANG"); err != nil || path != entry.Path {
+		t.Errorf("Get(GOLANG) = %q, %v; want %q", path, err, entry.Path)
+	}
+
+	// Two templates differing only in case make the fallback ambiguous
+	if err := reg.Add("GoLang", src, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	if _, err := reg.Entry("GOLANG"); !errors.Is(err, ErrAmbiguousTemplate) {
+		t.Errorf("Entry(GOLANG) error = %v, want ErrAmbiguousTemplate", err)
+	}
+
+	// Exact names still resolve
+	for _, name := range []string{"golang", "GoLang"} {
+		if entry, err := reg.Entry(name); err != nil || entry.Name != name {
+			t.Errorf("Entry(%s) = %v, %v; want the exact match", name, entry, err)
+		}
+	}
+}
+
 func TestRegistry_Remove(t *testing.T) {
 	// Create temporary registry
 	tmpDir, err := os.MkdirTemp("", "ason_registry_test")
@@ -194,7 +1192,7 @@ func TestRegistry_Remove(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	// Create a test template directory
 	testTemplateDir, err := os.MkdirTemp("", "test_template")
@@ -254,7 +1252,7 @@ func TestRegistry_RemoveWithBackup(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	// Create a test template directory
 	testTemplateDir, err := os.MkdirTemp("", "test_template")
@@ -305,7 +1303,7 @@ func TestRegistry_RemoveNonExistent(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	registry := &Registry{path: tmpDir}
+	registry := newTestRegistry(t, tmpDir)
 
 	// Try to remove non-existent template
 	err = registry.Remove("non-existent", false, "")
@@ -382,3 +1380,67 @@ func TestTemplateEntry(t *testing.T) {
 		t.Errorf("TemplateEntry.Variables = %v, want [name version]", entry.Variables)
 	}
 }
+
+func TestRegistry_IsOutdated(t *testing.T) {
+	registry := newTestRegistry(t, t.TempDir())
+
+	sourceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# original"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	// Hidden paths that aren't copied don't make the template outdated
+	if err := os.MkdirAll(filepath.Join(sourceDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, ".git", "HEAD"), []byte("ref"), 0644); err != nil {
+		t.Fatalf("Failed to create .git/HEAD: %v", err)
+	}
+
+	if err := registry.Add("drifting", sourceDir, "", ""); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	entry, err := registry.Entry("drifting")
+	if err != nil {
+		t.Fatalf("Entry() failed: %v", err)
+	}
+
+	outdated, err := registry.IsOutdated(*entry)
+	if err != nil {
+		t.Fatalf("IsOutdated() failed: %v", err)
+	}
+	if outdated {
+		t.Error("IsOutdated() = true right after registering, want false")
+	}
+
+	// Adding a file to the source makes it outdated
+	if err := os.WriteFile(filepath.Join(sourceDir, "NEW.md"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to add source file: %v", err)
+	}
+	if outdated, err := registry.IsOutdated(*entry); err != nil || !outdated {
+		t.Errorf("IsOutdated() after adding a file = %v, %v; want true, nil", outdated, err)
+	}
+	if err := os.Remove(filepath.Join(sourceDir, "NEW.md")); err != nil {
+		t.Fatalf("Failed to remove source file: %v", err)
+	}
+
+	// So does editing a file, even when its size stays the same
+	later := entry.Added.Add(time.Minute)
+	if err := os.WriteFile(filepath.Join(sourceDir, "README.md"), []byte("# modified"), 0644); err != nil {
+		t.Fatalf("Failed to modify source file: %v", err)
+	}
+	if err := os.Chtimes(filepath.Join(sourceDir, "README.md"), later, later); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+	if outdated, err := registry.IsOutdated(*entry); err != nil || !outdated {
+		t.Errorf("IsOutdated() after editing a file = %v, %v; want true, nil", outdated, err)
+	}
+
+	// A source that no longer exists can't be compared
+	if err := os.RemoveAll(sourceDir); err != nil {
+		t.Fatalf("Failed to remove source: %v", err)
+	}
+	if outdated, err := registry.IsOutdated(*entry); err != nil || outdated {
+		t.Errorf("IsOutdated() with missing source = %v, %v; want false, nil", outdated, err)
+	}
+}
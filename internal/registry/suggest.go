@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to max registered template names close to name, for
+// "did you mean" hints when a template isn't found. Names are compared
+// case-insensitively by edit distance, closest first; a name qualifies when
+// it is within a third of name's length, and at least one edit, of it.
+func (r *Registry) Suggest(name string, max int) []string {
+	entries, err := r.entries()
+	if err != nil || max <= 0 {
+		return nil
+	}
+
+	target := strings.ToLower(name)
+	limit := len([]rune(target)) / 3
+	if limit < 1 {
+		limit = 1
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for entryName := range entries {
+		if entryName == name {
+			continue
+		}
+		if d := levenshtein(target, strings.ToLower(entryName)); d <= limit {
+			candidates = append(candidates, candidate{entryName, d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < max; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// levenshtein returns the number of single-rune insertions, deletions and
+// substitutions needed to turn a into b
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"golng", "golang", 1},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Suggest(t *testing.T) {
+	reg := newTestRegistry(t, t.TempDir())
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "README.md"), []byte("# template"), 0644); err != nil {
+		t.Fatalf("Failed to create template file: %v", err)
+	}
+	for _, name := range []string{"golang-service", "go-service", "react-app", "python-lambda"} {
+		if err := reg.Add(name, src, "", ""); err != nil {
+			t.Fatalf("Add(%s) failed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		max  int
+		want []string
+	}{
+		{"golng-service", 3, []string{"golang-service", "go-service"}},
+		{"golng-service", 1, []string{"golang-service"}},
+		{"Golang-Servce", 1, []string{"golang-service"}},
+		{"goo-service", 3, []string{"go-service"}},
+		{"raect-app", 3, []string{"react-app"}},
+		{"terraform", 3, nil},
+	}
+
+	for _, tt := range tests {
+		if got := reg.Suggest(tt.name, tt.max); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %v, want %v", tt.name, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CheckValues checks resolved variable values against their declarations:
+// required variables need a non-empty value, and each value must convert to
+// the declared type, be one of the declared choices and match the
+// validation pattern. List values are checked item by item. Every violation
+// is returned, in declaration order; secret values are never quoted.
+func CheckValues(variables []Variable, values map[string]interface{}) []error {
+	var errs []error
+	for _, v := range variables {
+		value, ok := values[v.Name]
+		if !ok || value == nil || value == "" {
+			if v.Required {
+				errs = append(errs, fmt.Errorf("variable %s is required but has no value", v.Name))
+			}
+			continue
+		}
+
+		if raw, ok := value.(string); ok {
+			if _, err := CoerceValue(v, raw); err != nil {
+				if v.IsSecret() {
+					err = fmt.Errorf("variable %s: value is not a valid %s", v.Name, v.Type)
+				}
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		var pattern *regexp.Regexp
+		if v.Validation != "" {
+			var err error
+			if pattern, err = regexp.Compile(v.Validation); err != nil {
+				errs = append(errs, fmt.Errorf("variable %s has an invalid validation pattern: %w", v.Name, err))
+				continue
+			}
+		}
+
+		for _, item := range valueItems(v, value) {
+			shown := fmt.Sprintf("%q", item)
+			if v.IsSecret() {
+				shown = "value"
+			}
+			if len(v.Choices) > 0 && !containsString(v.Choices, item) {
+				errs = append(errs, fmt.Errorf("variable %s: %s is not one of %s", v.Name, shown, strings.Join(v.Choices, ", ")))
+			}
+			if pattern != nil && !pattern.MatchString(item) {
+				errs = append(errs, fmt.Errorf("variable %s: %s does not match %s", v.Name, shown, v.Validation))
+			}
+		}
+	}
+	return errs
+}
+
+// valueItems returns the values to check choices and patterns against: the
+// items of a list variable, or the value itself as a string
+func valueItems(v Variable, value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		if strings.ToLower(v.Type) == "list" {
+			return splitList(value)
+		}
+		return []string{value}
+	case []string:
+		return value
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprintf("%v", item)
+		}
+		return items
+	default:
+		return []string{fmt.Sprintf("%v", value)}
+	}
+}
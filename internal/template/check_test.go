@@ -0,0 +1,60 @@
+package template
+
+import (
+	"testing"
+)
+
+func TestCheckValues(t *testing.T) {
+	variables := []Variable{
+		{Name: "name", Required: true},
+		{Name: "region", Choices: []string{"us", "eu"}},
+		{Name: "port", Type: "integer"},
+		{Name: "slug", Validation: "^[a-z-]+$"},
+		{Name: "zones", Type: "list", Choices: []string{"a", "b"}},
+		{Name: "token", Secret: true, Validation: "^tok_"},
+		{Name: "replicas", Type: "integer", Default: int64(3)},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		values := map[string]interface{}{
+			"name":     "demo",
+			"region":   "eu",
+			"port":     "8080",
+			"slug":     "my-app",
+			"zones":    "a, b",
+			"token":    "tok_123",
+			"replicas": int64(3),
+		}
+		if errs := CheckValues(variables, values); len(errs) != 0 {
+			t.Errorf("CheckValues() = %v, want no violations", errs)
+		}
+	})
+
+	t.Run("violations", func(t *testing.T) {
+		values := map[string]interface{}{
+			"region": "mars",
+			"port":   "eighty",
+			"slug":   "My App",
+			"zones":  []string{"a", "c"},
+			"token":  "s3cret",
+		}
+		want := []string{
+			"variable name is required but has no value",
+			`variable region: "mars" is not one of us, eu`,
+			`variable port: "eighty" is not an integer`,
+			`variable slug: "My App" does not match ^[a-z-]+$`,
+			`variable zones: "c" is not one of a, b`,
+			"variable token: value does not match ^tok_",
+		}
+
+		errs := CheckValues(variables, values)
+		if len(errs) != len(want) {
+			t.Fatalf("CheckValues() = %v, want %d violations", errs, len(want))
+		}
+		for i, err := range errs {
+			if err.Error() != want[i] {
+				t.Errorf("violation %d = %q, want %q", i, err.Error(), want[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CoerceValue converts a raw variable value, as given on the command line or
+// in a variable file, to the Go type for the variable's declared type:
+//
+//	string (or no type)  string
+//	password             string
+//	integer              int
+//	number               float64
+//	boolean              bool
+//	list                 []string, split on commas
+//
+// Malformed values for numeric and boolean variables are rejected. Types ason
+// doesn't know are passed through as strings.
+func CoerceValue(def Variable, raw string) (interface{}, error) {
+	switch strings.ToLower(def.Type) {
+	case "integer", "int":
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not an integer", def.Name, raw)
+		}
+		return n, nil
+	case "number", "float":
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a number", def.Name, raw)
+		}
+		return f, nil
+	case "boolean", "bool":
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a boolean (use true or false)", def.Name, raw)
+		}
+		return b, nil
+	case "list":
+		return splitList(raw), nil
+	default:
+		return raw, nil
+	}
+}
+
+// splitList splits a comma-separated list, trimming spaces and dropping
+// empty items
+func splitList(raw string) []string {
+	items := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
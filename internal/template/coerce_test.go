@@ -0,0 +1,55 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		typ  string
+		raw  string
+		want interface{}
+	}{
+		{"", "hello", "hello"},
+		{"string", "8080", "8080"},
+		{"integer", "8080", 8080},
+		{"integer", " -3 ", -3},
+		{"number", "1.5", 1.5},
+		{"number", "2", 2.0},
+		{"boolean", "true", true},
+		{"boolean", "0", false},
+		{"list", "us-east-1, us-west-2", []string{"us-east-1", "us-west-2"}},
+		{"list", "", []string{}},
+		{"choice", "b", "b"},
+	}
+
+	for _, tt := range tests {
+		got, err := CoerceValue(Variable{Name: "v", Type: tt.typ}, tt.raw)
+		if err != nil {
+			t.Errorf("CoerceValue(%q, %q) failed: %v", tt.typ, tt.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CoerceValue(%q, %q) = %#v, want %#v", tt.typ, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestCoerceValue_Invalid(t *testing.T) {
+	tests := []struct {
+		typ string
+		raw string
+	}{
+		{"integer", "abc"},
+		{"integer", "1.5"},
+		{"number", "one"},
+		{"boolean", "maybe"},
+	}
+
+	for _, tt := range tests {
+		if _, err := CoerceValue(Variable{Name: "port", Type: tt.typ}, tt.raw); err == nil {
+			t.Errorf("CoerceValue(%q, %q) should fail", tt.typ, tt.raw)
+		}
+	}
+}
@@ -2,30 +2,330 @@ package template
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/madstone-tech/ason/internal/glob"
+	"gopkg.in/yaml.v3"
 )
 
+// ConfigFileNames lists the template config file names ason recognises,
+// in lookup order.
+var ConfigFileNames = []string{"ason.toml", "ason.yaml", "ason.yml", "ason.json"}
+
+// DefaultRenderSuffixes are the file suffixes that mark a file for rendering
+// and are dropped from the output name, e.g. main.go.tmpl becomes main.go.
+var DefaultRenderSuffixes = []string{".ason", ".tmpl"}
+
+// DefaultPartialsDirs are the template directories holding fragments pulled
+// in with `{% include %}` or `{% extends %}`; they aren't generated themselves
+var DefaultPartialsDirs = []string{"_partials", "_includes"}
+
+// DefaultDotfilePrefix marks template paths that become dotfiles in the
+// output, so dot_gitignore is generated as .gitignore
+const DefaultDotfilePrefix = "dot_"
+
+// KeepMarkers are the placeholder files that keep an otherwise empty
+// directory in version control. A template directory holding nothing else
+// is generated even when the marker itself is excluded.
+var KeepMarkers = []string{".gitkeep", ".keep"}
+
+// DefaultHiddenAllow lists the hidden files and directories (names starting
+// with ".") kept by default. Other hidden paths are skipped unless the
+// template sets include_hidden or lists them in hidden_allow.
+var DefaultHiddenAllow = []string{
+	".gitignore", ".gitattributes", ".gitkeep", ".keep", ".env.example",
+	".github", ".gitlab", ".gitlab-ci.yml", ".devcontainer", ".vscode",
+	".editorconfig", ".dockerignore", ".golangci.*", ".pre-commit-config.yaml",
+	".prettierrc*", ".eslintrc*", ".nvmrc", ".tool-versions",
+}
+
+// IgnoreFileName is the file in a template's root listing further ignore
+// patterns, one per line
+const IgnoreFileName = ".asonignore"
+
+// alwaysSkipped are hidden paths never taken from a template
+var alwaysSkipped = []string{".git", ".DS_Store", IgnoreFileName}
+
+// ErrNoConfig is returned by FindConfig when a template has no config file.
+var ErrNoConfig = errors.New("no template config file found")
+
 // Config represents the template configuration
 type Config struct {
-	Name        string     `toml:"name" json:"name"`
-	Description string     `toml:"description" json:"description"`
-	Version     string     `toml:"version" json:"version"`
-	Author      string     `toml:"author" json:"author"`
-	Engine      string     `toml:"engine" json:"engine"`
-	Variables   []Variable `toml:"variables" json:"variables"`
+	Name        string     `toml:"name" yaml:"name" json:"name"`
+	Description string     `toml:"description" yaml:"description" json:"description"`
+	Version     string     `toml:"version" yaml:"version" json:"version"`
+	Author      string     `toml:"author" yaml:"author" json:"author"`
+	Engine      string     `toml:"engine" yaml:"engine" json:"engine"`
+	Type        string     `toml:"type,omitempty" yaml:"type,omitempty" json:"type,omitempty"`
+	Variables   []Variable `toml:"variables" yaml:"variables" json:"variables"`
+	Ignore      []string   `toml:"ignore,omitempty" yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	Tags        []string   `toml:"tags,omitempty" yaml:"tags,omitempty" json:"tags,omitempty"`
+	Rendering   Rendering  `toml:"rendering,omitempty" yaml:"rendering,omitempty" json:"rendering,omitempty"`
+
+	// OutputName is the directory `ason new` generates into when no output
+	// is given, rendered with the generation variables, e.g.
+	// "{{ project_name }}"
+	OutputName string `toml:"output_name,omitempty" yaml:"output_name,omitempty" json:"output_name,omitempty"`
+
+	// MinAsonVersion is the oldest ason release the template works with
+	MinAsonVersion string `toml:"min_ason_version,omitempty" yaml:"min_ason_version,omitempty" json:"min_ason_version,omitempty"`
+
+	// RenderSuffixes overrides DefaultRenderSuffixes
+	RenderSuffixes []string `toml:"render_suffixes,omitempty" yaml:"render_suffixes,omitempty" json:"render_suffixes,omitempty"`
+
+	// DotfilePrefix replaces DefaultDotfilePrefix; an empty string turns
+	// the renaming off
+	DotfilePrefix *string `toml:"dotfile_prefix,omitempty" yaml:"dotfile_prefix,omitempty" json:"dotfile_prefix,omitempty"`
+
+	// PartialsDir replaces DefaultPartialsDirs
+	PartialsDir string `toml:"partials_dir,omitempty" yaml:"partials_dir,omitempty" json:"partials_dir,omitempty"`
+
+	// Engines maps file suffixes such as ".gotmpl" to the engine that
+	// renders files ending in them, overriding Engine per file. The
+	// suffixes are also render suffixes, dropped from output names.
+	Engines map[string]string `toml:"engines,omitempty" yaml:"engines,omitempty" json:"engines,omitempty"`
+
+	// RawPatterns are globs of template-relative paths copied verbatim,
+	// without passing through the engine
+	RawPatterns []string `toml:"raw_patterns,omitempty" yaml:"raw_patterns,omitempty" json:"raw_patterns,omitempty"`
+
+	// Executable are globs of output paths generated with mode 0755,
+	// whatever the mode of their template file
+	Executable []string `toml:"executable,omitempty" yaml:"executable,omitempty" json:"executable,omitempty"`
+
+	// IncludeHidden keeps all hidden files and directories
+	IncludeHidden bool `toml:"include_hidden,omitempty" yaml:"include_hidden,omitempty" json:"include_hidden,omitempty"`
+
+	// HiddenAllow lists extra hidden file or directory names to keep, as globs
+	HiddenAllow []string `toml:"hidden_allow,omitempty" yaml:"hidden_allow,omitempty" json:"hidden_allow,omitempty"`
+
+	// Rename rules applied to output file paths, first match wins
+	Rename []RenameRule `toml:"rename,omitempty" yaml:"rename,omitempty" json:"rename,omitempty"`
+
+	// Extends names a registered template whose files are generated
+	// underneath this one's; this template's files win on conflicts
+	Extends string `toml:"extends,omitempty" yaml:"extends,omitempty" json:"extends,omitempty"`
+
+	// Includes are registered templates generated into subdirectories
+	Includes []Include `toml:"include,omitempty" yaml:"include,omitempty" json:"include,omitempty"`
+
+	// NextSteps are hints printed after a successful generation; they are
+	// rendered with the generation variables
+	NextSteps []string `toml:"next_steps,omitempty" yaml:"next_steps,omitempty" json:"next_steps,omitempty"`
+
+	// Computed are variables derived from the others instead of asked for
+	Computed []Computed `toml:"computed,omitempty" yaml:"computed,omitempty" json:"computed,omitempty"`
+}
+
+// Computed is a variable whose value is rendered from the other variables
+// once they are all known, such as module_path = "github.com/{{ org }}/{{ name }}".
+// Computed variables are rendered in declaration order, so each can use the
+// ones declared before it.
+type Computed struct {
+	Name  string `toml:"name" yaml:"name" json:"name"`
+	Value string `toml:"value" yaml:"value" json:"value"`
+}
+
+// RenameRule renames generated files whose output path matches From.
+// To may contain template variables; without a slash it replaces only the
+// file name, otherwise it is the full output path relative to the project.
+type RenameRule struct {
+	From string `toml:"from" yaml:"from" json:"from"`
+	To   string `toml:"to" yaml:"to" json:"to"`
+}
+
+// Include generates another registered template into a subdirectory of the
+// output. The included template sees the parent's variables, overridden by
+// Vars; Path and string values in Vars may contain template variables.
+type Include struct {
+	Template string                 `toml:"template" yaml:"template" json:"template"`
+	Path     string                 `toml:"path" yaml:"path" json:"path"`
+	Vars     map[string]interface{} `toml:"vars,omitempty" yaml:"vars,omitempty" json:"vars,omitempty"`
+}
+
+// Rendering holds the `[rendering]` options that control engine output
+type Rendering struct {
+	TrimBlocks   bool `toml:"trim_blocks,omitempty" yaml:"trim_blocks,omitempty" json:"trim_blocks,omitempty"`
+	LStripBlocks bool `toml:"lstrip_blocks,omitempty" yaml:"lstrip_blocks,omitempty" json:"lstrip_blocks,omitempty"`
+	// Autoescape HTML-escapes variable output, for templates producing HTML
+	Autoescape bool `toml:"autoescape,omitempty" yaml:"autoescape,omitempty" json:"autoescape,omitempty"`
 }
 
-// Variable represents a template variable
+// Variable represents a template variable. It is the single definition shared
+// by config loading, the registry and interactive prompting.
 type Variable struct {
-	Name     string      `toml:"name" json:"name"`
-	Type     string      `toml:"type" json:"type"`
-	Prompt   string      `toml:"prompt" json:"prompt"`
-	Default  interface{} `toml:"default,omitempty" json:"default,omitempty"`
-	Required bool        `toml:"required,omitempty" json:"required,omitempty"`
-	Choices  []string    `toml:"choices,omitempty" json:"choices,omitempty"`
+	Name        string      `toml:"name" yaml:"name" json:"name"`
+	Type        string      `toml:"type" yaml:"type" json:"type"`
+	Prompt      string      `toml:"prompt" yaml:"prompt" json:"prompt"`
+	Description string      `toml:"description,omitempty" yaml:"description,omitempty" json:"description,omitempty"`
+	Default     interface{} `toml:"default,omitempty" yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool        `toml:"required,omitempty" yaml:"required,omitempty" json:"required,omitempty"`
+	Choices     []string    `toml:"choices,omitempty" yaml:"choices,omitempty" json:"choices,omitempty"`
+	Example     string      `toml:"example,omitempty" yaml:"example,omitempty" json:"example,omitempty"`
+	Validation  string      `toml:"validation,omitempty" yaml:"validation,omitempty" json:"validation,omitempty"`
+
+	// Secret marks a value, such as an API key, that is masked while typed
+	// and kept out of ason's output; type = "password" implies it
+	Secret bool `toml:"secret,omitempty" yaml:"secret,omitempty" json:"secret,omitempty"`
+
+	// Options is the older spelling of Choices accepted by the registry.
+	// LoadConfig folds it into Choices.
+	Options []string `toml:"options,omitempty" yaml:"options,omitempty" json:"options,omitempty"`
+}
+
+// PromptText returns the text to show when asking for the variable's value,
+// falling back from Prompt to Description to the variable name.
+func (v Variable) PromptText() string {
+	if v.Prompt != "" {
+		return v.Prompt
+	}
+	if v.Description != "" {
+		return v.Description
+	}
+	return v.Name
+}
+
+// IsSecret reports whether the variable holds a secret value
+func (v Variable) IsSecret() bool {
+	return v.Secret || v.Type == "password"
+}
+
+// MergeVariables merges inherited variable declarations with a template's
+// own. A declaration in own replaces the base declaration of the same name
+// in place; new ones are appended in order.
+func MergeVariables(base, own []Variable) []Variable {
+	merged := make([]Variable, 0, len(base)+len(own))
+	index := make(map[string]int, len(base))
+	for _, v := range base {
+		index[v.Name] = len(merged)
+		merged = append(merged, v)
+	}
+
+	for _, v := range own {
+		if i, ok := index[v.Name]; ok {
+			merged[i] = v
+			continue
+		}
+		index[v.Name] = len(merged)
+		merged = append(merged, v)
+	}
+
+	return merged
+}
+
+// MergeComputed merges inherited computed variables with a template's own,
+// the way MergeVariables merges declarations
+func MergeComputed(base, own []Computed) []Computed {
+	merged := make([]Computed, 0, len(base)+len(own))
+	index := make(map[string]int, len(base))
+	for _, c := range base {
+		index[c.Name] = len(merged)
+		merged = append(merged, c)
+	}
+
+	for _, c := range own {
+		if i, ok := index[c.Name]; ok {
+			merged[i] = c
+			continue
+		}
+		index[c.Name] = len(merged)
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+// IsKeptEmpty reports whether a template directory is meant to be generated
+// empty: it has no entries, or only keep markers
+func IsKeptEmpty(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(KeepMarkers, entry.Name()) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsHidden reports whether a file or directory name is hidden
+func IsHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// AlwaysSkipped reports whether a name is never taken from a template
+// (such as .git), so skipping it needs no warning
+func AlwaysSkipped(name string) bool {
+	for _, skipped := range alwaysSkipped {
+		if name == skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepHidden reports whether a hidden file or directory name should be kept.
+// It is safe to call on a nil Config, which applies the defaults.
+func (c *Config) KeepHidden(name string) bool {
+	if AlwaysSkipped(name) {
+		return false
+	}
+
+	if c != nil && c.IncludeHidden {
+		return true
+	}
+
+	if glob.MatchAny(DefaultHiddenAllow, name) {
+		return true
+	}
+
+	return c != nil && glob.MatchAny(c.HiddenAllow, name)
+}
+
+// WithIncludeHidden returns a copy of the config that keeps all hidden files
+// and directories; a nil config yields an otherwise empty one
+func (c *Config) WithIncludeHidden() *Config {
+	var withHidden Config
+	if c != nil {
+		withHidden = *c
+	}
+	withHidden.IncludeHidden = true
+	return &withHidden
+}
+
+// IgnorePatterns returns the patterns of paths left out of a template: the
+// config's ignore list followed by the lines of the template's .asonignore.
+// Blank lines and lines starting with # are skipped. It is safe to call on a
+// nil Config.
+func (c *Config) IgnorePatterns(dir string) ([]string, error) {
+	var patterns []string
+	if c != nil {
+		patterns = append(patterns, c.Ignore...)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return patterns, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
 }
 
 // LoadConfig loads template configuration from a file
@@ -36,16 +336,108 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	var config Config
+	if err := DecodeConfig(path, data, &config); err != nil {
+		return nil, err
+	}
 
-	// Try TOML first
-	if err := toml.Unmarshal(data, &config); err == nil {
-		return &config, nil
+	for i := range config.Variables {
+		v := &config.Variables[i]
+		if len(v.Choices) == 0 {
+			v.Choices = v.Options
+		}
+		v.Options = nil
 	}
 
-	// Try JSON as fallback
-	if err := json.Unmarshal(data, &config); err == nil {
-		return &config, nil
+	return &config, nil
+}
+
+// Load finds and loads the config file of a template directory. It returns
+// ErrNoConfig if the template has no config file.
+func Load(dir string) (*Config, error) {
+	path, err := FindConfig(dir)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("failed to parse config file")
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	return config, nil
+}
+
+// FindConfig returns the path of the config file in a template directory.
+// It returns ErrNoConfig if none exists and an error if more than one of
+// ConfigFileNames is present, since it would be ambiguous which one wins.
+func FindConfig(dir string) (string, error) {
+	var found []string
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, path)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", ErrNoConfig
+	case 1:
+		return found[0], nil
+	default:
+		var names []string
+		for _, path := range found {
+			names = append(names, filepath.Base(path))
+		}
+		return "", fmt.Errorf("multiple template config files found: %s", strings.Join(names, ", "))
+	}
+}
+
+// DecodeConfig parses config data into v based on the file extension of path.
+// Files without a recognised extension are tried as TOML and then JSON.
+func DecodeConfig(path string, data []byte, v interface{}) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		if err := toml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to parse TOML config: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to parse YAML config: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to parse JSON config: %w", err)
+		}
+	default:
+		// Try TOML first, JSON as fallback
+		if err := toml.Unmarshal(data, v); err == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, v); err == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to parse config file")
+	}
+
+	return nil
+}
+
+// DotPrefix returns the prefix marking paths that are generated as
+// dotfiles, or "" when there is none. It is safe to call on a nil Config.
+func (c *Config) DotPrefix() string {
+	if c != nil && c.DotfilePrefix != nil {
+		return *c.DotfilePrefix
+	}
+	return DefaultDotfilePrefix
+}
+
+// PartialsDirs returns the template-relative directories of include and
+// extends partials, which are left out of the output. It is safe to call on
+// a nil Config.
+func (c *Config) PartialsDirs() []string {
+	if c != nil && c.PartialsDir != "" {
+		return []string{filepath.Clean(c.PartialsDir)}
+	}
+	return DefaultPartialsDirs
 }
@@ -1,8 +1,10 @@
 package template
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -293,3 +295,219 @@ func TestLoadConfig_EmptyFile(t *testing.T) {
 		t.Errorf("Expected empty name for empty config, got %v", config.Name)
 	}
 }
+
+func TestLoadConfig_YAML(t *testing.T) {
+	// Create temporary directory
+	tmpDir, err := os.MkdirTemp("", "ason_config_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	yamlContent := `name: yaml-template
+description: A YAML test template
+engine: pongo2
+variables:
+  - name: app_name
+    prompt: "Enter app name:"
+    choices: [web, api]
+`
+
+	yamlPath := filepath.Join(tmpDir, "ason.yaml")
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write YAML file: %v", err)
+	}
+
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if config.Name != "yaml-template" {
+		t.Errorf("Config.Name = %v, want %v", config.Name, "yaml-template")
+	}
+
+	if len(config.Variables) != 1 || config.Variables[0].Prompt != "Enter app name:" {
+		t.Errorf("Config.Variables = %+v, want one variable with prompt", config.Variables)
+	}
+
+	if len(config.Variables[0].Choices) != 2 {
+		t.Errorf("Variables[0].Choices length = %v, want %v", len(config.Variables[0].Choices), 2)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "toml", files: []string{"ason.toml"}, want: "ason.toml"},
+		{name: "yaml", files: []string{"ason.yaml"}, want: "ason.yaml"},
+		{name: "yml", files: []string{"ason.yml"}, want: "ason.yml"},
+		{name: "json", files: []string{"ason.json"}, want: "ason.json"},
+		{name: "none", files: nil, wantErr: true},
+		{name: "conflicting", files: []string{"ason.toml", "ason.json"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(tmpDir, f), []byte(""), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", f, err)
+				}
+			}
+
+			got, err := FindConfig(tmpDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if filepath.Base(got) != tt.want {
+				t.Errorf("FindConfig() = %v, want %v", filepath.Base(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := FindConfig(t.TempDir()); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("FindConfig() on empty dir error = %v, want ErrNoConfig", err)
+	}
+}
+
+func TestLoadConfig_Rendering(t *testing.T) {
+	tomlPath := filepath.Join(t.TempDir(), "ason.toml")
+	content := `name = "trimmed"
+
+[rendering]
+trim_blocks = true
+lstrip_blocks = true
+autoescape = true
+`
+	if err := os.WriteFile(tomlPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write TOML file: %v", err)
+	}
+
+	config, err := LoadConfig(tomlPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !config.Rendering.TrimBlocks || !config.Rendering.LStripBlocks || !config.Rendering.Autoescape {
+		t.Errorf("Config.Rendering = %+v, want all options enabled", config.Rendering)
+	}
+}
+
+func TestConfig_KeepHidden(t *testing.T) {
+	var nilConfig *Config
+
+	tests := []struct {
+		name   string
+		config *Config
+		file   string
+		want   bool
+	}{
+		{"default allows .github", nilConfig, ".github", true},
+		{"default allows .golangci.yml", nilConfig, ".golangci.yml", true},
+		{"default skips .env", nilConfig, ".env", false},
+		{"never keeps .git", &Config{IncludeHidden: true}, ".git", false},
+		{"include_hidden keeps .env", &Config{IncludeHidden: true}, ".env", true},
+		{"hidden_allow keeps .env", &Config{HiddenAllow: []string{".env*"}}, ".env", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.KeepHidden(tt.file); got != tt.want {
+				t.Errorf("KeepHidden(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsKeptEmpty(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"marked/.gitkeep":   "",
+		"both/.keep":        "",
+		"both/.gitkeep":     "",
+		"content/README.md": "# readme",
+		"nested/sub/.keep":  "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatalf("Failed to create empty: %v", err)
+	}
+
+	tests := map[string]bool{
+		"empty":   true,
+		"marked":  true,
+		"both":    true,
+		"content": false,
+		"nested":  false,
+		"missing": false,
+	}
+	for dir, want := range tests {
+		if got := IsKeptEmpty(filepath.Join(root, dir)); got != want {
+			t.Errorf("IsKeptEmpty(%s) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestMergeVariables(t *testing.T) {
+	base := []Variable{
+		{Name: "name", Prompt: "Project name"},
+		{Name: "license", Default: "MIT"},
+	}
+	own := []Variable{
+		{Name: "license", Default: "Apache-2.0"},
+		{Name: "port", Default: 8080},
+	}
+
+	got := MergeVariables(base, own)
+
+	want := []Variable{
+		{Name: "name", Prompt: "Project name"},
+		{Name: "license", Default: "Apache-2.0"},
+		{Name: "port", Default: 8080},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeVariables() = %+v, want %+v", got, want)
+	}
+
+	if got := MergeVariables(nil, nil); len(got) != 0 {
+		t.Errorf("MergeVariables(nil, nil) = %+v, want empty", got)
+	}
+}
+
+func TestMergeComputed(t *testing.T) {
+	base := []Computed{
+		{Name: "slug", Value: "{{ name|lower }}"},
+		{Name: "year", Value: "2025"},
+	}
+	own := []Computed{
+		{Name: "slug", Value: "{{ name|slugify }}"},
+		{Name: "module_path", Value: "github.com/{{ org }}/{{ slug }}"},
+	}
+
+	got := MergeComputed(base, own)
+
+	want := []Computed{
+		{Name: "slug", Value: "{{ name|slugify }}"},
+		{Name: "year", Value: "2025"},
+		{Name: "module_path", Value: "github.com/{{ org }}/{{ slug }}"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeComputed() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/version"
+)
+
+// Validation issue categories
+const (
+	CategoryStructure = "structure"
+	CategoryConfig    = "config"
+	CategoryVariables = "variables"
+)
+
+// Severity tells whether a validation issue makes a template unusable
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single validation finding
+type Issue struct {
+	Category string   `json:"category"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Category, i.Message)
+}
+
+// Report holds the results of validating a template directory
+type Report struct {
+	Path      string  `json:"path"`
+	Files     int     `json:"files"`
+	Config    string  `json:"config,omitempty"`
+	Variables int     `json:"variables"`
+	Issues    []Issue `json:"issues,omitempty"`
+}
+
+func (r *Report) add(category string, severity Severity, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Category: category, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// Errors returns the issues that make the template unusable
+func (r *Report) Errors() []Issue {
+	return r.filter(SeverityError)
+}
+
+// Warnings returns the issues that don't stop the template from being used
+func (r *Report) Warnings() []Issue {
+	return r.filter(SeverityWarning)
+}
+
+func (r *Report) filter(severity Severity) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// Failed reports whether the template failed validation. In strict mode
+// warnings count as failures too.
+func (r *Report) Failed(strict bool) bool {
+	if strict {
+		return len(r.Issues) > 0
+	}
+	return len(r.Errors()) > 0
+}
+
+// Validate checks a template directory: that it holds files, that its config
+// parses and names a known engine, and that its variable declarations are
+// consistent. Problems are collected in the report rather than returned; the
+// error is only for a directory that can't be read.
+func Validate(dir string) (*Report, error) {
+	report := &Report{Path: dir}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access template: %w", err)
+	}
+	if !info.IsDir() {
+		report.add(CategoryStructure, SeverityError, "template path must be a directory")
+		return report, nil
+	}
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && AlwaysSkipped(info.Name()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			report.Files++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze template: %w", err)
+	}
+
+	if report.Files == 0 {
+		report.add(CategoryStructure, SeverityError, "template contains no files")
+	}
+
+	path, err := FindConfig(dir)
+	if errors.Is(err, ErrNoConfig) {
+		report.add(CategoryConfig, SeverityWarning, "no config file found (ason.toml, ason.yaml, ason.yml or ason.json)")
+		return report, nil
+	}
+	if err != nil {
+		report.add(CategoryConfig, SeverityError, "%v", err)
+		return report, nil
+	}
+	report.Config = filepath.Base(path)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		report.add(CategoryConfig, SeverityError, "%s: %v", report.Config, err)
+		return report, nil
+	}
+
+	if config.Engine != "" {
+		if _, err := engine.New(config.Engine, engine.Options{}); err != nil {
+			report.add(CategoryConfig, SeverityError, "%v", err)
+		}
+	}
+	for _, suffix := range sortedKeys(config.Engines) {
+		if _, err := engine.New(config.Engines[suffix], engine.Options{}); err != nil {
+			report.add(CategoryConfig, SeverityError, "engines: %s: %v", suffix, err)
+		}
+	}
+	if config.Description == "" {
+		report.add(CategoryConfig, SeverityWarning, "no description")
+	}
+	if config.MinAsonVersion != "" {
+		if _, err := version.Parse(config.MinAsonVersion); err != nil {
+			report.add(CategoryConfig, SeverityError, "min_ason_version: %v", err)
+		}
+	}
+
+	report.Variables = len(config.Variables)
+	validateVariables(report, config.Variables)
+	validateComputed(report, config.Computed, config.Variables)
+
+	return report, nil
+}
+
+// knownTypes are the variable types CoerceValue understands
+var knownTypes = []string{"", "string", "password", "integer", "int", "number", "float", "boolean", "bool", "list"}
+
+func validateVariables(report *Report, variables []Variable) {
+	seen := make(map[string]bool)
+	for i, v := range variables {
+		if v.Name == "" {
+			report.add(CategoryVariables, SeverityError, "variable %d has no name", i+1)
+			continue
+		}
+		if seen[v.Name] {
+			report.add(CategoryVariables, SeverityError, "variable %s is declared more than once", v.Name)
+		}
+		seen[v.Name] = true
+
+		if !containsString(knownTypes, strings.ToLower(v.Type)) {
+			report.add(CategoryVariables, SeverityWarning, "variable %s has unknown type %q and is treated as a string", v.Name, v.Type)
+		}
+
+		if v.Validation != "" {
+			if _, err := regexp.Compile(v.Validation); err != nil {
+				report.add(CategoryVariables, SeverityError, "variable %s has an invalid validation pattern: %v", v.Name, err)
+			}
+		}
+
+		if v.Default == nil {
+			continue
+		}
+		if s, ok := v.Default.(string); ok {
+			if _, err := CoerceValue(v, s); err != nil {
+				report.add(CategoryVariables, SeverityError, "default %v", err)
+			}
+		}
+		if len(v.Choices) > 0 && !containsString(v.Choices, fmt.Sprintf("%v", v.Default)) {
+			report.add(CategoryVariables, SeverityWarning, "variable %s default %q is not one of its choices", v.Name, fmt.Sprintf("%v", v.Default))
+		}
+	}
+}
+
+func validateComputed(report *Report, computed []Computed, variables []Variable) {
+	declared := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		declared[v.Name] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range computed {
+		if c.Name == "" {
+			report.add(CategoryVariables, SeverityError, "computed variable %d has no name", i+1)
+			continue
+		}
+		if seen[c.Name] {
+			report.add(CategoryVariables, SeverityError, "computed variable %s is declared more than once", c.Name)
+		}
+		seen[c.Name] = true
+
+		if declared[c.Name] {
+			report.add(CategoryVariables, SeverityError, "computed variable %s is also declared as a variable", c.Name)
+		}
+		if c.Value == "" {
+			report.add(CategoryVariables, SeverityWarning, "computed variable %s has no value", c.Name)
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
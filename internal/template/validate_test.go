@@ -0,0 +1,186 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				"README.md": "# {{ name }}",
+				"ason.toml": "description = \"Demo\"\n[[variables]]\nname = \"name\"\n",
+			},
+		},
+		{
+			name:         "empty",
+			files:        map[string]string{},
+			wantErrors:   []string{"structure: template contains no files"},
+			wantWarnings: []string{"config: no config file found"},
+		},
+		{
+			name:         "no config",
+			files:        map[string]string{"README.md": "hello"},
+			wantWarnings: []string{"config: no config file found"},
+		},
+		{
+			name: "broken config",
+			files: map[string]string{
+				"ason.toml": "name = ",
+			},
+			wantErrors: []string{"config: ason.toml: failed to parse TOML config"},
+		},
+		{
+			name: "unknown engine",
+			files: map[string]string{
+				"ason.toml": "description = \"Demo\"\nengine = \"jinja\"\n",
+			},
+			wantErrors: []string{`config: unknown engine "jinja"`},
+		},
+		{
+			name: "unknown suffix engine",
+			files: map[string]string{
+				"ason.toml": "description = \"Demo\"\n[engines]\n\".gotmpl\" = \"go\"\n\".j2\" = \"jinja\"\n",
+			},
+			wantErrors: []string{`config: engines: .j2: unknown engine "jinja"`},
+		},
+		{
+			name: "bad min version",
+			files: map[string]string{
+				"ason.toml": "description = \"Demo\"\nmin_ason_version = \"latest\"\n",
+			},
+			wantErrors: []string{`config: min_ason_version: invalid version "latest"`},
+		},
+		{
+			name: "bad variables",
+			files: map[string]string{
+				"ason.toml": `description = "Demo"
+
+[[variables]]
+name = "port"
+type = "integer"
+default = "eighty"
+
+[[variables]]
+name = "port"
+
+[[variables]]
+name = "slug"
+validation = "["
+
+[[variables]]
+name = "region"
+type = "region"
+default = "mars"
+choices = ["us", "eu"]
+`,
+			},
+			wantErrors: []string{
+				`variables: default variable port: "eighty" is not an integer`,
+				"variables: variable port is declared more than once",
+				"variables: variable slug has an invalid validation pattern",
+			},
+			wantWarnings: []string{
+				`variables: variable region has unknown type "region"`,
+				`variables: variable region default "mars" is not one of its choices`,
+			},
+		},
+		{
+			name: "bad computed variables",
+			files: map[string]string{
+				"ason.toml": `description = "Demo"
+
+[[variables]]
+name = "name"
+
+[[computed]]
+value = "{{ name }}"
+
+[[computed]]
+name = "slug"
+value = "{{ name|lower }}"
+
+[[computed]]
+name = "slug"
+value = "{{ name|upper }}"
+
+[[computed]]
+name = "name"
+value = "{{ name|title }}"
+
+[[computed]]
+name = "empty"
+`,
+			},
+			wantErrors: []string{
+				"variables: computed variable 1 has no name",
+				"variables: computed variable slug is declared more than once",
+				"variables: computed variable name is also declared as a variable",
+			},
+			wantWarnings: []string{
+				"variables: computed variable empty has no value",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Validate(writeTemplate(t, tt.files))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			assertIssues(t, "errors", report.Errors(), tt.wantErrors)
+			assertIssues(t, "warnings", report.Warnings(), tt.wantWarnings)
+
+			if report.Failed(false) != (len(tt.wantErrors) > 0) {
+				t.Errorf("Failed(false) = %v", report.Failed(false))
+			}
+			if report.Failed(true) != (len(tt.wantErrors)+len(tt.wantWarnings) > 0) {
+				t.Errorf("Failed(true) = %v", report.Failed(true))
+			}
+		})
+	}
+}
+
+func assertIssues(t *testing.T, kind string, got []Issue, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s = %v, want %d", kind, got, len(want))
+	}
+	for i, issue := range got {
+		if !strings.HasPrefix(issue.String(), want[i]) {
+			t.Errorf("%s[%d] = %q, want prefix %q", kind, i, issue.String(), want[i])
+		}
+	}
+}
+
+func TestValidate_MissingDirectory(t *testing.T) {
+	if _, err := Validate(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Validate() should fail for a missing directory")
+	}
+}
@@ -0,0 +1,232 @@
+package userconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/madstone-tech/ason/internal/engine"
+	"github.com/madstone-tech/ason/internal/xdg"
+)
+
+// FileName is the name of the user config file in the ason config directory
+const FileName = "config.toml"
+
+// DefaultCacheTTL is how long remote templates are cached when cache_ttl
+// isn't set
+const DefaultCacheTTL = 24 * time.Hour
+
+// ColorModes are the accepted values of the color setting
+var ColorModes = []string{"auto", "always", "never"}
+
+// Config holds the user's settings
+type Config struct {
+	// Author is the default for template variables named author
+	Author string `toml:"author,omitempty"`
+
+	// DefaultEngine renders templates that don't name an engine
+	DefaultEngine string `toml:"default_engine,omitempty"`
+
+	// Color is one of ColorModes
+	Color string `toml:"color,omitempty"`
+
+	// Quiet makes `ason new` behave as if --quiet were given
+	Quiet bool `toml:"quiet,omitempty"`
+
+	// BackupDir is where `ason remove --backup` puts backups
+	BackupDir string `toml:"backup_dir,omitempty"`
+
+	// CacheTTL is how long a cached remote template is used before it is
+	// fetched again, as a duration; empty means DefaultCacheTTL
+	CacheTTL string `toml:"cache_ttl,omitempty"`
+
+	// Defaults holds variable values saved with `ason new --save-defaults`,
+	// by template
+	Defaults map[string]map[string]string `toml:"defaults,omitempty"`
+}
+
+// Key describes a setting that `ason config` can get and set
+type Key struct {
+	Name        string
+	Description string
+
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// Keys lists the known settings, in the order `ason config list` shows them
+var Keys = []Key{
+	{
+		Name:        "author",
+		Description: "Default for template variables named author",
+		get:         func(c *Config) string { return c.Author },
+		set:         func(c *Config, value string) error { c.Author = value; return nil },
+	},
+	{
+		Name:        "default_engine",
+		Description: "Engine for templates that don't name one (" + strings.Join(engine.Names, ", ") + ")",
+		get:         func(c *Config) string { return c.DefaultEngine },
+		set: func(c *Config, value string) error {
+			if value != "" && !slices.Contains(engine.Names, value) {
+				return fmt.Errorf("unknown engine %q (supported: %s)", value, strings.Join(engine.Names, ", "))
+			}
+			c.DefaultEngine = value
+			return nil
+		},
+	},
+	{
+		Name:        "color",
+		Description: "Color output (" + strings.Join(ColorModes, ", ") + ")",
+		get:         func(c *Config) string { return c.Color },
+		set: func(c *Config, value string) error {
+			if value != "" && !slices.Contains(ColorModes, value) {
+				return fmt.Errorf("invalid color %q (supported: %s)", value, strings.Join(ColorModes, ", "))
+			}
+			c.Color = value
+			return nil
+		},
+	},
+	{
+		Name:        "quiet",
+		Description: "Suppress progress output and the summary of ason new (true, false)",
+		get:         func(c *Config) string { return strconv.FormatBool(c.Quiet) },
+		set: func(c *Config, value string) error {
+			quiet, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid quiet %q (use true or false)", value)
+			}
+			c.Quiet = quiet
+			return nil
+		},
+	},
+	{
+		Name:        "backup_dir",
+		Description: "Directory for ason remove --backup",
+		get:         func(c *Config) string { return c.BackupDir },
+		set:         func(c *Config, value string) error { c.BackupDir = value; return nil },
+	},
+	{
+		Name:        "cache_ttl",
+		Description: "How long remote templates are cached, e.g. 12h (0 disables the cache)",
+		get:         func(c *Config) string { return c.CacheTTL },
+		set: func(c *Config, value string) error {
+			if value != "" {
+				if ttl, err := time.ParseDuration(value); err != nil || ttl < 0 {
+					return fmt.Errorf("invalid cache_ttl %q (use a duration such as 12h or 30m)", value)
+				}
+			}
+			c.CacheTTL = value
+			return nil
+		},
+	},
+}
+
+// LookupKey returns the known setting with the given name
+func LookupKey(name string) (Key, error) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+
+	names := make([]string, len(Keys))
+	for i, key := range Keys {
+		names[i] = key.Name
+	}
+	return Key{}, fmt.Errorf("unknown config key %q (known: %s)", name, strings.Join(names, ", "))
+}
+
+// Path returns the location of the user config file
+func Path() (string, error) {
+	dir, err := xdg.ConfigHome()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the user config at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	var config Config
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &config, nil
+		}
+		return nil, fmt.Errorf("failed to read user config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Save writes the config to path, creating its directory if needed
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := toml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write user config: %w", err)
+	}
+	return nil
+}
+
+// Get returns the value of a setting as text
+func (c *Config) Get(name string) (string, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return "", err
+	}
+	return key.get(c), nil
+}
+
+// Set validates and changes a setting; an empty value clears string
+// settings
+func (c *Config) Set(name, value string) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	return key.set(c, value)
+}
+
+// RemoteCacheTTL returns how long remote templates are cached. Values that
+// don't parse, which Set refuses, fall back to DefaultCacheTTL.
+func (c *Config) RemoteCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.CacheTTL)
+	if err != nil || ttl < 0 {
+		return DefaultCacheTTL
+	}
+	return ttl
+}
+
+// TemplateDefaults returns the variable values saved for a template
+func (c *Config) TemplateDefaults(template string) map[string]string {
+	return c.Defaults[template]
+}
+
+// SaveTemplateDefaults records variable values for a template, replacing
+// those saved before for the same variables
+func (c *Config) SaveTemplateDefaults(template string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	if c.Defaults == nil {
+		c.Defaults = make(map[string]map[string]string)
+	}
+	if c.Defaults[template] == nil {
+		c.Defaults[template] = make(map[string]string, len(values))
+	}
+	for name, value := range values {
+		c.Defaults[template][name] = value
+	}
+}
@@ -0,0 +1,108 @@
+package userconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_SetGetSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ason", FileName)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file failed: %v", err)
+	}
+	if !reflect.DeepEqual(*config, Config{}) {
+		t.Errorf("Load() of a missing file = %+v, want an empty config", config)
+	}
+
+	for key, value := range map[string]string{
+		"author":         "Ada Lovelace",
+		"default_engine": "go",
+		"color":          "never",
+		"quiet":          "true",
+		"backup_dir":     "/tmp/ason-backups",
+		"cache_ttl":      "12h",
+	} {
+		if err := config.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key, err)
+		}
+	}
+	if err := config.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := Config{Author: "Ada Lovelace", DefaultEngine: "go", Color: "never", Quiet: true, BackupDir: "/tmp/ason-backups", CacheTTL: "12h"}
+	if !reflect.DeepEqual(*loaded, want) {
+		t.Errorf("Load() = %+v, want %+v", *loaded, want)
+	}
+	if got, err := loaded.Get("quiet"); err != nil || got != "true" {
+		t.Errorf("Get(quiet) = %q, %v, want true", got, err)
+	}
+}
+
+func TestConfig_SetInvalid(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"editor", "vim", `unknown config key "editor"`},
+		{"default_engine", "jinja", `unknown engine "jinja"`},
+		{"color", "sometimes", `invalid color "sometimes"`},
+		{"quiet", "maybe", `invalid quiet "maybe"`},
+		{"cache_ttl", "tomorrow", `invalid cache_ttl "tomorrow"`},
+		{"cache_ttl", "-1h", `invalid cache_ttl "-1h"`},
+	}
+
+	for _, tt := range tests {
+		var config Config
+		err := config.Set(tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Set(%s, %s) error = %v, want %q", tt.key, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestConfig_TemplateDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	var config Config
+	config.SaveTemplateDefaults("go-service", map[string]string{"org": "acme", "region": "eu-west-1"})
+	config.SaveTemplateDefaults("go-service", map[string]string{"region": "us-east-1"})
+	if err := config.Save(path); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := map[string]string{"org": "acme", "region": "us-east-1"}
+	if got := loaded.TemplateDefaults("go-service"); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateDefaults() = %v, want %v", got, want)
+	}
+	if got := loaded.TemplateDefaults("other"); len(got) != 0 {
+		t.Errorf("TemplateDefaults() of another template = %v, want none", got)
+	}
+}
+
+func TestConfig_RemoteCacheTTL(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":      DefaultCacheTTL,
+		"30m":   30 * time.Minute,
+		"0":     0,
+		"bogus": DefaultCacheTTL,
+	}
+	for value, want := range tests {
+		config := Config{CacheTTL: value}
+		if got := config.RemoteCacheTTL(); got != want {
+			t.Errorf("RemoteCacheTTL() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package varfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// loadHCL parses a Terraform-style .tfvars or .hcl file of top-level
+// `name = value` assignments. Values may be strings, heredocs, numbers,
+// booleans, null, lists and objects; lists of scalars become comma-separated
+// strings and objects are written as `key=value` pairs. Variables, function
+// calls and blocks are refused, since variable files only hold literal
+// values.
+func loadHCL(content []byte) (map[string]string, error) {
+	file, diags := hclsyntax.ParseConfig(content, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, hclError(diags)
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, hclError(diags)
+	}
+
+	variables := make(map[string]string, len(attrs))
+	for name, attr := range attrs {
+		// Without an evaluation context, any reference or call is an error
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, hclError(diags)
+		}
+		variables[name] = formatHCLValue(value)
+	}
+	return variables, nil
+}
+
+// hclError reports the first error in diags with its line, the way the
+// other formats report parse errors
+func hclError(diags hcl.Diagnostics) error {
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		msg := diag.Summary
+		if diag.Detail != "" {
+			msg += ": " + strings.TrimSuffix(diag.Detail, ".")
+		}
+		if diag.Subject != nil {
+			return fmt.Errorf("line %d: %s", diag.Subject.Start.Line, msg)
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return diags
+}
+
+// formatHCLValue converts a decoded value to a variable string
+func formatHCLValue(value cty.Value) string {
+	if value.IsNull() {
+		return ""
+	}
+
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return value.AsString()
+	case ty == cty.Number:
+		return value.AsBigFloat().Text('f', -1)
+	case ty == cty.Bool:
+		return fmt.Sprintf("%v", value.True())
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		var items []string
+		for it := value.ElementIterator(); it.Next(); {
+			_, item := it.Element()
+			items = append(items, formatHCLValue(item))
+		}
+		return strings.Join(items, ",")
+	case ty.IsMapType() || ty.IsObjectType():
+		values := make(map[string]string)
+		for it := value.ElementIterator(); it.Next(); {
+			key, item := it.Element()
+			values[key.AsString()] = formatHCLValue(item)
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + values[key]
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return value.GoString()
+	}
+}
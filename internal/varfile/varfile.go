@@ -11,8 +11,33 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// AutoFileNames are the variable files Find discovers, in lookup order
+var AutoFileNames = []string{"ason.vars.toml", "ason.vars.yaml", "ason.vars.yml", "ason.vars.json"}
+
+// Find returns the first of AutoFileNames found in dirs, searched in order,
+// or "" when there is none
+func Find(dirs ...string) (string, error) {
+	for _, dir := range dirs {
+		for _, name := range AutoFileNames {
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to check variable file: %w", err)
+			}
+			if !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+	return "", nil
+}
+
 // Load reads variables from a file and returns them as a map.
-// Supports TOML, YAML, and JSON formats based on file extension.
+// Supports TOML, YAML, JSON, HCL (.tfvars, .hcl) and dotenv formats based on
+// file extension; dotenv files are also recognised by names such as .env or .env.production.
 // For TOML files, it supports both simple key-value format and the template format with [variables] section.
 func Load(filePath string) (map[string]string, error) {
 	// Check if file exists
@@ -28,6 +53,9 @@ func Load(filePath string) (map[string]string, error) {
 
 	// Determine format by extension
 	ext := strings.ToLower(filepath.Ext(filePath))
+	if isEnvFile(filePath) {
+		ext = ".env"
+	}
 
 	var variables map[string]string
 	switch ext {
@@ -37,8 +65,12 @@ func Load(filePath string) (map[string]string, error) {
 		variables, err = loadYAML(content)
 	case ".json":
 		variables, err = loadJSON(content)
+	case ".tfvars", ".hcl":
+		variables, err = loadHCL(content)
+	case ".env":
+		variables, err = loadEnv(content)
 	default:
-		return nil, fmt.Errorf("unsupported file format: %s (supported: .toml, .yaml, .yml, .json)", ext)
+		return nil, fmt.Errorf("unsupported file format: %s (supported: .toml, .yaml, .yml, .json, .tfvars, .hcl, .env)", ext)
 	}
 
 	if err != nil {
@@ -67,7 +99,7 @@ func loadTOML(content []byte) (map[string]string, error) {
 			case map[string]interface{}:
 				// Variable definition with default value
 				if defaultVal, ok := v["default"]; ok {
-					variables[key] = fmt.Sprintf("%v", defaultVal)
+					variables[key] = formatValue(defaultVal)
 				}
 			}
 		}
@@ -89,7 +121,7 @@ func loadTOML(content []byte) (map[string]string, error) {
 		if key == "template" || key == "variables" {
 			continue
 		}
-		variables[key] = fmt.Sprintf("%v", value)
+		variables[key] = formatValue(value)
 	}
 
 	return variables, nil
@@ -127,6 +159,71 @@ func loadJSON(content []byte) (map[string]string, error) {
 	return convertToStringMap(data), nil
 }
 
+// isEnvFile reports whether a file name is a dotenv file: `.env`,
+// `.env.<stage>` or `<name>.env`
+func isEnvFile(filePath string) bool {
+	base := strings.ToLower(filepath.Base(filePath))
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// loadEnv parses a dotenv file of KEY=value lines. Blank lines and lines
+// starting with # are ignored, an `export ` prefix is allowed, and values
+// may be single-quoted (literal) or double-quoted (with \n, \" and \\
+// escapes). Unquoted values end at an inline ` #` comment.
+func loadEnv(content []byte) (map[string]string, error) {
+	variables := make(map[string]string)
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		variables[key] = value
+	}
+
+	return variables, nil
+}
+
+// parseEnvValue unquotes a dotenv value
+func parseEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'', '"':
+		end := strings.LastIndexByte(value, quote)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated %c quote", quote)
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text after quoted value: %s", rest)
+		}
+		inner := value[1:end]
+		if quote == '\'' {
+			return inner, nil
+		}
+		return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(inner), nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
 // convertToStringMap converts a map[string]interface{} to map[string]string.
 func convertToStringMap(data map[string]interface{}) map[string]string {
 	result := make(map[string]string)
@@ -134,15 +231,28 @@ func convertToStringMap(data map[string]interface{}) map[string]string {
 		// Handle nested maps (variable definitions with default values)
 		if m, ok := value.(map[string]interface{}); ok {
 			if defaultVal, exists := m["default"]; exists {
-				result[key] = fmt.Sprintf("%v", defaultVal)
+				result[key] = formatValue(defaultVal)
 				continue
 			}
 		}
-		result[key] = fmt.Sprintf("%v", value)
+		result[key] = formatValue(value)
 	}
 	return result
 }
 
+// formatValue converts a decoded value to a variable string. Lists are
+// joined with commas, the way list variables are given with --var.
+func formatValue(value interface{}) string {
+	if items, ok := value.([]interface{}); ok {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = formatValue(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 // Merge combines variables from a file with command-line variables.
 // Command-line variables take precedence over file variables.
 func Merge(fileVars, cliVars map[string]string) map[string]string {
@@ -175,6 +175,36 @@ func TestLoad_JSON(t *testing.T) {
 	}
 }
 
+func TestLoad_Lists(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"vars.toml":     "regions = [\"us\", \"eu\"]\n",
+		"defaults.toml": "[variables.regions]\ndefault = [\"us\", \"eu\"]\n",
+		"vars.yaml":     "regions:\n  - us\n  - eu\n",
+		"defaults.yaml": "regions:\n  default: [us, eu]\n",
+		"vars.json":     `{"regions": ["us", "eu"]}`,
+		"defaults.json": `{"regions": {"default": ["us", "eu"]}}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(tempDir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			vars, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if vars["regions"] != "us,eu" {
+				t.Errorf("regions = %q, want %q", vars["regions"], "us,eu")
+			}
+		})
+	}
+}
+
 func TestLoad_FileNotFound(t *testing.T) {
 	_, err := Load("/nonexistent/file.toml")
 	if err == nil {
@@ -294,3 +324,174 @@ variables:
 		}
 	}
 }
+
+func TestLoadEnv(t *testing.T) {
+	content := `# Deployment settings
+export ENVIRONMENT=prod
+
+AWS_REGION = us-west-2 # inline comment
+GREETING="Hello, \"World\"\nBye"
+PATTERN='literal \n # not a comment'
+EMPTY=
+URL=https://example.com/#anchor
+`
+
+	vars, err := loadEnv([]byte(content))
+	if err != nil {
+		t.Fatalf("loadEnv() failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"ENVIRONMENT": "prod",
+		"AWS_REGION":  "us-west-2",
+		"GREETING":    "Hello, \"World\"\nBye",
+		"PATTERN":     `literal \n # not a comment`,
+		"EMPTY":       "",
+		"URL":         "https://example.com/#anchor",
+	}
+
+	if len(vars) != len(expected) {
+		t.Errorf("Expected %d variables, got %d: %v", len(expected), len(vars), vars)
+	}
+	for key, want := range expected {
+		if got, ok := vars[key]; !ok || got != want {
+			t.Errorf("Variable %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadEnv_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing equals":    "JUST_A_KEY",
+		"missing key":       "=value",
+		"unterminated":      `NAME="open`,
+		"text after quotes": `NAME="a" b`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadEnv([]byte(content)); err == nil {
+				t.Errorf("loadEnv(%q) should fail", content)
+			}
+		})
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, name := range []string{".env", ".env.production", "deploy.env"} {
+		t.Run(name, func(t *testing.T) {
+			envFile := filepath.Join(tempDir, name)
+			if err := os.WriteFile(envFile, []byte("environment=prod\n"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			vars, err := Load(envFile)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if vars["environment"] != "prod" {
+				t.Errorf("environment = %q, want %q", vars["environment"], "prod")
+			}
+		})
+	}
+}
+
+func TestLoad_TFVars(t *testing.T) {
+	tempDir := t.TempDir()
+	tfvarsFile := filepath.Join(tempDir, "prod.tfvars")
+
+	content := `# Lambda WAF settings
+function_name = "lambda-waf-ipset"
+aws_region    = "us-west-2"
+memory_size   = 512
+timeout       = 30.5
+enabled       = true // trailing comment
+
+/* Lists and objects */
+allowed_ips = [
+  "10.0.0.0/8",
+  "192.168.0.0/16",
+]
+tags = { team = "platform", "cost-center" = 42 }
+
+description = <<-EOT
+    First line
+    Second line
+    EOT
+`
+	if err := os.WriteFile(tfvarsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	vars, err := Load(tfvarsFile)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"function_name": "lambda-waf-ipset",
+		"aws_region":    "us-west-2",
+		"memory_size":   "512",
+		"timeout":       "30.5",
+		"enabled":       "true",
+		"allowed_ips":   "10.0.0.0/8,192.168.0.0/16",
+		"tags":          "cost-center=42,team=platform",
+		"description":   "First line\nSecond line\n",
+	}
+
+	if len(vars) != len(expected) {
+		t.Errorf("Expected %d variables, got %d: %v", len(expected), len(vars), vars)
+	}
+	for key, want := range expected {
+		if got, ok := vars[key]; !ok || got != want {
+			t.Errorf("Variable %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadHCL_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing equals":   `region "us-west-2"`,
+		"unterminated":     `region = "us-west-2`,
+		"expression":       `region = var.default_region`,
+		"unclosed list":    `ips = ["10.0.0.0/8"`,
+		"unclosed heredoc": "text = <<EOT\nhello\n",
+		"block":            `variable "region" {}`,
+		"bad number":       `version = 1.2.3`,
+		"two on a line":    `a = 1 b = 2`,
+		"interpolation":    `name = "${var.prefix}-api"`,
+		"function call":    `name = upper("api")`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadHCL([]byte(content)); err == nil {
+				t.Errorf("loadHCL(%q) should fail", content)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	empty, first, second := t.TempDir(), t.TempDir(), t.TempDir()
+	for dir, name := range map[string]string{first: "ason.vars.yaml", second: "ason.vars.toml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("name: demo\n"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	got, err := Find(empty, first, second)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if want := filepath.Join(first, "ason.vars.yaml"); got != want {
+		t.Errorf("Find() = %q, want %q", got, want)
+	}
+
+	got, err = Find(empty)
+	if err != nil || got != "" {
+		t.Errorf("Find() without a vars file = %q, %v, want none", got, err)
+	}
+}
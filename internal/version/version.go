@@ -0,0 +1,78 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is dropped; a
+// pre-release is kept only to order it before its release.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+// Parse reads versions like 1.2.3, v1.2 or 1.2.3-rc.1+build. Missing minor
+// and patch numbers are zero.
+func Parse(s string) (Version, error) {
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(core, "+"); i >= 0 {
+		core = core[:i]
+	}
+
+	var v Version
+	if i := strings.Index(core, "-"); i >= 0 {
+		core, v.Prerelease = core[:i], core[i+1:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer than
+// other. Pre-releases are older than their release but otherwise equal to
+// each other.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease != "" && other.Prerelease == "":
+		return -1
+	case v.Prerelease == "" && other.Prerelease != "":
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether version is min or newer
+func AtLeast(version, min string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	m, err := Parse(min)
+	if err != nil {
+		return false, err
+	}
+	return v.Compare(m) >= 0, nil
+}
@@ -0,0 +1,56 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Version
+		wantErr bool
+	}{
+		{input: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{input: "v0.4", want: Version{Minor: 4}},
+		{input: "2", want: Version{Major: 2}},
+		{input: "1.0.0-rc.1+abc", want: Version{Major: 1, Prerelease: "rc.1"}},
+		{input: "dev", wantErr: true},
+		{input: "1.2.3.4", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		version, min string
+		want         bool
+	}{
+		{"0.1.0", "0.1.0", true},
+		{"0.2.0", "0.1.9", true},
+		{"0.10.0", "0.9.0", true},
+		{"v1.0.0", "1", true},
+		{"0.1.0", "99.0.0", false},
+		{"1.0.0-rc.1", "1.0.0", false},
+		{"1.0.0", "1.0.0-rc.1", true},
+	}
+
+	for _, tt := range tests {
+		got, err := AtLeast(tt.version, tt.min)
+		if err != nil {
+			t.Fatalf("AtLeast(%q, %q) error = %v", tt.version, tt.min, err)
+		}
+		if got != tt.want {
+			t.Errorf("AtLeast(%q, %q) = %v, want %v", tt.version, tt.min, got, tt.want)
+		}
+	}
+}
@@ -46,3 +46,23 @@ func CacheHome() (string, error) {
 
 	return filepath.Join(cacheHome, "ason"), nil
 }
+
+// DataDirs returns the ason directories under XDG_DATA_DIRS, most preferred
+// first. These hold system-wide, read-only data shared by all users.
+func DataDirs() []string {
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	var dirs []string
+	for _, dir := range filepath.SplitList(dataDirs) {
+		// The spec requires absolute paths; relative ones are ignored
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		dirs = append(dirs, filepath.Join(dir, "ason"))
+	}
+
+	return dirs
+}
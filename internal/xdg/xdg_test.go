@@ -0,0 +1,21 @@
+package xdg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDataDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_DIRS", "")
+	want := []string{filepath.Join("/usr/local/share", "ason"), filepath.Join("/usr/share", "ason")}
+	if got := DataDirs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DataDirs() = %v, want %v", got, want)
+	}
+
+	t.Setenv("XDG_DATA_DIRS", "/opt/share:relative/share::/srv/share")
+	want = []string{filepath.Join("/opt/share", "ason"), filepath.Join("/srv/share", "ason")}
+	if got := DataDirs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DataDirs() = %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"log"
 	"os"
 
 	"github.com/madstone-tech/ason/cmd"
@@ -19,8 +18,8 @@ func main() {
 	// Set version info in cmd package
 	cmd.SetVersionInfo(version, commit, date, builtBy)
 
+	// Execute has already printed the error
 	if err := cmd.Execute(); err != nil {
-		log.Fatal(err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }